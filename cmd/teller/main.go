@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/hashhavoc/teller/internal/commands"
 	"github.com/phuslu/log"
@@ -37,7 +40,14 @@ func main() {
 		},
 	}
 	app := commands.CreateApp(glog, Version)
-	err := app.Run(os.Args)
+	// Cancel in-flight requests on Ctrl+C instead of leaving them running.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := app.RunContext(ctx, os.Args)
+	interrupted := ctx.Err() != nil
+	stop()
+	if interrupted {
+		os.Exit(130)
+	}
 	if err != nil {
 		glog.Fatal().Err(err).Msg("")
 	}
@@ -1,12 +1,9 @@
 package ft
 
 import (
-	"fmt"
-
-	"github.com/charmbracelet/bubbles/viewport"
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/hashhavoc/teller/internal/commands/props"
 	"github.com/hashhavoc/teller/internal/common"
+	"github.com/hashhavoc/teller/internal/common/tui"
 	"github.com/hashhavoc/teller/pkg/api/gobob"
 	"github.com/urfave/cli/v2"
 )
@@ -17,31 +14,25 @@ func CreateFungibleTokensCommand(props *props.AppProps) *cli.Command {
 		Aliases: []string{"ft"},
 		Usage:   "Provides interactions with fungible tokens",
 		Action: func(c *cli.Context) error {
-			resp, err := props.BobClient.GetAllTokens()
+			resp, err := props.BobClient.GetAllTokensContext(c.Context)
 			if err != nil {
 				props.Logger.Fatal().Err(err).Msg("Error getting all tokens")
 			}
 			dataRows := generateTableData(resp)
 
-			headers := []string{"Name", "Symbol", "Decimals", "Total Supply", "Contract ID", "Holders", "Type"}
-
-			t := common.CreateTable(headers, dataRows)
-
-			vpTop := viewport.New(75, 1)
-			vpTop.SetContent(fmt.Sprintf("Total: %s", fmt.Sprint(len(dataRows))))
-
-			vpBottom := viewport.New(75, 1)
-			vpBottom.SetContent("Press 'a' to export all addresses, 'h' to view holders")
+			view := tui.View{
+				Title:   "BOB fungible tokens",
+				Columns: columns,
+				Rows:    tui.Rows(dataRows),
+				Export:  "tokens.csv",
+				Actions: tokenActions(props.BobClient),
+			}
 
-			m := tableModel{
-				table:          t,
-				viewportBottom: vpBottom,
-				viewportTop:    vpTop,
-				client:         props.BobClient,
-				logger:         props.Logger,
+			if !common.Interactive(c) {
+				return tui.PrintRows(c, view.Columns, view.Rows)
 			}
 
-			if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+			if err := tui.Run(c.Context, view); err != nil {
 				props.Logger.Fatal().Err(err).Msg("Failed to run program")
 			}
 			return nil
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hashhavoc/teller/internal/commands/props"
+	"github.com/hashhavoc/teller/internal/common"
+	"github.com/hashhavoc/teller/internal/common/tui"
+	"github.com/jedib0t/go-pretty/table"
+	"github.com/urfave/cli/v2"
+)
+
+var statsColumns = []tui.Column{
+	{Title: "Host"},
+	{Title: "Entries", Kind: tui.Integer},
+	{Title: "Immutable", Kind: tui.Integer},
+	{Title: "Expired", Kind: tui.Integer},
+	{Title: "Size", Kind: tui.Integer},
+	{Title: "Oldest", Kind: tui.Timestamp},
+	{Title: "Newest", Kind: tui.Timestamp},
+}
+
+func CreateCacheCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "cache",
+		Usage: "Inspect and clear the API response cache",
+		Subcommands: []*cli.Command{
+			createStatsCommand(props),
+			createClearCommand(props),
+		},
+	}
+}
+
+func createStatsCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "stats",
+		Usage: "show the cached responses of each host",
+		Action: func(c *cli.Context) error {
+			stats, err := props.Cache.Stats()
+			if err != nil {
+				return err
+			}
+			if format := common.OutputFormat(c); format != common.OutputTable {
+				var rows [][]string
+				for _, s := range stats {
+					rows = append(rows, []string{s.Host, fmt.Sprint(s.Entries), fmt.Sprint(s.Immutable), fmt.Sprint(s.Expired),
+						fmt.Sprint(s.Size), s.Oldest.Format(time.RFC3339), s.Newest.Format(time.RFC3339)})
+				}
+				return tui.PrintRows(c, statsColumns, rows)
+			}
+			if len(stats) == 0 {
+				fmt.Printf("The cache in %s is empty\n", props.Cache.Dir)
+				return nil
+			}
+
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			t.SetStyle(table.StyleRounded)
+			t.AppendHeader(table.Row{"Host", "Entries", "Immutable", "Expired", "Size", "Oldest", "Newest"})
+			var entries, immutable, expired int
+			var size int64
+			for _, s := range stats {
+				host := s.Host
+				if host == "" {
+					host = "(unreadable)"
+				}
+				t.AppendRow(table.Row{host, s.Entries, s.Immutable, s.Expired, formatSize(s.Size),
+					s.Oldest.Local().Format("2006-01-02 15:04:05"), s.Newest.Local().Format("2006-01-02 15:04:05")})
+				entries += s.Entries
+				immutable += s.Immutable
+				expired += s.Expired
+				size += s.Size
+			}
+			t.AppendFooter(table.Row{"Total", entries, immutable, expired, formatSize(size), "", ""})
+			t.Render()
+			return nil
+		},
+	}
+}
+
+func createClearCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "clear",
+		Usage: "remove cached responses",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "expired",
+				Usage: "only remove responses past their TTL",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			removed, err := props.Cache.Clear(c.Bool("expired"))
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Removed %d cached responses\n", removed)
+			return nil
+		},
+	}
+}
+
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
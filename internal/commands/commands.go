@@ -1,11 +1,16 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/hashhavoc/teller/internal/commands/bob"
+	"github.com/hashhavoc/teller/internal/commands/cache"
 	"github.com/hashhavoc/teller/internal/commands/conf"
 	"github.com/hashhavoc/teller/internal/commands/contract"
 	"github.com/hashhavoc/teller/internal/commands/dex"
@@ -15,44 +20,24 @@ import (
 	"github.com/hashhavoc/teller/internal/commands/token"
 	"github.com/hashhavoc/teller/internal/commands/transactions"
 	"github.com/hashhavoc/teller/internal/commands/wallet"
+	"github.com/hashhavoc/teller/internal/common"
 	"github.com/hashhavoc/teller/internal/config"
 	"github.com/hashhavoc/teller/pkg/api/alex"
 	"github.com/hashhavoc/teller/pkg/api/gobob"
 	"github.com/hashhavoc/teller/pkg/api/hiro"
 	"github.com/hashhavoc/teller/pkg/api/ord"
 	"github.com/hashhavoc/teller/pkg/api/stxtools"
+	"github.com/hashhavoc/teller/pkg/api/transport"
 	"github.com/phuslu/log"
 
 	"github.com/urfave/cli/v2"
 )
 
 func CreateApp(glog log.Logger, version string) *cli.App {
-	dirname, err := os.UserHomeDir()
-	if err != nil {
-		glog.Fatal().Err(err).Msg("Failed to get user home directory")
-	}
-	configPath := fmt.Sprintf("%s/.teller.yaml", dirname)
-	config := config.NewConfig(configPath)
-
-	err = config.ReadConfig()
-	if err != nil {
-		glog.Debug().Err(err).Msg("Failed to read config")
-	}
-
-	hiroClient := hiro.NewAPIClient(config.Endpoints.Hiro)
-	alexClient := alex.NewAPIClient(config.Endpoints.Alex)
-	stxtoolsClient := stxtools.NewAPIClient(config.Endpoints.StxTools)
-	ordClient := ord.NewAPIClient(config.Endpoints.Ord)
-	gobobClient := gobob.NewAPIClient(config.Endpoints.Bob)
 	props := &props.AppProps{
-		HeroClient:     hiroClient,
-		AlexClient:     alexClient,
-		StxToolsClient: stxtoolsClient,
-		OrdClient:      ordClient,
-		BobClient:      gobobClient,
-		Config:         config,
-		Logger:         glog,
+		Logger: glog,
 	}
+	var cancelTimeout context.CancelFunc
 	app := &cli.App{
 		Name:                 "teller",
 		Compiled:             time.Now(),
@@ -60,6 +45,63 @@ func CreateApp(glog log.Logger, version string) *cli.App {
 		Usage:                "interact with the stx blockchain",
 		EnableBashCompletion: true,
 		Suggest:              true,
+		// Clarity literals such as tuples contain commas, so slice flags
+		// must not be split on them.
+		DisableSliceFlagSeparator: true,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "config",
+				Usage:   "config file (default: $XDG_CONFIG_HOME/teller/config.yaml if it exists, else ~/.teller.yaml)",
+				EnvVars: []string{"TELLER_CONFIG"},
+			},
+			&cli.StringFlag{
+				Name:    "profile",
+				Usage:   "profile of the config file to use, such as mainnet or testnet (default: the profile set in the config file)",
+				EnvVars: []string{"TELLER_PROFILE"},
+			},
+			&cli.DurationFlag{
+				Name:    "timeout",
+				Usage:   "abort the command and its requests after this long, e.g. 30s or 5m (default: no limit)",
+				EnvVars: []string{"TELLER_TIMEOUT"},
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "output format: " + strings.Join(common.OutputFormats, ", ") + ". The interactive table is only shown for table output to a terminal",
+				Value:   common.OutputTable,
+				EnvVars: []string{"TELLER_OUTPUT"},
+			},
+			&cli.BoolFlag{
+				Name:    "no-cache",
+				Usage:   "neither read nor store cached API responses",
+				EnvVars: []string{"TELLER_NO_CACHE"},
+			},
+		},
+		Before: func(c *cli.Context) error {
+			if err := common.ValidOutputFormat(c.String("output")); err != nil {
+				return err
+			}
+			if err := loadConfig(c, props, version); err != nil {
+				return err
+			}
+			if c.Bool("no-cache") {
+				props.Cache.Disabled = true
+			}
+			// Subcommands inherit the context, so every request made through
+			// c.Context stops at the deadline.
+			if timeout := c.Duration("timeout"); timeout > 0 {
+				var cancel context.CancelFunc
+				c.Context, cancel = context.WithTimeout(c.Context, timeout)
+				cancelTimeout = cancel
+			}
+			return nil
+		},
+		After: func(c *cli.Context) error {
+			if cancelTimeout != nil {
+				cancelTimeout()
+			}
+			return nil
+		},
 		Commands: []*cli.Command{
 			conf.CreateConfigCommand(props),
 			bob.CreateBobCommand(props),
@@ -69,8 +111,56 @@ func CreateApp(glog log.Logger, version string) *cli.App {
 			dex.CreateDexCommand(props),
 			transactions.CreateTransactionsCommand(props),
 			ordinals.CreateOrdinalsCommand(props),
+			cache.CreateCacheCommand(props),
 			names.CreateNameCommand(props),
 		},
 	}
 	return app
 }
+
+// loadConfig reads the config file and profile selected by the global flags,
+// and sets up the cache and API clients of props with them.
+func loadConfig(c *cli.Context, props *props.AppProps, version string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	cfg := config.NewConfig(config.Locate(c.String("config"), home), home)
+	if err := cfg.ReadConfig(); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to read config %s: %w", cfg.Path, err)
+		}
+		props.Logger.Debug().Err(err).Msg("Failed to read config")
+	}
+	profile := cfg.Profile
+	if c.IsSet("profile") {
+		profile = c.String("profile")
+	}
+	if err := cfg.UseProfile(profile); err != nil {
+		return err
+	}
+
+	responseCache := transport.NewCache(cfg.CacheDir())
+	responseCache.Disabled = cfg.Cache.Disabled
+	options := make(map[string]transport.Options)
+	for _, endpoint := range []string{"hiro", "alex", "stxtools", "ord", "bob"} {
+		opts, err := cfg.TransportOptions(endpoint)
+		if err != nil {
+			return fmt.Errorf("invalid configuration of endpoint %s: %w", endpoint, err)
+		}
+		if opts.UserAgent == "" {
+			opts.UserAgent = "teller/" + version
+		}
+		opts.Cache = responseCache
+		options[endpoint] = opts
+	}
+
+	props.HeroClient = hiro.NewAPIClient(cfg.EndpointURL("hiro"), options["hiro"])
+	props.AlexClient = alex.NewAPIClient(cfg.EndpointURL("alex"), options["alex"])
+	props.StxToolsClient = stxtools.NewAPIClient(cfg.EndpointURL("stxtools"), options["stxtools"])
+	props.OrdClient = ord.NewAPIClient(cfg.EndpointURL("ord"), options["ord"])
+	props.BobClient = gobob.NewAPIClient(cfg.EndpointURL("bob"), options["bob"])
+	props.Cache = responseCache
+	props.Config = cfg
+	return nil
+}
@@ -0,0 +1,238 @@
+package conf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashhavoc/teller/internal/commands/props"
+	"github.com/hashhavoc/teller/internal/common"
+	"github.com/hashhavoc/teller/internal/common/tui"
+	"github.com/hashhavoc/teller/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+func CreateSetAuthCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "auth",
+		Usage: "manage the credentials sent to each endpoint",
+		Subcommands: []*cli.Command{
+			createAuthAPIKeyCommand(props),
+			createAuthBasicCommand(props),
+			createAuthHeaderCommand(props),
+			createAuthClearCommand(props),
+			createAuthListCommand(props),
+		},
+	}
+}
+
+func endpointFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:     "endpoint",
+		Aliases:  []string{"e"},
+		Usage:    "endpoint the credentials are for (" + strings.Join(config.EndpointNames, ", ") + ")",
+		Required: true,
+	}
+}
+
+// secretFlags returns the flags giving a secret inline as --name, from an
+// environment variable as --name-env or from a command as --name-command.
+func secretFlags(name, usage string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  name,
+			Usage: usage + ", stored in the config file (default: prompt for it)",
+		},
+		&cli.StringFlag{
+			Name:  name + "-env",
+			Usage: "environment variable to read the " + usage + " from",
+		},
+		&cli.StringFlag{
+			Name:  name + "-command",
+			Usage: "command printing the " + usage + ", e.g. 'pass show teller/hiro'",
+		},
+	}
+}
+
+// secretFromFlags returns the secret given by the flags of secretFlags,
+// prompting for an inline value when none is set.
+func secretFromFlags(c *cli.Context, name, prompt string) (*config.Secret, error) {
+	var secret config.Secret
+	set := 0
+	if c.IsSet(name) {
+		secret.Value = c.String(name)
+		set++
+	}
+	if c.IsSet(name + "-env") {
+		secret.Env = c.String(name + "-env")
+		set++
+	}
+	if c.IsSet(name + "-command") {
+		secret.Command = c.String(name + "-command")
+		set++
+	}
+	switch set {
+	case 0:
+		value, err := common.ReadSecret(prompt)
+		if err != nil {
+			return nil, err
+		}
+		secret.Value = string(value)
+	case 1:
+	default:
+		return nil, fmt.Errorf("only one of --%[1]s, --%[1]s-env and --%[1]s-command can be set", name)
+	}
+	return &secret, nil
+}
+
+func writeAuth(props *props.AppProps, endpoint string, update func(*config.ConfigAuth)) error {
+	if err := props.Config.SetAuth(endpoint, update); err != nil {
+		return err
+	}
+	return props.Config.WriteConfig()
+}
+
+func createAuthAPIKeyCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "api-key",
+		Usage: "send an API key as the X-Api-Key header, as Hiro expects",
+		Flags: append([]cli.Flag{endpointFlag()}, secretFlags("key", "API key")...),
+		Action: func(c *cli.Context) error {
+			endpoint := c.String("endpoint")
+			if err := config.ValidEndpoint(endpoint); err != nil {
+				return err
+			}
+			key, err := secretFromFlags(c, "key", "API key: ")
+			if err != nil {
+				return err
+			}
+			return writeAuth(props, endpoint, func(a *config.ConfigAuth) {
+				a.APIKey = key
+			})
+		},
+	}
+}
+
+func createAuthBasicCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "basic",
+		Usage: "send a username and password as basic auth",
+		Flags: append([]cli.Flag{
+			endpointFlag(),
+			&cli.StringFlag{
+				Name:     "username",
+				Aliases:  []string{"u"},
+				Usage:    "basic auth username",
+				Required: true,
+			},
+		}, secretFlags("password", "password")...),
+		Action: func(c *cli.Context) error {
+			endpoint := c.String("endpoint")
+			if err := config.ValidEndpoint(endpoint); err != nil {
+				return err
+			}
+			password, err := secretFromFlags(c, "password", "Password: ")
+			if err != nil {
+				return err
+			}
+			return writeAuth(props, endpoint, func(a *config.ConfigAuth) {
+				a.Username = c.String("username")
+				a.Password = password
+			})
+		},
+	}
+}
+
+func createAuthHeaderCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "header",
+		Usage: "send a custom header such as 'Authorization: Bearer ...'",
+		Flags: append([]cli.Flag{
+			endpointFlag(),
+			&cli.StringFlag{
+				Name:     "name",
+				Usage:    "header name",
+				Required: true,
+			},
+		}, secretFlags("value", "header value")...),
+		Action: func(c *cli.Context) error {
+			endpoint := c.String("endpoint")
+			if err := config.ValidEndpoint(endpoint); err != nil {
+				return err
+			}
+			value, err := secretFromFlags(c, "value", c.String("name")+": ")
+			if err != nil {
+				return err
+			}
+			return writeAuth(props, endpoint, func(a *config.ConfigAuth) {
+				if a.Headers == nil {
+					a.Headers = make(map[string]*config.Secret)
+				}
+				a.Headers[c.String("name")] = value
+			})
+		},
+	}
+}
+
+func createAuthClearCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "clear",
+		Usage: "remove the credentials of an endpoint",
+		Flags: []cli.Flag{
+			endpointFlag(),
+			&cli.StringFlag{
+				Name:  "header",
+				Usage: "only remove this custom header",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return writeAuth(props, c.String("endpoint"), func(a *config.ConfigAuth) {
+				if c.IsSet("header") {
+					delete(a.Headers, c.String("header"))
+					return
+				}
+				*a = config.ConfigAuth{}
+			})
+		},
+	}
+}
+
+func createAuthListCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "list the configured credentials without revealing them",
+		Action: func(c *cli.Context) error {
+			if len(props.Config.Auth) == 0 {
+				fmt.Println("No credentials are configured")
+				return nil
+			}
+
+			var rows [][]string
+			for _, endpoint := range config.EndpointNames {
+				auth, ok := props.Config.Auth[endpoint]
+				if !ok {
+					continue
+				}
+				if auth.APIKey != nil {
+					rows = append(rows, []string{endpoint, "api key", auth.APIKey.Source()})
+				}
+				if auth.Username != "" || auth.Password != nil {
+					source := "none"
+					if auth.Password != nil {
+						source = auth.Password.Source()
+					}
+					rows = append(rows, []string{endpoint, "basic auth as " + auth.Username, source})
+				}
+				names := make([]string, 0, len(auth.Headers))
+				for name := range auth.Headers {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				for _, name := range names {
+					rows = append(rows, []string{endpoint, "header " + name, auth.Headers[name].Source()})
+				}
+			}
+			return tui.PrintRows(c, []tui.Column{{Title: "Endpoint"}, {Title: "Credential"}, {Title: "Source"}}, rows)
+		},
+	}
+}
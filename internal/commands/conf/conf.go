@@ -12,6 +12,7 @@ func CreateConfigCommand(props *props.AppProps) *cli.Command {
 		Subcommands: []*cli.Command{
 			CreateInitCommand(props),
 			CreateSetCommand(props),
+			CreateProfileCommand(props),
 		},
 	}
 }
@@ -33,6 +34,7 @@ func CreateSetCommand(props *props.AppProps) *cli.Command {
 		Usage: "Creates a new configuration file",
 		Subcommands: []*cli.Command{
 			CreateSetEndpointCommand(props),
+			CreateSetAuthCommand(props),
 		},
 	}
 }
@@ -69,21 +71,22 @@ func CreateSetEndpointCommand(props *props.AppProps) *cli.Command {
 			},
 		},
 		Action: func(c *cli.Context) error {
-
+			// Endpoints are set on the profile selected by --profile.
+			endpoints := &props.Config.Active().Endpoints
 			if c.String("hiro") != "" {
-				props.Config.Endpoints.Hiro = c.String("hiro")
+				endpoints.Hiro = c.String("hiro")
 			}
 			if c.String("ord") != "" {
-				props.Config.Endpoints.Ord = c.String("ord")
+				endpoints.Ord = c.String("ord")
 			}
 			if c.String("alex") != "" {
-				props.Config.Endpoints.Alex = c.String("alex")
+				endpoints.Alex = c.String("alex")
 			}
 			if c.String("stxtools") != "" {
-				props.Config.Endpoints.StxTools = c.String("stxtools")
+				endpoints.StxTools = c.String("stxtools")
 			}
 			if c.String("bob") != "" {
-				props.Config.Endpoints.Bob = c.String("bob")
+				endpoints.Bob = c.String("bob")
 			}
 			err := props.Config.WriteConfig()
 			if err != nil {
@@ -0,0 +1,114 @@
+package conf
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hashhavoc/teller/internal/commands/props"
+	"github.com/hashhavoc/teller/internal/common/tui"
+	"github.com/urfave/cli/v2"
+)
+
+func CreateProfileCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "profile",
+		Usage: "manage named sets of endpoints and wallets such as mainnet and testnet",
+		Subcommands: []*cli.Command{
+			createProfileAddCommand(props),
+			createProfileRemoveCommand(props),
+			createProfileDefaultCommand(props),
+			createProfileListCommand(props),
+		},
+	}
+}
+
+func profileNameFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:     "name",
+		Aliases:  []string{"n"},
+		Usage:    "profile name",
+		Required: true,
+	}
+}
+
+func createProfileAddCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "add",
+		Usage: "add an empty profile, set its endpoints with --profile <name> config set endpoint",
+		Flags: []cli.Flag{profileNameFlag()},
+		Action: func(c *cli.Context) error {
+			if err := props.Config.AddProfile(c.String("name")); err != nil {
+				return err
+			}
+			return props.Config.WriteConfig()
+		},
+	}
+}
+
+func createProfileRemoveCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "remove",
+		Usage: "remove a profile with its endpoints and wallets",
+		Flags: []cli.Flag{profileNameFlag()},
+		Action: func(c *cli.Context) error {
+			if err := props.Config.RemoveProfile(c.String("name")); err != nil {
+				return err
+			}
+			return props.Config.WriteConfig()
+		},
+	}
+}
+
+func createProfileDefaultCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "default",
+		Usage: "use a profile unless --profile selects another",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "name",
+				Aliases: []string{"n"},
+				Usage:   "profile name (default: the top level endpoints and wallets)",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			name := c.String("name")
+			if name != "" && props.Config.Profiles[name] == nil {
+				return fmt.Errorf("unknown profile %q", name)
+			}
+			props.Config.Profile = name
+			return props.Config.WriteConfig()
+		},
+	}
+}
+
+func createProfileListCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "list the profiles and the endpoints they use",
+		Action: func(c *cli.Context) error {
+			fmt.Fprintln(os.Stderr, "Config:", props.Config.Path)
+			var rows [][]string
+			for _, name := range append([]string{""}, props.Config.ProfileNames()...) {
+				profile := props.Config.Profiles[name]
+				if name == "" {
+					profile = &props.Config.ConfigProfile
+				}
+				var state string
+				switch {
+				case name == props.Config.ActiveProfile():
+					state = "active"
+				case name == props.Config.Profile:
+					state = "default"
+				}
+				display := name
+				if display == "" {
+					display = "(top level)"
+				}
+				// The hiro endpoint tells the networks apart.
+				hiro := props.Config.ProfileEndpointURL(name, "hiro")
+				rows = append(rows, []string{display, state, hiro, fmt.Sprint(len(profile.Wallets))})
+			}
+			return tui.PrintRows(c, []tui.Column{{Title: "Profile"}, {Title: "State"}, {Title: "Hiro"}, {Title: "Wallets", Kind: tui.Integer}}, rows)
+		},
+	}
+}
@@ -1,13 +1,25 @@
 package contract
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/hashhavoc/teller/internal/commands/props"
+	"github.com/hashhavoc/teller/internal/commands/signing"
+	"github.com/hashhavoc/teller/internal/common"
+	"github.com/hashhavoc/teller/internal/common/tui"
 	"github.com/hashhavoc/teller/pkg/api/hiro"
+	"github.com/hashhavoc/teller/pkg/clarity"
+	"github.com/hashhavoc/teller/pkg/clarity/sip"
+	"github.com/hashhavoc/teller/pkg/stacks/transaction"
 	"github.com/jedib0t/go-pretty/table"
 	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v2"
 )
 
 // CreateContractsCommand creates the contracts command and its subcommands.
@@ -18,7 +30,11 @@ func CreateContractsCommand(props *props.AppProps) *cli.Command {
 		Subcommands: []*cli.Command{
 			createSourceCommand(props),
 			createReadCommand(props),
+			createCallCommand(props),
+			createDeployCommand(props),
 			createViewCommand(props),
+			createAbiCommand(props),
+			createConformanceCommand(props),
 		},
 	}
 }
@@ -33,10 +49,11 @@ func createSourceCommand(props *props.AppProps) *cli.Command {
 				Usage:    "Contract address",
 				Aliases:  []string{"c"},
 				Required: true,
+				Action:   props.CheckContract,
 			},
 		},
 		Action: func(c *cli.Context) error {
-			resp, err := props.HeroClient.GetContractSource(c.String("contract"))
+			resp, err := props.HeroClient.GetContractSourceContext(c.Context, c.String("contract"))
 			if err != nil {
 				return err
 			}
@@ -57,8 +74,66 @@ func createReadCommand(props *props.AppProps) *cli.Command {
 				Required: true,
 			},
 			&cli.StringFlag{
-				Name:     "type",
-				Usage:    "return type",
+				Name:     "contract",
+				Usage:    "Contract address",
+				Aliases:  []string{"c"},
+				Required: true,
+				Action:   props.CheckContract,
+			},
+			&cli.StringSliceFlag{
+				Name:    "arg",
+				Usage:   "function argument as a Clarity literal, e.g. u100, 'SP..., 0xbeef, \"text\", (some u1), {a: u1}. Repeat for each argument",
+				Aliases: []string{"a"},
+			},
+			&cli.StringFlag{
+				Name:   "sender",
+				Usage:  "principal to use as tx-sender for the call",
+				Action: props.CheckPrincipal,
+			},
+		},
+		ArgsUsage: "contract id",
+		Action: func(c *cli.Context) error {
+			id := c.String("contract")
+			abi, err := props.HeroClient.GetContractInterfaceContext(c.Context, id)
+			if err != nil {
+				return err
+			}
+			function, ok := abi.Function(c.String("function"))
+			if !ok {
+				return fmt.Errorf("contract %s has no function %s", id, c.String("function"))
+			}
+			if function.Access != clarity.AccessReadOnly {
+				return fmt.Errorf("%s is a %s function, only read-only functions can be read", function.Name, clarity.AccessLabel(function.Access))
+			}
+			values, err := function.ParseArgs(c.StringSlice("arg"))
+			if err != nil {
+				return err
+			}
+			args, err := encodeArguments(values)
+			if err != nil {
+				return err
+			}
+			resp, err := props.HeroClient.GetContractReadOnlyContext(c.Context, id, function.Name, c.String("sender"), args)
+			if err != nil {
+				return err
+			}
+			if err := function.Outputs.Type.Admits(resp); err != nil {
+				props.Logger.Warn().Err(err).Msg("Result does not match the declared return type")
+			}
+			fmt.Println(resp.String())
+			return nil
+		},
+	}
+}
+
+func createCallCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "call",
+		Usage: "build, sign and optionally broadcast a call to a public function of a contract",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:     "function",
+				Usage:    "function to call on the contract",
 				Required: true,
 			},
 			&cli.StringFlag{
@@ -66,55 +141,459 @@ func createReadCommand(props *props.AppProps) *cli.Command {
 				Usage:    "Contract address",
 				Aliases:  []string{"c"},
 				Required: true,
+				Action:   props.CheckContract,
+			},
+			&cli.StringSliceFlag{
+				Name:    "arg",
+				Usage:   "function argument as a Clarity literal, e.g. u100, 'SP..., 0xbeef, \"text\", (some u1), {a: u1}. Repeat for each argument",
+				Aliases: []string{"a"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "post-condition",
+				Aliases: []string{"pc"},
+				Usage: "post condition, repeat for each condition: \"stx PRINCIPAL CODE AMOUNT\", " +
+					"\"ft PRINCIPAL CODE AMOUNT ADDRESS.CONTRACT::ASSET\" or \"nft PRINCIPAL sent|not-sent ADDRESS.CONTRACT::ASSET VALUE\". " +
+					"PRINCIPAL is origin or an address, CODE is eq, gt, gte, lt or lte",
 			},
+		}, signing.Flags()...),
+		Action: func(c *cli.Context) error {
+			id := c.String("contract")
+			network, err := signing.Network(c, props)
+			if err != nil {
+				return err
+			}
+			key, err := signing.Key(c, props)
+			if err != nil {
+				return err
+			}
+
+			abi, err := props.HeroClient.GetContractInterfaceContext(c.Context, id)
+			if err != nil {
+				return err
+			}
+			function, ok := abi.Function(c.String("function"))
+			if !ok {
+				return fmt.Errorf("contract %s has no function %s", id, c.String("function"))
+			}
+			if function.Access != clarity.AccessPublic {
+				return fmt.Errorf("%s is a %s function, only public functions can be called", function.Name, clarity.AccessLabel(function.Access))
+			}
+			args, err := function.ParseArgs(c.StringSlice("arg"))
+			if err != nil {
+				return err
+			}
+
+			var postConditions []transaction.PostCondition
+			for _, s := range c.StringSlice("post-condition") {
+				pc, err := transaction.ParsePostCondition(s)
+				if err != nil {
+					return err
+				}
+				postConditions = append(postConditions, pc)
+			}
+
+			payload, err := transaction.NewContractCall(id, function.Name, args)
+			if err != nil {
+				return err
+			}
+			tx := transaction.New(network, transaction.NewSingleSigSpendingCondition(key.PublicKey(), 0, 0), payload)
+			tx.PostConditions = postConditions
+			if err := signing.Sign(c, props, tx, key); err != nil {
+				return err
+			}
+			if tx.PostConditionMode == transaction.PostConditionModeDeny && len(postConditions) == 0 {
+				props.Logger.Warn().Msg("No post conditions in deny mode, the call will abort if it moves any assets")
+			}
+			_, err = signing.Submit(c, props, tx)
+			return err
 		},
-		ArgsUsage: "contract id",
+	}
+}
+
+func createDeployCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "deploy",
+		Usage: "build, sign and optionally broadcast a contract deployment",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:     "file",
+				Aliases:  []string{"f"},
+				Usage:    "Clarity source file to deploy",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "name",
+				Usage:    "name of the contract",
+				Required: true,
+			},
+			&cli.UintFlag{
+				Name:  "clarity-version",
+				Usage: "Clarity version of the contract (1, 2 or 3), defaults to the version of the current epoch",
+			},
+			&cli.BoolFlag{
+				Name:  "wait",
+				Usage: "wait for the broadcast deployment to be confirmed",
+			},
+			&cli.DurationFlag{
+				Name:  "wait-timeout",
+				Usage: "how long to wait for confirmation",
+				Value: 30 * time.Minute,
+			},
+		}, signing.Flags()...),
 		Action: func(c *cli.Context) error {
-			resp, err := props.HeroClient.GetContractReadOnly(c.String("contract"), c.String("function"), c.String("type"), []string{})
+			if c.Bool("wait") && !c.Bool("broadcast") {
+				return errors.New("--wait requires --broadcast")
+			}
+			version := c.Uint("clarity-version")
+			if version > 3 {
+				return fmt.Errorf("invalid clarity version %d", version)
+			}
+			network, err := signing.Network(c, props)
+			if err != nil {
+				return err
+			}
+			key, err := signing.Key(c, props)
+			if err != nil {
+				return err
+			}
+			code, err := os.ReadFile(c.String("file"))
+			if err != nil {
+				return err
+			}
+
+			payload, err := transaction.NewSmartContract(c.String("name"), string(code), byte(version))
+			if err != nil {
+				return err
+			}
+			tx := transaction.New(network, transaction.NewSingleSigSpendingCondition(key.PublicKey(), 0, 0), payload)
+			// Without --fee, Sign estimates the fee from the serialized
+			// size, which is dominated by the contract source.
+			if err := signing.Sign(c, props, tx, key); err != nil {
+				return err
+			}
+
+			contractID := fmt.Sprintf("%s.%s", tx.Auth.Origin.Address(network), payload.Name)
+			props.Logger.Info().Str("contract", contractID).Uint64("fee", tx.Auth.Origin.Fee).Msg("Deploying contract")
+			txid, err := signing.Submit(c, props, tx)
+			if err != nil || txid == "" {
+				return err
+			}
+			fmt.Println("Contract ID:", contractID)
+			if !c.Bool("wait") {
+				return nil
+			}
+
+			if _, err := signing.Wait(c.Context, props, txid, c.Duration("wait-timeout")); err != nil {
+				return err
+			}
+			details, err := props.HeroClient.GetContractDetailsContext(c.Context, contractID)
 			if err != nil {
 				return err
 			}
-			fmt.Println(string(resp))
+
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			t.SetStyle(table.StyleRounded)
+			t.AppendHeader(table.Row{"Field", "Value"})
+			t.AppendRow(table.Row{"Contract ID", details.ContractID})
+			t.AppendRow(table.Row{"TxID", details.TxID})
+			t.AppendRow(table.Row{"Block Height", details.BlockHeight})
+			t.AppendRow(table.Row{"Clarity Version", details.ClarityVersion})
+			t.AppendRow(table.Row{"Canonical", details.Canonical})
+			t.AppendRow(table.Row{"Source Size", fmt.Sprintf("%d bytes", len(details.SourceCode))})
+			t.Render()
 			return nil
 		},
 	}
 }
 
-func createViewCommand(props *props.AppProps) *cli.Command {
+// encodeArguments returns the hex encoding of each argument.
+func encodeArguments(values []clarity.Value) ([]string, error) {
+	args := make([]string, 0, len(values))
+	for _, v := range values {
+		encoded, err := clarity.EncodeHex(v)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, encoded)
+	}
+	return args, nil
+}
+
+func createAbiCommand(props *props.AppProps) *cli.Command {
 	return &cli.Command{
-		Name:      "view",
-		Usage:     "execute view of a contract",
+		Name:      "abi",
+		Usage:     "view the interface of a contract",
 		ArgsUsage: "contract id",
 		Action: func(c *cli.Context) error {
 			id := c.Args().First()
 			if id == "" {
 				return &ContractIDRequiredError{"contract id is required"}
 			}
-			resp := GetContractDetails(props.HeroClient, id)
+			if err := props.CheckContract(c, id); err != nil {
+				return err
+			}
+			abi, err := props.HeroClient.GetContractInterfaceContext(c.Context, id)
+			if err != nil {
+				return err
+			}
+
+			switch common.OutputFormat(c) {
+			case common.OutputJSON:
+				return json.NewEncoder(os.Stdout).Encode(abi)
+			case common.OutputYAML:
+				// Go through JSON so type signatures keep their API form, which
+				// MapSlice reads in order as JSON is valid yaml.
+				b, err := json.Marshal(abi)
+				if err != nil {
+					return err
+				}
+				var doc yaml.MapSlice
+				if err := yaml.Unmarshal(b, &doc); err != nil {
+					return err
+				}
+				if b, err = yaml.Marshal(doc); err != nil {
+					return err
+				}
+				_, err = os.Stdout.Write(b)
+				return err
+			case common.OutputCSV, common.OutputTSV:
+				// Only the functions fit a single table.
+				var rows [][]string
+				for _, f := range abi.SortedFunctions() {
+					rows = append(rows, []string{clarity.AccessLabel(f.Access), f.Name, f.ArgsString(), f.Outputs.Type.String()})
+				}
+				return tui.PrintRows(c, []tui.Column{{Title: "Access"}, {Title: "Function"}, {Title: "Arguments"}, {Title: "Returns"}}, rows)
+			}
+
 			t := table.NewWriter()
 			t.SetOutputMirror(os.Stdout)
 			t.SetStyle(table.StyleRounded)
-			t.AppendHeader(table.Row{"Name", "Result"})
-			for _, d := range resp {
-				t.AppendRow([]interface{}{d.FunctionName, d.Result})
+			t.AppendHeader(table.Row{"Access", "Function", "Arguments", "Returns"})
+			for _, f := range abi.SortedFunctions() {
+				t.AppendRow(table.Row{clarity.AccessLabel(f.Access), f.Name, f.ArgsString(), f.Outputs.Type.String()})
 			}
 			t.Render()
+
+			if len(abi.Variables) > 0 {
+				t = table.NewWriter()
+				t.SetOutputMirror(os.Stdout)
+				t.SetStyle(table.StyleRounded)
+				t.AppendHeader(table.Row{"Access", "Variable", "Type"})
+				for _, v := range abi.Variables {
+					t.AppendRow(table.Row{v.Access, v.Name, v.Type.String()})
+				}
+				t.Render()
+			}
+
+			if len(abi.Maps) > 0 {
+				t = table.NewWriter()
+				t.SetOutputMirror(os.Stdout)
+				t.SetStyle(table.StyleRounded)
+				t.AppendHeader(table.Row{"Map", "Key", "Value"})
+				for _, m := range abi.Maps {
+					t.AppendRow(table.Row{m.Name, m.Key.String(), m.Value.String()})
+				}
+				t.Render()
+			}
+
+			if len(abi.FungibleTokens) > 0 || len(abi.NonFungibleTokens) > 0 {
+				t = table.NewWriter()
+				t.SetOutputMirror(os.Stdout)
+				t.SetStyle(table.StyleRounded)
+				t.AppendHeader(table.Row{"Token", "Kind", "Asset Type"})
+				for _, ft := range abi.FungibleTokens {
+					t.AppendRow(table.Row{ft.Name, "fungible", ""})
+				}
+				for _, nft := range abi.NonFungibleTokens {
+					t.AppendRow(table.Row{nft.Name, "non-fungible", nft.Type.String()})
+				}
+				t.Render()
+			}
+
+			if len(abi.ImplementedTraits) > 0 {
+				t = table.NewWriter()
+				t.SetOutputMirror(os.Stdout)
+				t.SetStyle(table.StyleRounded)
+				t.AppendHeader(table.Row{"Implemented Trait"})
+				for _, trait := range abi.ImplementedTraits {
+					t.AppendRow(table.Row{trait})
+				}
+				t.Render()
+			}
+			return nil
+		},
+	}
+}
+
+func createConformanceCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:      "conformance",
+		Usage:     "check a contract against the SIP-009, SIP-010 and SIP-013 token standards",
+		ArgsUsage: "contract id",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "standard",
+				Usage:   "standard to check (sip-009, sip-010 or sip-013). Repeat for each standard, defaults to all",
+				Aliases: []string{"s"},
+			},
+		},
+		Action: func(c *cli.Context) error {
+			id := c.Args().First()
+			if id == "" {
+				return &ContractIDRequiredError{"contract id is required"}
+			}
+			if err := props.CheckContract(c, id); err != nil {
+				return err
+			}
+
+			traits := sip.Traits
+			requested := c.StringSlice("standard")
+			if len(requested) > 0 {
+				traits = nil
+				for _, name := range requested {
+					trait, ok := sip.Lookup(name)
+					if !ok {
+						return fmt.Errorf("unknown standard %s", name)
+					}
+					traits = append(traits, trait)
+				}
+			}
+
+			abi, err := props.HeroClient.GetContractInterfaceContext(c.Context, id)
+			if err != nil {
+				return err
+			}
+
+			var failed []string
+			var rows [][]string
+			conforming := 0
+			for _, trait := range traits {
+				report := sip.Check(abi, trait)
+				if common.OutputFormat(c) == common.OutputTable {
+					renderConformance(report)
+				} else {
+					rows = append(rows, conformanceRows(report)...)
+				}
+				if report.Conforms() {
+					conforming++
+				} else if len(requested) > 0 || report.Declared {
+					// A standard that was asked for explicitly, or that the
+					// contract claims to implement, must conform.
+					failed = append(failed, trait.Name)
+				}
+			}
+
+			if rows != nil {
+				if err := tui.PrintRows(c, []tui.Column{{Title: "Standard"}, {Title: "Declared"}, {Title: "Function"}, {Title: "Status"}, {Title: "Expected"}, {Title: "Found"}, {Title: "Detail"}}, rows); err != nil {
+					return err
+				}
+			}
+			if len(failed) > 0 {
+				return cli.Exit(fmt.Sprintf("%s does not conform to %s", id, strings.Join(failed, ", ")), 1)
+			}
+			if conforming == 0 {
+				return cli.Exit(fmt.Sprintf("%s does not conform to any known standard", id), 1)
+			}
 			return nil
 		},
 	}
 }
 
-func GetContractDetails(c *hiro.APIClient, id string) []ContractReadOnlyFunctionsSip10Response {
+// renderConformance prints the per function result of a conformance check.
+func renderConformance(report sip.Report) {
+	status := "conforms"
+	if !report.Conforms() {
+		status = "does not conform"
+	}
+	declared := "not declared"
+	if report.Declared {
+		declared = "declared"
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleRounded)
+	t.SetTitle(fmt.Sprintf("%s (%s): %s, trait %s", strings.ToUpper(report.Trait.Name), report.Trait.Description, status, declared))
+	t.AppendHeader(table.Row{"Function", "Status", "Expected", "Found"})
+	for _, f := range report.Functions {
+		found := f.Detail
+		if f.Actual != nil {
+			found = traitSignature(*f.Actual)
+			if f.Detail != "" {
+				found += "\n" + f.Detail
+			}
+		}
+		t.AppendRow(table.Row{f.Expected.Name, f.Status, traitSignature(f.Expected), found})
+	}
+	t.Render()
+}
+
+// conformanceRows returns the per function result of a conformance check
+// for machine readable output.
+func conformanceRows(report sip.Report) [][]string {
+	var rows [][]string
+	for _, f := range report.Functions {
+		found := ""
+		if f.Actual != nil {
+			found = traitSignature(*f.Actual)
+		}
+		rows = append(rows, []string{report.Trait.Name, fmt.Sprint(report.Declared), f.Expected.Name, string(f.Status),
+			traitSignature(f.Expected), found, f.Detail})
+	}
+	return rows
+}
+
+// traitSignature renders a function the way define-trait lists it, with
+// argument types only.
+func traitSignature(f clarity.Function) string {
+	args := make([]string, 0, len(f.Args))
+	for _, arg := range f.Args {
+		args = append(args, arg.Type.String())
+	}
+	return fmt.Sprintf("(%s) %s", strings.Join(args, " "), f.Outputs.Type)
+}
+
+func createViewCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:      "view",
+		Usage:     "execute view of a contract",
+		ArgsUsage: "contract id",
+		Action: func(c *cli.Context) error {
+			id := c.Args().First()
+			if id == "" {
+				return &ContractIDRequiredError{"contract id is required"}
+			}
+			if err := props.CheckContract(c, id); err != nil {
+				return err
+			}
+			resp := GetContractDetails(c.Context, props.HeroClient, id)
+			var rows [][]string
+			for _, d := range resp {
+				rows = append(rows, []string{d.FunctionName, d.Result})
+			}
+			return tui.PrintRows(c, []tui.Column{{Title: "Name"}, {Title: "Result"}}, rows)
+		},
+	}
+}
+
+func GetContractDetails(ctx context.Context, c *hiro.APIClient, id string) []ContractReadOnlyFunctionsSip10Response {
 	functions := []ContractReadOnlyFunctionsSip10{}
-	functions = append(functions, ContractReadOnlyFunctionsSip10{FunctionName: "get-name", ResponseType: "string"})
-	functions = append(functions, ContractReadOnlyFunctionsSip10{FunctionName: "get-symbol", ResponseType: "string"})
-	functions = append(functions, ContractReadOnlyFunctionsSip10{FunctionName: "get-token-uri", ResponseType: "string"})
-	functions = append(functions, ContractReadOnlyFunctionsSip10{FunctionName: "get-decimals", ResponseType: "uint128"})
-	functions = append(functions, ContractReadOnlyFunctionsSip10{FunctionName: "get-total-supply", ResponseType: "uint128"})
+	functions = append(functions, ContractReadOnlyFunctionsSip10{FunctionName: "get-name"})
+	functions = append(functions, ContractReadOnlyFunctionsSip10{FunctionName: "get-symbol"})
+	functions = append(functions, ContractReadOnlyFunctionsSip10{FunctionName: "get-token-uri"})
+	functions = append(functions, ContractReadOnlyFunctionsSip10{FunctionName: "get-decimals"})
+	functions = append(functions, ContractReadOnlyFunctionsSip10{FunctionName: "get-total-supply"})
 	details := make([]ContractReadOnlyFunctionsSip10Response, 0)
 	for _, function := range functions {
-		resp, _ := c.GetContractReadOnly(id, function.FunctionName, function.ResponseType, []string{})
-		details = append(details, ContractReadOnlyFunctionsSip10Response{FunctionName: function.FunctionName, Result: resp})
+		var result string
+		resp, err := c.GetContractReadOnlyContext(ctx, id, function.FunctionName, "", []string{})
+		if err != nil {
+			result = fmt.Sprintf("error: %v", err)
+		} else {
+			result = clarity.Display(clarity.Unwrap(resp))
+		}
+		details = append(details, ContractReadOnlyFunctionsSip10Response{FunctionName: function.FunctionName, Result: result})
 	}
 
 	return details
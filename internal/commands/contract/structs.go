@@ -2,7 +2,6 @@ package contract
 
 type ContractReadOnlyFunctionsSip10 struct {
 	FunctionName string
-	ResponseType string
 }
 
 type ContractReadOnlyFunctionsSip10Response struct {
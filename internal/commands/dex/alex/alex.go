@@ -1,13 +1,11 @@
 package alex
 
 import (
-	"fmt"
 	"strconv"
 
-	"github.com/charmbracelet/bubbles/viewport"
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/hashhavoc/teller/internal/commands/props"
 	"github.com/hashhavoc/teller/internal/common"
+	"github.com/hashhavoc/teller/internal/common/tui"
 	"github.com/hashhavoc/teller/pkg/api/alex"
 	"github.com/urfave/cli/v2"
 )
@@ -17,30 +15,25 @@ func CreateAlexCommand(props *props.AppProps) *cli.Command {
 		Name:  "alex",
 		Usage: "get alex dex pairs",
 		Action: func(c *cli.Context) error {
-			resp, err := props.AlexClient.GetPairs()
+			resp, err := props.AlexClient.GetPairsContext(c.Context)
 			if err != nil {
 				return err
 			}
 			dataRows := generateTableData(resp)
 
-			headers := []string{"Base", "Target", "Last Price", "Liquidity in USD", "Contract ID"}
-
-			t := common.CreateTable(headers, dataRows)
-
-			vpTop := viewport.New(75, 1)
-			vpTop.SetContent(fmt.Sprintf("Total: %s", fmt.Sprint(len(dataRows))))
-
-			vpBottom := viewport.New(75, 1)
-			vpBottom.SetContent("Press 'a' to export all addresses, 'h' to view holders")
+			view := tui.View{
+				Title:   "ALEX pairs",
+				Columns: columns,
+				Rows:    tui.Rows(dataRows),
+				Export:  "alex-tokens.csv",
+				Actions: pairActions(props.HeroClient),
+			}
 
-			m := tableModel{
-				table:          t,
-				viewportBottom: vpBottom,
-				viewportTop:    vpTop,
-				client:         props.HeroClient,
+			if !common.Interactive(c) {
+				return tui.PrintRows(c, view.Columns, view.Rows)
 			}
 
-			if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+			if err := tui.Run(c.Context, view); err != nil {
 				return err
 			}
 			return nil
@@ -1,15 +1,16 @@
 package names
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 
 	"github.com/charmbracelet/bubbles/table"
-	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/hashhavoc/teller/internal/commands/props"
 	"github.com/hashhavoc/teller/internal/common"
+	"github.com/hashhavoc/teller/internal/common/tui"
 	"github.com/jszwec/csvutil"
 	"github.com/urfave/cli/v2"
 )
@@ -41,49 +42,30 @@ func createLookupCommand(props *props.AppProps) *cli.Command {
 		Action: func(c *cli.Context) error {
 			var rows []table.Row
 
-			theName, err := props.HeroClient.GetName(c.String("name"))
+			theName, err := props.HeroClient.GetNameContext(c.Context, c.String("name"))
 			if err != nil {
 				return err
 			}
 
-			headers := []table.Column{
-				{Title: "Name", Width: len("0xce6a4bec9c1c3297e2a66cca212e3b29940b93066bedc4700931dea7e98c2d6a")},
-				{Title: "Address", Width: len("SPSR9XHHRG3XYQ59A13Z1WSWESPRDBXCGX9VXEMP")},
-				{Title: "Expire Block", Width: len("5000000")},
-				{Title: "Registered Block", Width: len("5000000")},
-			}
-
-			maxWidths := make([]int, len(headers))
-			for i, header := range headers {
-				maxWidths[i] = header.Width
-			}
 			rows = append(rows, table.Row{
 				c.String("name"),
 				theName.Address,
+				fmt.Sprint(theName.ExpireBlock),
 			})
 
-			for _, row := range rows {
-				for i, cell := range row {
-					cellStr := fmt.Sprint(cell)
-					if len(cellStr) > maxWidths[i] {
-						maxWidths[i] = len(cellStr)
-					}
-				}
+			view := tui.View{
+				Title:   "BNS name " + c.String("name"),
+				Columns: lookupColumns,
+				Rows:    rows,
+				Export:  "name.csv",
+				Actions: nameActions(props),
 			}
 
-			for i, maxWidth := range maxWidths {
-				headers[i].Width = maxWidth
+			if !common.Interactive(c) {
+				return tui.PrintRows(c, view.Columns, view.Rows)
 			}
 
-			t := table.New(
-				table.WithColumns(headers),
-				table.WithRows(rows),
-				table.WithFocused(true),
-				table.WithStyles(common.TableStyles),
-			)
-
-			m := tableModel{table: t, client: props.HeroClient, logger: props.Logger, page: 1}
-			if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+			if err := tui.Run(c.Context, view); err != nil {
 				props.Logger.Fatal().Err(err).Msg("Failed to run program")
 			}
 			return nil
@@ -110,16 +92,16 @@ func createSyncCommand(props *props.AppProps) *cli.Command {
 			},
 		},
 		Action: func(c *cli.Context) error {
-			return syncNames(props, c.String("type"), c.String("file"))
+			return syncNames(c.Context, props, c.String("type"), c.String("file"))
 		},
 	}
 }
 
-func syncNames(props *props.AppProps, format string, filename string) error {
+func syncNames(ctx context.Context, props *props.AppProps, format string, filename string) error {
 	var data []byte
 	var err error
 
-	allNames, err := props.HeroClient.GetAllNames()
+	allNames, err := props.HeroClient.GetAllNamesContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -153,23 +135,11 @@ func createViewCommand(props *props.AppProps) *cli.Command {
 		Action: func(c *cli.Context) error {
 			var rows []table.Row
 
-			allNames, err := props.HeroClient.GetAllNames()
+			allNames, err := props.HeroClient.GetAllNamesContext(c.Context)
 			if err != nil {
 				return err
 			}
 
-			// Prepare the table
-			headers := []table.Column{
-				{Title: "Name", Width: len("0xce6a4bec9c1c3297e2a66cca212e3b29940b93066bedc4700931dea7e98c2d6a")},
-				{Title: "Address", Width: len("SPSR9XHHRG3XYQ59A13Z1WSWESPRDBXCGX9VXEMP")},
-				{Title: "Expire Block", Width: len("500000000000000")},
-				{Title: "Registered Block", Width: len("500000000000000")},
-			}
-
-			maxWidths := make([]int, len(headers))
-			for i, header := range headers {
-				maxWidths[i] = header.Width
-			}
 			for _, name := range allNames {
 				rows = append(rows, table.Row{
 					name.Name,
@@ -179,29 +149,19 @@ func createViewCommand(props *props.AppProps) *cli.Command {
 				})
 			}
 
-			for _, row := range rows {
-				for i, cell := range row {
-					cellStr := fmt.Sprint(cell)
-					if len(cellStr) > maxWidths[i] {
-						maxWidths[i] = len(cellStr)
-					}
-				}
+			view := tui.View{
+				Title:   "BNS names",
+				Columns: nameColumns,
+				Rows:    rows,
+				Export:  "names.csv",
+				Actions: nameActions(props),
 			}
 
-			for i, maxWidth := range maxWidths {
-				headers[i].Width = maxWidth
+			if !common.Interactive(c) {
+				return tui.PrintRows(c, view.Columns, view.Rows)
 			}
 
-			t := table.New(
-				table.WithColumns(headers),
-				table.WithRows(rows),
-				table.WithFocused(true),
-				table.WithStyles(common.TableStyles),
-			)
-
-			// Render the table
-			m := tableModel{table: t, client: props.HeroClient, logger: props.Logger, page: 1}
-			if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+			if err := tui.Run(c.Context, view); err != nil {
 				props.Logger.Fatal().Err(err).Msg("Failed to run program")
 			}
 			return nil
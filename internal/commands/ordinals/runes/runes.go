@@ -3,10 +3,9 @@ package runes
 import (
 	"fmt"
 
-	"github.com/charmbracelet/bubbles/viewport"
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/hashhavoc/teller/internal/commands/props"
 	"github.com/hashhavoc/teller/internal/common"
+	"github.com/hashhavoc/teller/internal/common/tui"
 	"github.com/hashhavoc/teller/pkg/api/ord"
 	"github.com/urfave/cli/v2"
 )
@@ -16,31 +15,25 @@ func CreateRunesCommand(props *props.AppProps) *cli.Command {
 		Name:  "runes",
 		Usage: "Provides interactions with runes",
 		Action: func(c *cli.Context) error {
-			resp, err := props.OrdClient.GetAllRunes()
+			resp, err := props.OrdClient.GetAllRunesContext(c.Context)
 			if err != nil {
 				props.Logger.Fatal().Err(err).Msg("Error getting all tokens")
 			}
 			dataRows := generateTableData(resp)
 
-			headers := []string{"Name", "Mints", "Premine", "Total Supply", "Cap", "Premine %", "Block", "Terms"}
-
-			t := common.CreateTable(headers, dataRows)
-
-			vpTop := viewport.New(75, 1)
-			vpTop.SetContent(fmt.Sprintf("Total: %s", fmt.Sprint(len(dataRows))))
-
-			vpBottom := viewport.New(75, 1)
-			vpBottom.SetContent("Press 'c' to copy name, 'a' to export all runes")
+			view := tui.View{
+				Title:   "Runes",
+				Columns: columns,
+				Rows:    tui.Rows(dataRows),
+				Export:  "runes.csv",
+				Actions: runeActions,
+			}
 
-			m := tableModel{
-				table:          t,
-				viewportBottom: vpBottom,
-				viewportTop:    vpTop,
-				client:         props.HeroClient,
-				logger:         props.Logger,
+			if !common.Interactive(c) {
+				return tui.PrintRows(c, view.Columns, view.Rows)
 			}
 
-			if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+			if err := tui.Run(c.Context, view); err != nil {
 				props.Logger.Fatal().Err(err).Msg("Failed to run program")
 			}
 			return nil
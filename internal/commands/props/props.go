@@ -1,7 +1,10 @@
 package props
 
 import (
+	"fmt"
+
 	"github.com/phuslu/log"
+	"github.com/urfave/cli/v2"
 
 	"github.com/hashhavoc/teller/internal/config"
 	"github.com/hashhavoc/teller/pkg/api/alex"
@@ -9,6 +12,8 @@ import (
 	"github.com/hashhavoc/teller/pkg/api/hiro"
 	"github.com/hashhavoc/teller/pkg/api/ord"
 	"github.com/hashhavoc/teller/pkg/api/stxtools"
+	"github.com/hashhavoc/teller/pkg/api/transport"
+	"github.com/hashhavoc/teller/pkg/stacks/address"
 )
 
 type AppProps struct {
@@ -17,6 +22,7 @@ type AppProps struct {
 	StxToolsClient *stxtools.APIClient
 	OrdClient      *ord.APIClient
 	BobClient      *gobob.APIClient
+	Cache          *transport.Cache
 	Config         *config.Config
 	Logger         log.Logger
 }
@@ -24,3 +30,47 @@ type AppProps struct {
 func NewAppProps() *AppProps {
 	return &AppProps{}
 }
+
+// NetworkName returns the network a command runs against: its --network
+// flag when given, else the network of the active profile.
+func (p *AppProps) NetworkName(c *cli.Context) string {
+	if c.IsSet("network") {
+		return c.String("network")
+	}
+	return p.Config.NetworkName()
+}
+
+// CheckPrincipal is the Action of flags taking a standard or contract
+// principal. It rejects principals that do not decode, and those of
+// another network than the command runs against.
+func (p *AppProps) CheckPrincipal(c *cli.Context, principal string) error {
+	parsed, err := address.ParsePrincipal(principal)
+	if err != nil {
+		return err
+	}
+	return p.checkNetwork(c, parsed)
+}
+
+// CheckContract is CheckPrincipal for flags that only take contract
+// principals.
+func (p *AppProps) CheckContract(c *cli.Context, contract string) error {
+	parsed, err := address.ParseContract(contract)
+	if err != nil {
+		return err
+	}
+	return p.checkNetwork(c, parsed)
+}
+
+func (p *AppProps) checkNetwork(c *cli.Context, principal address.Principal) error {
+	network, err := address.NetworkByName(p.NetworkName(c))
+	if err != nil {
+		return err
+	}
+	if err := principal.Check(network); err != nil {
+		if profile := p.Config.ActiveProfile(); profile != "" && !c.IsSet("network") {
+			return fmt.Errorf("%w (profile %s)", err, profile)
+		}
+		return err
+	}
+	return nil
+}
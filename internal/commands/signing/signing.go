@@ -0,0 +1,341 @@
+// Package signing holds the flags and steps shared by commands that build,
+// sign and broadcast Stacks transactions.
+package signing
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hashhavoc/teller/internal/commands/props"
+	"github.com/hashhavoc/teller/internal/common"
+	"github.com/hashhavoc/teller/pkg/api/hiro"
+	"github.com/hashhavoc/teller/pkg/clarity"
+	"github.com/hashhavoc/teller/pkg/keystore"
+	"github.com/hashhavoc/teller/pkg/stacks/keys"
+	"github.com/hashhavoc/teller/pkg/stacks/transaction"
+	"github.com/jedib0t/go-pretty/table"
+	"github.com/urfave/cli/v2"
+)
+
+const waitInterval = 10 * time.Second
+
+// Flags returns the flags used to sign and submit a transaction.
+func Flags() []cli.Flag {
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Name:    "from",
+			Usage:   "name of the keystore key to sign with (default: the only key in the keystore)",
+			EnvVars: []string{"TELLER_KEY"},
+		},
+	}
+	flags = append(flags, BuildFlags()...)
+	return append(flags, SubmitFlags()...)
+}
+
+// BuildFlags returns the flags setting the network, nonce, fee and post
+// condition mode of a transaction.
+func BuildFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "network",
+			Aliases: []string{"n"},
+			Usage:   "network to build the transaction for: mainnet, testnet or devnet (default: the network of the profile)",
+		},
+		&cli.Uint64Flag{
+			Name:  "nonce",
+			Usage: "account nonce (default: next nonce reported by the API)",
+		},
+		&cli.Uint64Flag{
+			Name:  "fee",
+			Usage: "fee in micro-STX (default: estimated from the transaction size)",
+		},
+		&cli.StringFlag{
+			Name:  "post-condition-mode",
+			Usage: "allow or deny asset transfers not covered by post conditions",
+			Value: "deny",
+		},
+	}
+}
+
+// SubmitFlags returns the flags read by Submit.
+func SubmitFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "broadcast",
+			Usage: "broadcast the signed transaction instead of printing it",
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "print the decoded transaction without broadcasting it",
+			Action: func(c *cli.Context, dryRun bool) error {
+				if dryRun && c.Bool("broadcast") {
+					return errors.New("--dry-run and --broadcast cannot be used together")
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// Network returns the network selected by the network flag, or that of
+// the active profile.
+func Network(c *cli.Context, props *props.AppProps) (transaction.Network, error) {
+	return transaction.NetworkByName(props.NetworkName(c))
+}
+
+// Key unlocks the keystore key selected by the from flag. When the flag is
+// not given the keystore must hold exactly one key.
+func Key(c *cli.Context, props *props.AppProps) (*keys.PrivateKey, error) {
+	name := c.String("from")
+	if name == "" {
+		stored, skipped, err := keystore.New(props.Config.KeystoreDir()).List()
+		if err != nil {
+			return nil, err
+		}
+		for _, err := range skipped {
+			fmt.Fprintf(os.Stderr, "Skipping unreadable keystore file: %v\n", err)
+		}
+		switch len(stored) {
+		case 0:
+			return nil, errors.New("the keystore is empty, add a key with 'teller wallet keys import'")
+		case 1:
+			name = stored[0].Name
+		default:
+			return nil, errors.New("the keystore holds several keys, select one with --from")
+		}
+	}
+	return Unlock(props, name)
+}
+
+// Unlock prompts for the passphrase of the named keystore key and decrypts
+// it.
+func Unlock(props *props.AppProps, name string) (*keys.PrivateKey, error) {
+	stored, err := keystore.New(props.Config.KeystoreDir()).Load(name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	passphrase, err := common.ReadPassphrase(fmt.Sprintf("Passphrase for %s: ", name), false)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := stored.Decrypt(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return keys.ParsePrivateKey(string(secret))
+}
+
+// Sign prepares tx with the build flags and signs it with key.
+func Sign(c *cli.Context, props *props.AppProps, tx *transaction.Transaction, key *keys.PrivateKey) error {
+	if err := Prepare(c, props, tx); err != nil {
+		return err
+	}
+	return tx.Sign(key)
+}
+
+// Prepare applies the post condition mode, nonce and fee flags to tx,
+// looking up the nonce and estimating the fee when they are not given.
+func Prepare(c *cli.Context, props *props.AppProps, tx *transaction.Transaction) error {
+	mode, err := transaction.ParsePostConditionMode(c.String("post-condition-mode"))
+	if err != nil {
+		return err
+	}
+	tx.PostConditionMode = mode
+
+	network, err := tx.Network()
+	if err != nil {
+		return err
+	}
+	origin := &tx.Auth.Origin
+
+	if c.IsSet("nonce") {
+		origin.Nonce = c.Uint64("nonce")
+	} else {
+		address := origin.Address(network)
+		nonces, err := props.HeroClient.GetNoncesContext(c.Context, address)
+		if err != nil {
+			return fmt.Errorf("error fetching nonce for %s: %w", address, err)
+		}
+		origin.Nonce = uint64(nonces.PossibleNextNonce)
+	}
+
+	if c.IsSet("fee") {
+		origin.Fee = c.Uint64("fee")
+	} else {
+		fee, err := EstimateFee(c.Context, props, tx)
+		if err != nil {
+			return err
+		}
+		origin.Fee = fee
+	}
+	return nil
+}
+
+// EstimateFee multiplies the API fee rate by the size of the transaction.
+// Single signatures have a fixed size so the estimate holds once signed,
+// multisig public keys grow by 32 bytes when replaced by signatures.
+func EstimateFee(ctx context.Context, props *props.AppProps, tx *transaction.Transaction) (uint64, error) {
+	rate, err := props.HeroClient.GetTransferFeeRateContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error estimating fee: %w", err)
+	}
+	if rate == 0 {
+		rate = 1
+	}
+	b, err := tx.Serialize()
+	if err != nil {
+		return 0, err
+	}
+	size := len(b)
+	if origin := tx.Auth.Origin; len(origin.Fields) > 0 {
+		size += 32 * (int(origin.SignaturesRequired) - origin.SignatureCount())
+	}
+	return rate * uint64(size), nil
+}
+
+// Submit prints the decoded transaction for --dry-run, broadcasts it for
+// --broadcast and otherwise prints its hex encoding. The txid is returned
+// when the transaction was broadcast.
+func Submit(c *cli.Context, props *props.AppProps, tx *transaction.Transaction) (string, error) {
+	b, err := tx.Serialize()
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case c.Bool("dry-run"):
+		// Render what was serialized rather than the builder's view of it.
+		decoded, err := transaction.Decode(b)
+		if err != nil {
+			return "", err
+		}
+		return "", Render(decoded)
+	case c.Bool("broadcast"):
+		txid, err := props.HeroClient.BroadcastTransactionContext(c.Context, b)
+		if err != nil {
+			return "", err
+		}
+		fmt.Println(txid)
+		return txid, nil
+	default:
+		fmt.Println(hex.EncodeToString(b))
+		return "", nil
+	}
+}
+
+// Wait polls the API until the transaction leaves the mempool or the
+// timeout passes. It returns an error unless the transaction succeeded or
+// ctx is done first.
+func Wait(ctx context.Context, props *props.AppProps, txid string, timeout time.Duration) (hiro.Tx, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		tx, err := props.HeroClient.GetTransactionContext(ctx, txid)
+		switch {
+		case errors.Is(err, hiro.ErrTransactionNotFound):
+			// The API indexes broadcast transactions after a short delay.
+		case err != nil:
+			return hiro.Tx{}, err
+		case tx.TxStatus == "success":
+			return tx, nil
+		case tx.TxStatus != "pending":
+			return tx, fmt.Errorf("transaction %s failed with status %s: %s", txid, tx.TxStatus, tx.TxResult.Repr)
+		}
+
+		if time.Now().After(deadline) {
+			return hiro.Tx{}, fmt.Errorf("transaction %s was not confirmed within %s", txid, timeout)
+		}
+		props.Logger.Info().Str("txid", txid).Msg("Waiting for confirmation")
+		select {
+		case <-ctx.Done():
+			return hiro.Tx{}, ctx.Err()
+		case <-time.After(waitInterval):
+		}
+	}
+}
+
+// Render prints the fields of a transaction as a table.
+func Render(tx *transaction.Transaction) error {
+	txid, err := tx.TxID()
+	if err != nil {
+		return err
+	}
+	network, err := tx.Network()
+	if err != nil {
+		return err
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleRounded)
+	t.AppendHeader(table.Row{"Field", "Value"})
+	t.AppendRow(table.Row{"TxID", "0x" + txid})
+	t.AppendRow(table.Row{"Network", network.Name})
+	t.AppendRow(table.Row{"Chain ID", fmt.Sprintf("0x%08x", tx.ChainID)})
+	t.AppendRow(table.Row{"Sender", tx.Auth.Origin.Address(network)})
+	t.AppendRow(table.Row{"Hash Mode", tx.Auth.Origin.HashMode})
+	t.AppendRow(table.Row{"Nonce", tx.Auth.Origin.Nonce})
+	t.AppendRow(table.Row{"Fee", tx.Auth.Origin.Fee})
+	t.AppendRow(table.Row{"Signature", signatureStatus(tx)})
+	for i, f := range tx.Auth.Origin.Fields {
+		key := "signed"
+		if !f.IsSignature() {
+			key = hex.EncodeToString(f.PublicKey)
+		}
+		t.AppendRow(table.Row{fmt.Sprintf("Key %d", i+1), key})
+	}
+	if tx.Auth.Sponsor != nil {
+		t.AppendRow(table.Row{"Sponsor", tx.Auth.Sponsor.Address(network)})
+	}
+	t.AppendRow(table.Row{"Anchor Mode", tx.AnchorMode})
+	t.AppendRow(table.Row{"Post Condition Mode", tx.PostConditionMode})
+	for i, pc := range tx.PostConditions {
+		t.AppendRow(table.Row{fmt.Sprintf("Post Condition %d", i+1), pc.String()})
+	}
+	t.AppendRow(table.Row{"Payload", tx.Payload.PayloadType()})
+
+	switch p := tx.Payload.(type) {
+	case *transaction.TokenTransferPayload:
+		t.AppendRow(table.Row{"Recipient", clarity.Display(p.Recipient)})
+		t.AppendRow(table.Row{"Amount", p.Amount})
+		t.AppendRow(table.Row{"Memo", p.MemoString()})
+	case *transaction.SmartContractPayload:
+		t.AppendRow(table.Row{"Contract", fmt.Sprintf("%s.%s", tx.Auth.Origin.Address(network), p.Name)})
+		if p.ClarityVersion != 0 {
+			t.AppendRow(table.Row{"Clarity Version", p.ClarityVersion})
+		}
+		t.AppendRow(table.Row{"Code Size", fmt.Sprintf("%d bytes", len(p.Code))})
+	case *transaction.ContractCallPayload:
+		t.AppendRow(table.Row{"Contract", p.Contract.ContractID()})
+		t.AppendRow(table.Row{"Function", p.Function})
+		for i, arg := range p.Args {
+			t.AppendRow(table.Row{fmt.Sprintf("Argument %d", i+1), arg.String()})
+		}
+	}
+	t.Render()
+	return nil
+}
+
+func signatureStatus(tx *transaction.Transaction) string {
+	origin := tx.Auth.Origin
+	if len(origin.Fields) > 0 {
+		status := fmt.Sprintf("%d of %d signatures", origin.SignatureCount(), origin.SignaturesRequired)
+		if origin.SignatureCount() < int(origin.SignaturesRequired) {
+			return status
+		}
+		if err := tx.VerifyOrigin(); err != nil {
+			return status + ", invalid: " + err.Error()
+		}
+		return status + ", valid"
+	}
+	if origin.Signature == [65]byte{} {
+		return "unsigned"
+	}
+	if err := tx.VerifyOrigin(); err != nil {
+		return "invalid: " + err.Error()
+	}
+	return "valid"
+}
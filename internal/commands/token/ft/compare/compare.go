@@ -3,10 +3,9 @@ package compare
 import (
 	"fmt"
 
-	"github.com/charmbracelet/bubbles/viewport"
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/hashhavoc/teller/internal/commands/props"
 	"github.com/hashhavoc/teller/internal/common"
+	"github.com/hashhavoc/teller/internal/common/tui"
 	"github.com/hashhavoc/teller/pkg/api/hiro"
 	"github.com/hashhavoc/teller/pkg/utils/uint128"
 	"github.com/urfave/cli/v2"
@@ -22,6 +21,7 @@ func CreateFungibleTokenHoldersCompareCommand(props *props.AppProps) *cli.Comman
 				Usage:    "Contract address",
 				Aliases:  []string{"c"},
 				Required: true,
+				Action:   props.CheckContract,
 			},
 			&cli.IntFlag{
 				Name:    "first",
@@ -37,37 +37,31 @@ func CreateFungibleTokenHoldersCompareCommand(props *props.AppProps) *cli.Comman
 			},
 		},
 		Action: func(c *cli.Context) error {
-			firstResp, err := props.HeroClient.GetTokenHolders(c.String("contract"), c.Int("first"))
+			firstResp, err := props.HeroClient.GetTokenHoldersContext(c.Context, c.String("contract"), c.Int("first"))
 			if err != nil {
 				props.Logger.Fatal().Err(err).Msg("Error getting all tokens")
 			}
 
-			secondResp, err := props.HeroClient.GetTokenHolders(c.String("contract"), c.Int("second"))
+			secondResp, err := props.HeroClient.GetTokenHoldersContext(c.Context, c.String("contract"), c.Int("second"))
 			if err != nil {
 				props.Logger.Fatal().Err(err).Msg("Error getting all tokens")
 			}
 
 			dataRows := generateTableData(firstResp, secondResp)
 
-			headers := []string{"Address", "First", "Second"}
-
-			t := common.CreateTable(headers, dataRows)
-
-			vpTop := viewport.New(75, 1)
-			vpTop.SetContent(fmt.Sprintf("Total: %s", fmt.Sprint(len(dataRows))))
-
-			vpBottom := viewport.New(75, 1)
-			vpBottom.SetContent("Press 's' to export all addresses, 'enter' to open address in explorer, 1-9 to sort by column")
+			view := tui.View{
+				Title:   fmt.Sprintf("Holders of %s at blocks %d and %d", c.String("contract"), c.Int("first"), c.Int("second")),
+				Columns: []tui.Column{{Title: "Address", Kind: tui.Address}, {Title: "First", Kind: tui.Uint128}, {Title: "Second", Kind: tui.Uint128}},
+				Rows:    tui.Rows(dataRows),
+				Export:  c.String("contract") + "-compare.csv",
+				Actions: holderActions(props),
+			}
 
-			m := tableModel{
-				table:          t,
-				viewportBottom: vpBottom,
-				viewportTop:    vpTop,
-				client:         props.HeroClient,
-				logger:         props.Logger,
+			if !common.Interactive(c) {
+				return tui.PrintRows(c, view.Columns, view.Rows)
 			}
 
-			if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+			if err := tui.Run(c.Context, view); err != nil {
 				props.Logger.Fatal().Err(err).Msg("Failed to run program")
 			}
 			return nil
@@ -89,7 +83,7 @@ func generateTableData(firstResp hiro.ContractHoldersResponse, secondResp hiro.C
 
 	// Process the first response
 	for address, amount := range firstResp {
-		value, err := uint128.FromString(amount)
+		value, err := uint128.FromDecimal(amount)
 		if err != nil {
 			continue
 		}
@@ -98,7 +92,7 @@ func generateTableData(firstResp hiro.ContractHoldersResponse, secondResp hiro.C
 
 	// Process the second response and calculate differences
 	for address, amount := range secondResp {
-		value, err := uint128.FromString(amount)
+		value, err := uint128.FromDecimal(amount)
 		if err != nil {
 			continue
 		}
@@ -1,12 +1,9 @@
 package holders
 
 import (
-	"fmt"
-
-	"github.com/charmbracelet/bubbles/viewport"
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/hashhavoc/teller/internal/commands/props"
 	"github.com/hashhavoc/teller/internal/common"
+	"github.com/hashhavoc/teller/internal/common/tui"
 	"github.com/hashhavoc/teller/pkg/api/hiro"
 	"github.com/urfave/cli/v2"
 )
@@ -21,6 +18,7 @@ func CreateFungibleTokenHoldersCommand(props *props.AppProps) *cli.Command {
 				Usage:    "Contract address",
 				Aliases:  []string{"c"},
 				Required: true,
+				Action:   props.CheckContract,
 			},
 			&cli.IntFlag{
 				Name:    "block",
@@ -30,31 +28,25 @@ func CreateFungibleTokenHoldersCommand(props *props.AppProps) *cli.Command {
 			},
 		},
 		Action: func(c *cli.Context) error {
-			resp, err := props.HeroClient.GetTokenHolders(c.String("contract"), c.Int("block"))
+			resp, err := props.HeroClient.GetTokenHoldersContext(c.Context, c.String("contract"), c.Int("block"))
 			if err != nil {
 				props.Logger.Fatal().Err(err).Msg("Error getting all tokens")
 			}
 			dataRows := generateTableData(resp)
 
-			headers := []string{"Address", "Balance"}
-
-			t := common.CreateTable(headers, dataRows)
-
-			vpTop := viewport.New(75, 1)
-			vpTop.SetContent(fmt.Sprintf("Total: %s", fmt.Sprint(len(dataRows))))
-
-			vpBottom := viewport.New(75, 1)
-			vpBottom.SetContent("Press 's' to export all addresses, 'enter' to open address in explorer, 1-9 to sort by column")
+			view := tui.View{
+				Title:   "Holders of " + c.String("contract"),
+				Columns: []tui.Column{{Title: "Address", Kind: tui.Address}, {Title: "Balance", Kind: tui.Uint128}},
+				Rows:    tui.Rows(dataRows),
+				Export:  c.String("contract") + "-holders.csv",
+				Actions: holderActions(props),
+			}
 
-			m := tableModel{
-				table:          t,
-				viewportBottom: vpBottom,
-				viewportTop:    vpTop,
-				client:         props.HeroClient,
-				logger:         props.Logger,
+			if !common.Interactive(c) {
+				return tui.PrintRows(c, view.Columns, view.Rows)
 			}
 
-			if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+			if err := tui.Run(c.Context, view); err != nil {
 				props.Logger.Fatal().Err(err).Msg("Failed to run program")
 			}
 			return nil
@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	"github.com/hashhavoc/teller/internal/commands/props"
+	"github.com/hashhavoc/teller/internal/common"
+	"github.com/hashhavoc/teller/internal/common/tui"
 	"github.com/urfave/cli/v2"
 )
 
@@ -29,13 +31,22 @@ func createHoldingsCommand(props *props.AppProps) *cli.Command {
 				Usage:    "Principal address",
 				Aliases:  []string{"p"},
 				Required: true,
+				Action:   props.CheckPrincipal,
 			},
 		},
 		Action: func(c *cli.Context) error {
-			resp, err := props.HeroClient.GetNFTHoldings(c.String("principal"))
+			resp, err := props.HeroClient.GetNFTHoldingsContext(c.Context, c.String("principal"))
 			if err != nil {
 				props.Logger.Fatal().Err(err).Msg("Error getting nft holdings")
 			}
+			if common.OutputFormat(c) != common.OutputTable {
+				var rows [][]string
+				for _, nft := range resp {
+					split := strings.Split(nft.AssetIdentifier, "::")
+					rows = append(rows, []string{split[1], split[0], nft.Value.Repr, fmt.Sprint(nft.BlockHeight), nft.TxID})
+				}
+				return tui.PrintRows(c, []tui.Column{{Title: "Asset"}, {Title: "Contract ID", Kind: tui.Address}, {Title: "Value"}, {Title: "Block Height", Kind: tui.Integer}, {Title: "TxID"}}, rows)
+			}
 			for _, nft := range resp {
 				split := strings.Split(nft.AssetIdentifier, "::")
 				fmt.Println(split[1])
@@ -0,0 +1,107 @@
+package transactions
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashhavoc/teller/internal/commands/props"
+	"github.com/hashhavoc/teller/internal/common"
+	"github.com/hashhavoc/teller/internal/common/tui"
+	"github.com/hashhavoc/teller/pkg/txstore"
+	"github.com/urfave/cli/v2"
+)
+
+func createQueryCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "query",
+		Usage: "Query the synced transactions by principal, contract, event type and block height",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "principal",
+				Aliases: []string{"p"},
+				Usage:   "Only transactions synced for this principal",
+				Action:  props.CheckPrincipal,
+			},
+			&cli.StringFlag{
+				Name:    "contract",
+				Aliases: []string{"c"},
+				Usage:   "Only transactions calling or moving tokens of this contract",
+				Action:  props.CheckContract,
+			},
+			&cli.StringFlag{
+				Name:    "event",
+				Aliases: []string{"e"},
+				Usage:   fmt.Sprintf("Only transactions with events of this type, such as %s, %s or %s", txstore.EventSTXTransfer, txstore.EventFTTransfer, txstore.EventNFTMint),
+			},
+			&cli.IntFlag{
+				Name:  "from",
+				Usage: "Lowest block height",
+			},
+			&cli.IntFlag{
+				Name:  "to",
+				Usage: "Highest block height",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			q := txstore.Query{
+				Principal:  c.String("principal"),
+				Contract:   c.String("contract"),
+				EventType:  c.String("event"),
+				FromHeight: c.Int("from"),
+				ToHeight:   c.Int("to"),
+			}
+			if q.FromHeight < 0 || q.ToHeight < 0 || (q.ToHeight > 0 && q.ToHeight < q.FromHeight) {
+				return errors.New("invalid block range")
+			}
+
+			store, err := openStore(props)
+			if err != nil {
+				return err
+			}
+			txs, err := store.Find(q)
+			store.Close()
+			if err != nil {
+				return err
+			}
+
+			var filters []string
+			for _, f := range []struct{ name, value string }{
+				{"principal", q.Principal},
+				{"contract", q.Contract},
+				{"event", q.EventType},
+			} {
+				if f.value != "" {
+					filters = append(filters, f.name+" "+f.value)
+				}
+			}
+			if q.FromHeight > 0 {
+				filters = append(filters, fmt.Sprintf("from block %d", q.FromHeight))
+			}
+			if q.ToHeight > 0 {
+				filters = append(filters, fmt.Sprintf("to block %d", q.ToHeight))
+			}
+			title := "Synced transactions"
+			if len(filters) > 0 {
+				title += " with " + strings.Join(filters, ", ")
+			}
+
+			view := tui.View{
+				Title:   title,
+				Columns: columns,
+				Rows:    transactionRows(txs),
+				Export:  "transactions.csv",
+				Actions: transactionActions(props),
+			}
+
+			if !common.Interactive(c) {
+				return tui.PrintRows(c, view.Columns, view.Rows)
+			}
+
+			if err := tui.Run(c.Context, view); err != nil {
+				props.Logger.Fatal().Err(err).Msg("Failed to run program")
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,275 @@
+package transactions
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hashhavoc/teller/internal/commands/props"
+	"github.com/hashhavoc/teller/internal/common"
+	"github.com/hashhavoc/teller/internal/common/tui"
+	"github.com/hashhavoc/teller/pkg/api/hiro"
+	"github.com/hashhavoc/teller/pkg/clarity"
+	"github.com/hashhavoc/teller/pkg/utils"
+	"github.com/urfave/cli/v2"
+)
+
+const stxDecimals = 6
+
+// conditionCodes shortens the condition codes of the API to those used by
+// the post condition flags of contract call.
+var conditionCodes = map[string]string{
+	"sent_equal_to":                 "eq",
+	"sent_greater_than":             "gt",
+	"sent_greater_than_or_equal_to": "gte",
+	"sent_less_than":                "lt",
+	"sent_less_than_or_equal_to":    "lte",
+	"sent":                          "sent",
+	"not_sent":                      "not-sent",
+}
+
+func createShowCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:      "show",
+		Usage:     "Show a transaction with its decoded call, events, post conditions and result",
+		ArgsUsage: "txid",
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return fmt.Errorf("expected a transaction id")
+			}
+			txid, err := parseTxID(c.Args().First())
+			if err != nil {
+				return err
+			}
+
+			view, err := transactionDetail(c.Context, props, txid)
+			if err != nil {
+				return err
+			}
+
+			if !common.Interactive(c) {
+				return tui.PrintRows(c, view.Columns, view.Rows)
+			}
+
+			if err := tui.Run(c.Context, view); err != nil {
+				props.Logger.Fatal().Err(err).Msg("Failed to run program")
+			}
+			return nil
+		},
+	}
+}
+
+// parseTxID returns s as a 0x prefixed transaction id.
+func parseTxID(s string) (string, error) {
+	id := strings.TrimPrefix(strings.ToLower(s), "0x")
+	if b, err := hex.DecodeString(id); err != nil || len(b) != 32 {
+		return "", fmt.Errorf("invalid transaction id %s, expected 64 hex characters", s)
+	}
+	return "0x" + id, nil
+}
+
+// loadDetail fetches the transaction txid in the background and opens its
+// detail view.
+func loadDetail(ctx context.Context, props *props.AppProps, txid string) tea.Cmd {
+	return func() tea.Msg {
+		view, err := transactionDetail(ctx, props, txid)
+		if err != nil {
+			return tui.Error(err)
+		}
+		return tui.Push(view)
+	}
+}
+
+// transactionDetail returns a view of every field of the transaction txid,
+// one per row.
+func transactionDetail(ctx context.Context, props *props.AppProps, txid string) (tui.View, error) {
+	client := props.HeroClient
+	tx, err := client.GetTransactionContext(ctx, txid)
+	if err != nil {
+		return tui.View{}, err
+	}
+	if len(tx.Events) < tx.EventCount {
+		tx.Events, err = client.GetTransactionEventsContext(ctx, txid)
+		if err != nil {
+			return tui.View{}, err
+		}
+	}
+
+	d := &detail{ctx: ctx, client: client, decimals: make(map[string]int)}
+	d.add("TxID", tx.TxID)
+	d.add("Status", tx.TxStatus)
+	d.add("Type", tx.TxType)
+	if tx.BlockHeight > 0 {
+		d.add("Block", strconv.Itoa(tx.BlockHeight))
+	}
+	if !tx.BlockTimeIso.IsZero() {
+		d.add("Time", tx.BlockTimeIso.UTC().Format("2006-01-02 15:04:05"))
+	}
+	d.add("Sender", tx.SenderAddress)
+	d.add("Nonce", strconv.Itoa(tx.Nonce))
+	d.add("Fee", common.InsertDecimal(tx.FeeRate, stxDecimals)+" STX")
+
+	switch tx.TxType {
+	case "token_transfer":
+		d.add("Recipient", tx.TokenTransfer.RecipientAddress)
+		d.add("Amount", common.InsertDecimal(tx.TokenTransfer.Amount, stxDecimals)+" STX")
+		if memo := memoString(tx.TokenTransfer.Memo); memo != "" {
+			d.add("Memo", memo)
+		}
+	case "contract_call":
+		call := tx.ContractCall
+		d.add("Contract", call.ContractId)
+		d.add("Function", call.FunctionName)
+		expr := []string{"contract-call?", "'" + call.ContractId, call.FunctionName}
+		args := make([]string, len(call.FunctionArgs))
+		for i, arg := range call.FunctionArgs {
+			args[i] = decodeValue(arg)
+		}
+		d.add("Call", "("+strings.Join(append(expr, args...), " ")+")")
+		for i, arg := range call.FunctionArgs {
+			d.add("Argument "+arg.Name, args[i])
+		}
+	}
+
+	var events, logs int
+	for _, e := range tx.Events {
+		if e.EventType == "smart_contract_log" {
+			logs++
+			d.add(fmt.Sprintf("Log %d", logs), fmt.Sprintf("%s %s %s", e.ContractLog.ContractId, e.ContractLog.Topic, decodeValue(e.ContractLog.Value)))
+			continue
+		}
+		events++
+		d.add(fmt.Sprintf("Event %d", events), d.event(e))
+	}
+
+	d.add("Post Condition Mode", tx.PostConditionMode)
+	for i, pc := range tx.PostConditions {
+		d.add(fmt.Sprintf("Post Condition %d", i+1), d.postCondition(pc))
+	}
+
+	if tx.TxResult.Hex != "" || tx.TxResult.Repr != "" {
+		d.add("Result", decodeValue(hiro.ClarityValue{Hex: tx.TxResult.Hex, Repr: tx.TxResult.Repr}))
+	}
+
+	return tui.View{
+		Title:   "Transaction " + tx.TxID,
+		Columns: detailColumns,
+		Rows:    d.rows,
+		Export:  tx.TxID + ".csv",
+		Actions: []tui.Action{
+			{
+				Key:  "o",
+				Help: "open in the explorer",
+				Run: func(ctx context.Context, row table.Row) tea.Cmd {
+					utils.OpenBrowser(props.Config.ExplorerURL("txid/" + tx.TxID))
+					return nil
+				},
+			},
+		},
+	}, nil
+}
+
+// detail builds the rows of a transaction detail view, looking up the
+// decimals of each token once.
+type detail struct {
+	ctx      context.Context
+	client   *hiro.APIClient
+	decimals map[string]int
+	rows     []table.Row
+}
+
+func (d *detail) add(field, value string) {
+	d.rows = append(d.rows, table.Row{field, value})
+}
+
+func (d *detail) event(e hiro.Event) string {
+	a := e.Asset
+	switch e.EventType {
+	case "stx_asset":
+		return transfer("STX "+a.AssetEventType, common.InsertDecimal(a.Amount, stxDecimals)+" STX", a.Sender, a.Recipient)
+	case "fungible_token_asset":
+		return transfer("FT "+a.AssetEventType, d.tokenAmount(a.AssetId, a.Amount)+" "+a.AssetId, a.Sender, a.Recipient)
+	case "non_fungible_token_asset":
+		return transfer("NFT "+a.AssetEventType, a.AssetId+" "+decodeValue(a.Value), a.Sender, a.Recipient)
+	case "stx_lock":
+		lock := e.StxLockEvent
+		return fmt.Sprintf("STX lock %s STX by %s until block %d", common.InsertDecimal(lock.LockedAmount, stxDecimals), lock.LockedAddress, lock.UnlockHeight)
+	default:
+		return e.EventType
+	}
+}
+
+// transfer describes an asset event, mints have no sender and burns no
+// recipient.
+func transfer(kind, amount, sender, recipient string) string {
+	s := kind + " " + amount
+	if sender != "" {
+		s += " from " + sender
+	}
+	if recipient != "" {
+		s += " to " + recipient
+	}
+	return s
+}
+
+func (d *detail) postCondition(pc hiro.PostCondition) string {
+	principal := pc.Principal.Address
+	switch pc.Principal.TypeID {
+	case "principal_origin":
+		principal = "origin"
+	case "principal_contract":
+		principal += "." + pc.Principal.ContractName
+	}
+	code, ok := conditionCodes[pc.ConditionCode]
+	if !ok {
+		code = pc.ConditionCode
+	}
+	asset := fmt.Sprintf("%s.%s::%s", pc.Asset.ContractAddress, pc.Asset.ContractName, pc.Asset.AssetName)
+	switch pc.Type {
+	case "stx":
+		return fmt.Sprintf("stx %s %s %s STX", principal, code, common.InsertDecimal(pc.Amount, stxDecimals))
+	case "fungible":
+		return fmt.Sprintf("ft %s %s %s %s", principal, code, d.tokenAmount(asset, pc.Amount), asset)
+	case "non_fungible":
+		return fmt.Sprintf("nft %s %s %s %s", principal, code, asset, decodeValue(pc.AssetValue))
+	default:
+		return pc.Type
+	}
+}
+
+// tokenAmount inserts the decimals of the token assetID into amount, leaving
+// it raw when they cannot be read.
+func (d *detail) tokenAmount(assetID, amount string) string {
+	contractID, _, _ := strings.Cut(assetID, "::")
+	decimals, ok := d.decimals[contractID]
+	if !ok {
+		resp, err := d.client.GetContractReadOnlyContext(d.ctx, contractID, "get-decimals", "", []string{})
+		if err == nil {
+			decimals, _ = strconv.Atoi(clarity.Display(clarity.Unwrap(resp)))
+		}
+		d.decimals[contractID] = decimals
+	}
+	return common.InsertDecimal(amount, decimals)
+}
+
+// decodeValue returns v in Clarity syntax, decoded from its hex, or the repr
+// of the API if the hex does not decode.
+func decodeValue(v hiro.ClarityValue) string {
+	if value, err := clarity.DecodeHex(v.Hex); err == nil {
+		return value.String()
+	}
+	return v.Repr
+}
+
+// memoString returns the text of a hex memo with its zero padding removed.
+func memoString(memo string) string {
+	b, err := hex.DecodeString(strings.TrimPrefix(memo, "0x"))
+	if err != nil {
+		return memo
+	}
+	return strings.TrimRight(string(b), "\x00")
+}
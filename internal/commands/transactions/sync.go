@@ -0,0 +1,152 @@
+package transactions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hashhavoc/teller/internal/commands/props"
+	"github.com/hashhavoc/teller/pkg/api/hiro"
+	"github.com/hashhavoc/teller/pkg/txstore"
+	"github.com/urfave/cli/v2"
+)
+
+// storeTimeout is how long to wait for another teller to close the store.
+const storeTimeout = 5 * time.Second
+
+func createSyncCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "sync",
+		Usage: "Sync transactions for a given principal into the local store",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "principal",
+				Aliases:  []string{"p"},
+				Usage:    "Specify the principal",
+				Required: true,
+				Action:   props.CheckPrincipal,
+			},
+			&cli.BoolFlag{
+				Name:  "full",
+				Usage: "Page through the whole history instead of stopping at the first known transaction",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			principal := c.String("principal")
+			return syncTransactions(c.Context, props, principal, c.Bool("full"))
+		},
+	}
+}
+
+// openStore opens the transaction store of the network of the profile.
+func openStore(props *props.AppProps) (*txstore.Store, error) {
+	return txstore.Open(props.Config.TxStorePath(props.Config.NetworkName()), storeTimeout)
+}
+
+// localTransactions returns the synced transactions of principal.
+func localTransactions(props *props.AppProps, principal string) ([]hiro.Transaction, error) {
+	store, err := openStore(props)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	state, err := store.SyncState(principal)
+	if err != nil {
+		return nil, err
+	}
+	if state.Synced.IsZero() {
+		return nil, fmt.Errorf("%s has not been synced, run transactions sync -p %s first", principal, principal)
+	}
+	return store.Principal(principal)
+}
+
+// syncTransactions stores the transactions of principal, newest first. Once
+// a sync has reached the first transaction of principal, later ones stop at
+// the first page with a transaction that is already stored unchanged.
+// Stored transactions in the range of the pages fetched that the API no
+// longer lists are checked one by one, to unindex those re-orged out.
+func syncTransactions(ctx context.Context, props *props.AppProps, principal string, full bool) error {
+	store, err := openStore(props)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	state, err := store.SyncState(principal)
+	if err != nil {
+		return err
+	}
+
+	var added, updated int
+	seen := make(map[string]bool)
+	lowest := 0
+	reachedKnown := false
+	for txs, err := range props.HeroClient.TransactionsPaginator(principal).Pages(ctx) {
+		if err != nil {
+			// The pages stored so far are kept, the next sync picks up
+			// the rest as the history is not complete yet.
+			return err
+		}
+		result, err := store.Put(principal, txs)
+		if err != nil {
+			return err
+		}
+		added += result.Added
+		updated += result.Updated
+		for _, tx := range txs {
+			seen[tx.Tx.TxID] = true
+			if lowest == 0 || tx.Tx.BlockHeight < lowest {
+				lowest = tx.Tx.BlockHeight
+			}
+		}
+		if state.Complete && !full && len(result.Known) > 0 {
+			reachedKnown = true
+			break
+		}
+		fmt.Fprintf(os.Stderr, "Synced %d transactions\n", len(seen))
+	}
+
+	// A pass that stopped early only covers the heights above the lowest
+	// one it fetched, as that block may continue on the next page.
+	from := 0
+	if reachedKnown {
+		from = lowest + 1
+	}
+	orphaned := 0
+	if lowest > 0 {
+		stored, err := store.Find(txstore.Query{Principal: principal, FromHeight: from})
+		if err != nil {
+			return err
+		}
+		for _, tx := range stored {
+			if seen[tx.Tx.TxID] {
+				continue
+			}
+			current, err := props.HeroClient.GetTransactionContext(ctx, tx.Tx.TxID)
+			if errors.Is(err, hiro.ErrTransactionNotFound) {
+				current = tx.Tx
+				current.Canonical = false
+			} else if err != nil {
+				return err
+			}
+			changed, err := store.Update(current)
+			if err != nil {
+				return err
+			}
+			if changed {
+				orphaned++
+			}
+		}
+	}
+
+	state.Complete = state.Complete || !reachedKnown
+	state.Synced = time.Now().UTC()
+	if err := store.SetSyncState(principal, state); err != nil {
+		return err
+	}
+
+	fmt.Printf("Synced transactions for principal %s: %d new, %d updated, %d re-orged\n", principal, added, updated, orphaned)
+	return nil
+}
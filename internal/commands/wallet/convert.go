@@ -0,0 +1,41 @@
+package wallet
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/hashhavoc/teller/internal/commands/props"
+	"github.com/hashhavoc/teller/internal/common/tui"
+	"github.com/hashhavoc/teller/pkg/stacks/address"
+	"github.com/urfave/cli/v2"
+)
+
+func createConvertCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:      "convert",
+		Usage:     "convert between the Stacks and Bitcoin forms of an address, which share the hash160",
+		ArgsUsage: "stacks or bitcoin address",
+		Action: func(c *cli.Context) error {
+			s := c.Args().First()
+			if s == "" {
+				return errors.New("an address is required")
+			}
+			var a address.Address
+			var err error
+			if strings.HasPrefix(s, "S") {
+				a, err = address.Decode(s)
+			} else {
+				a, err = address.FromBTC(s)
+			}
+			if err != nil {
+				return err
+			}
+			kind := "single signature"
+			if a.MultiSig() {
+				kind = "multisig"
+			}
+			return tui.PrintRows(c, []tui.Column{{Title: "Network"}, {Title: "Type"}, {Title: "Stacks", Kind: tui.Address}, {Title: "Bitcoin", Kind: tui.Address}},
+				[][]string{{a.Network().String(), kind, a.String(), a.BTC()}})
+		},
+	}
+}
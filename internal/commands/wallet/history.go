@@ -0,0 +1,411 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hashhavoc/teller/internal/commands/props"
+	"github.com/hashhavoc/teller/internal/common"
+	"github.com/hashhavoc/teller/internal/common/tui"
+	"github.com/hashhavoc/teller/pkg/api/hiro"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// historySamples is the number of heights sampled without --step.
+	historySamples = 30
+	// maxHistorySamples bounds the requests of a single range.
+	maxHistorySamples = 1000
+	// sparklineWidth is the width of the Trend column.
+	sparklineWidth = 40
+)
+
+// history is the summed balances of the configured wallets at each sampled
+// height.
+type history struct {
+	heights []int
+	times   []string
+	assets  []*historyAsset
+}
+
+// historyAsset is the balance of STX or a fungible token at each height of
+// its history.
+type historyAsset struct {
+	name string
+	// contractID is empty for STX.
+	contractID string
+	decimals   int
+	balances   []*big.Int
+}
+
+func createHistoryCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "history",
+		Usage: "sample the balances of the configured wallets across a range of block heights",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:     "from",
+				Usage:    "First block height of the range",
+				Required: true,
+			},
+			&cli.IntFlag{
+				Name:     "to",
+				Usage:    "Last block height of the range",
+				Required: true,
+			},
+			&cli.IntFlag{
+				Name:  "step",
+				Usage: fmt.Sprintf("Blocks between samples (default: %d samples across the range)", historySamples),
+			},
+			&cli.BoolFlag{
+				Name:  "refresh",
+				Usage: "Fetch every sample again instead of using the stored snapshots",
+			},
+			&cli.StringFlag{
+				Name:    "export",
+				Aliases: []string{"e"},
+				Usage:   "Write the series to a .csv or .json file",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			heights, err := sampleHeights(c.Int("from"), c.Int("to"), c.Int("step"))
+			if err != nil {
+				return err
+			}
+			export := c.String("export")
+			if export != "" {
+				if _, err := exportFormat(export); err != nil {
+					return err
+				}
+			}
+
+			h, err := loadHistory(c.Context, props, heights, c.Bool("refresh"))
+			if err != nil {
+				return err
+			}
+
+			if export != "" {
+				if err := exportHistory(h, export); err != nil {
+					return err
+				}
+				fmt.Fprintln(os.Stderr, "History exported to", export)
+			}
+
+			view := tui.View{
+				Title:   fmt.Sprintf("History of the configured wallets from block %d to %d, %d samples", heights[0], heights[len(heights)-1], len(heights)),
+				Columns: historyColumns,
+				Rows:    historyRows(h),
+				Export:  "history.csv",
+				Actions: historyActions(h),
+			}
+
+			if !common.Interactive(c) {
+				return tui.PrintRows(c, view.Columns, view.Rows)
+			}
+
+			if err := tui.Run(c.Context, view); err != nil {
+				props.Logger.Fatal().Err(err).Msg("Failed to run program")
+			}
+
+			return nil
+		},
+	}
+}
+
+// sampleHeights returns the heights from from to to every step blocks,
+// always ending at to.
+func sampleHeights(from, to, step int) ([]int, error) {
+	if from < 1 || to < from {
+		return nil, fmt.Errorf("invalid range %d to %d, expected 1 <= --from <= --to", from, to)
+	}
+	if step < 0 {
+		return nil, fmt.Errorf("invalid step %d", step)
+	}
+	if step == 0 {
+		step = max(1, (to-from+historySamples-1)/historySamples)
+	}
+	if (to-from)/step+2 > maxHistorySamples {
+		return nil, fmt.Errorf("%d to %d every %d blocks is more than %d samples, raise --step", from, to, step, maxHistorySamples)
+	}
+	var heights []int
+	for height := from; height < to; height += step {
+		heights = append(heights, height)
+	}
+	return append(heights, to), nil
+}
+
+// loadHistory samples the balances of the configured wallets at heights,
+// fetching only the snapshots that are not stored yet.
+func loadHistory(ctx context.Context, props *props.AppProps, heights []int, refresh bool) (*history, error) {
+	wallets := props.Config.Active().Wallets
+	if len(wallets) == 0 {
+		return nil, errors.New("no wallets configured, add one with wallet add")
+	}
+	// Balances above the tip are not known yet, and those close to it may
+	// still change with a fork, so only confirmed snapshots are stored.
+	tip, err := props.HeroClient.GetLatestBlockContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if last := heights[len(heights)-1]; last > tip.Height {
+		return nil, fmt.Errorf("block %d is above the chain tip %d", last, tip.Height)
+	}
+	confirmed := tip.Height - hiro.Confirmations
+
+	store := newSnapshotStore(props.Config.HistoryDir(), props.Config.NetworkName())
+	blocks := make(map[int]hiro.Block)
+
+	var snapshots []map[int]snapshot
+	for _, w := range wallets {
+		snaps, err := store.load(w)
+		if err != nil {
+			return nil, fmt.Errorf("error loading the snapshots of %s: %w", w, err)
+		}
+		var missing []int
+		for _, height := range heights {
+			if _, ok := snaps[height]; refresh || !ok || height > confirmed {
+				missing = append(missing, height)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "Wallet: %s, fetching %d of %d samples\n", w, len(missing), len(heights))
+		fetched, err := fetchSnapshots(ctx, props, w, missing, blocks, snaps)
+		// Keep the samples fetched before an error for the next run.
+		if fetched > 0 {
+			if err := store.save(w, snaps, confirmed); err != nil {
+				return nil, fmt.Errorf("error saving the snapshots of %s: %w", w, err)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snaps)
+	}
+
+	h := &history{heights: heights, times: make([]string, len(heights))}
+	stx := &historyAsset{name: "STX", decimals: stxDecimals}
+	tokens := make(map[string]*historyAsset)
+	for i, height := range heights {
+		stx.balances = append(stx.balances, new(big.Int))
+		for _, t := range tokens {
+			t.balances = append(t.balances, new(big.Int))
+		}
+		for _, snaps := range snapshots {
+			snap := snaps[height]
+			h.times[i] = snap.Time
+			if err := addBalance(stx.balances[i], snap.STX); err != nil {
+				return nil, fmt.Errorf("error parsing STX balance at %d: %w", height, err)
+			}
+			for k, balance := range snap.FungibleTokens {
+				t, ok := tokens[k]
+				if !ok {
+					contractID, name, _ := strings.Cut(k, "::")
+					t = &historyAsset{name: name, contractID: contractID}
+					for range i + 1 {
+						t.balances = append(t.balances, new(big.Int))
+					}
+					tokens[k] = t
+				}
+				if err := addBalance(t.balances[i], balance); err != nil {
+					return nil, fmt.Errorf("error parsing %s balance at %d: %w", k, height, err)
+				}
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(tokens))
+	for k := range tokens {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h.assets = append(h.assets, stx)
+	for _, k := range keys {
+		t := tokens[k]
+		if !t.held() {
+			continue
+		}
+		decimals, err := tokenDecimals(ctx, props, t.contractID, nil)
+		if err != nil {
+			props.Logger.Warn().Err(err).Str("contract", t.contractID).Msg("Failed to get decimals, showing raw balances")
+		}
+		t.decimals = decimals
+		h.assets = append(h.assets, t)
+	}
+	return h, nil
+}
+
+// fetchSnapshots adds the snapshots of wallet at heights to snaps,
+// returning how many it fetched.
+func fetchSnapshots(ctx context.Context, props *props.AppProps, wallet string, heights []int, blocks map[int]hiro.Block, snaps map[int]snapshot) (int, error) {
+	for i, height := range heights {
+		block, ok := blocks[height]
+		if !ok {
+			var err error
+			block, err = props.HeroClient.GetBlockByHeightContext(ctx, height)
+			if err != nil {
+				return i, err
+			}
+			blocks[height] = block
+		}
+		resp, err := props.HeroClient.GetAccountBalanceContext(ctx, wallet, height)
+		if err != nil {
+			return i, err
+		}
+		snap := snapshot{
+			Height:         height,
+			Hash:           block.Hash,
+			Time:           blockTime(block),
+			STX:            resp.Stx.Balance,
+			FungibleTokens: make(map[string]string),
+		}
+		for k, v := range resp.FungibleTokens {
+			snap.FungibleTokens[k] = v.Balance
+		}
+		snaps[height] = snap
+	}
+	return len(heights), nil
+}
+
+func blockTime(block hiro.Block) string {
+	if block.BlockTimeISO != "" {
+		return block.BlockTimeISO
+	}
+	if block.BlockTime != 0 {
+		return time.Unix(block.BlockTime, 0).UTC().Format(time.RFC3339)
+	}
+	return ""
+}
+
+// held reports whether any sample has a balance of a.
+func (a *historyAsset) held() bool {
+	for _, balance := range a.balances {
+		if balance.Sign() != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *historyAsset) amount(x *big.Int) string {
+	if x.Sign() < 0 {
+		return "-" + common.InsertDecimal(new(big.Int).Neg(x).String(), a.decimals)
+	}
+	return common.InsertDecimal(x.String(), a.decimals)
+}
+
+func (a *historyAsset) sparkline() string {
+	values := make([]float64, len(a.balances))
+	for i, balance := range a.balances {
+		values[i], _ = new(big.Float).SetInt(balance).Float64()
+	}
+	return common.Sparkline(values, sparklineWidth)
+}
+
+func historyRows(h *history) []table.Row {
+	rows := make([]table.Row, 0, len(h.assets))
+	for _, a := range h.assets {
+		low, high := a.balances[0], a.balances[0]
+		for _, balance := range a.balances {
+			if balance.Cmp(low) < 0 {
+				low = balance
+			}
+			if balance.Cmp(high) > 0 {
+				high = balance
+			}
+		}
+		first, last := a.balances[0], a.balances[len(a.balances)-1]
+		rows = append(rows, table.Row{
+			a.name,
+			a.contractID,
+			a.amount(first),
+			a.amount(last),
+			a.amount(new(big.Int).Sub(last, first)),
+			a.amount(low),
+			a.amount(high),
+			a.sparkline(),
+		})
+	}
+	return rows
+}
+
+// seriesRows returns the balance of a at each height of h, and its change
+// since the previous sample.
+func seriesRows(h *history, a *historyAsset) []table.Row {
+	rows := make([]table.Row, len(h.heights))
+	for i, height := range h.heights {
+		change := ""
+		if i > 0 {
+			change = a.amount(new(big.Int).Sub(a.balances[i], a.balances[i-1]))
+		}
+		rows[i] = table.Row{strconv.Itoa(height), h.times[i], a.amount(a.balances[i]), change}
+	}
+	return rows
+}
+
+func historyActions(h *history) []tui.Action {
+	return []tui.Action{
+		{
+			Key:  "enter",
+			Help: "view the balance at each sampled height",
+			Run: func(ctx context.Context, row table.Row) tea.Cmd {
+				for _, a := range h.assets {
+					if a.name == row[0] && a.contractID == row[1] {
+						return func() tea.Msg {
+							return tui.Push(tui.View{
+								Title:   "History of " + a.name,
+								Columns: seriesColumns,
+								Rows:    seriesRows(h, a),
+								Export:  a.name + "-history.csv",
+							})
+						}
+					}
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// exportFormat returns the output format of the extension of path.
+func exportFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return common.OutputCSV, nil
+	case ".json":
+		return common.OutputJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported export file %s, expected a .csv or .json file", path)
+	}
+}
+
+// exportHistory writes one row per asset and height to path.
+func exportHistory(h *history, path string) error {
+	format, err := exportFormat(path)
+	if err != nil {
+		return err
+	}
+	var rows [][]string
+	for i, height := range h.heights {
+		for _, a := range h.assets {
+			rows = append(rows, []string{strconv.Itoa(height), h.times[i], a.name, a.contractID, a.amount(a.balances[i])})
+		}
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := tui.WriteRows(file, format, exportColumns, rows); err != nil {
+		return err
+	}
+	return file.Close()
+}
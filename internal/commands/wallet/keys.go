@@ -0,0 +1,235 @@
+package wallet
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/hashhavoc/teller/internal/commands/props"
+	"github.com/hashhavoc/teller/internal/commands/signing"
+	"github.com/hashhavoc/teller/internal/common"
+	"github.com/hashhavoc/teller/internal/common/tui"
+	"github.com/hashhavoc/teller/pkg/keystore"
+	"github.com/hashhavoc/teller/pkg/stacks/keys"
+	"github.com/urfave/cli/v2"
+)
+
+func createKeysCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "keys",
+		Usage: "manage the encrypted keystore used to sign transactions",
+		Subcommands: []*cli.Command{
+			createKeysImportCommand(props),
+			createKeysExportCommand(props),
+			createKeysListCommand(props),
+			createKeysDeleteCommand(props),
+		},
+	}
+}
+
+func kdfFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "kdf",
+		Usage: "key derivation function protecting the key (scrypt or argon2id)",
+		Value: keystore.KDFScrypt,
+	}
+}
+
+func createKeysImportCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "import",
+		Usage: "encrypt a hex private key into the keystore, read from a hidden prompt or stdin",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "name",
+				Usage:    "name of the key in the keystore",
+				Required: true,
+			},
+			kdfFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			network, err := signing.Network(c, props)
+			if err != nil {
+				return err
+			}
+			ks := keystore.New(props.Config.KeystoreDir())
+			name := c.String("name")
+			if _, err := ks.Load(name); !errors.Is(err, keystore.ErrNotFound) {
+				if err == nil {
+					err = keystore.ErrExists
+				}
+				return fmt.Errorf("%s: %w", name, err)
+			}
+
+			secret, err := readPrivateKey()
+			if err != nil {
+				return err
+			}
+			key, err := keys.ParsePrivateKey(secret)
+			if err != nil {
+				return err
+			}
+			passphrase, err := common.ReadPassphrase("New passphrase: ", true)
+			if err != nil {
+				return err
+			}
+			address, err := saveKey(ks, name, key, network.SingleSigVersion, passphrase, c.String("kdf"))
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Imported %s as %s\n", address, name)
+			return nil
+		},
+	}
+}
+
+// readPrivateKey reads a hex key without echoing it when stdin is a
+// terminal, or the first line of stdin otherwise.
+func readPrivateKey() (string, error) {
+	if term.IsTerminal(os.Stdin.Fd()) {
+		secret, err := common.ReadSecret("Private key: ")
+		return string(secret), err
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("error reading private key: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// saveKey encrypts key into the keystore under name and returns its address
+// with the given version.
+func saveKey(ks *keystore.Keystore, name string, key *keys.PrivateKey, version byte, passphrase []byte, kdf string) (string, error) {
+	address, err := key.Address(version)
+	if err != nil {
+		return "", err
+	}
+	stored, err := keystore.Encrypt(name, address, []byte(key.Hex()), passphrase, kdf)
+	if err != nil {
+		return "", err
+	}
+	if err := ks.Save(stored); err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return address, nil
+}
+
+// saveAddresses encrypts the private keys of addresses into the keystore
+// with a single passphrase, recording the address of networkVersion. Several
+// keys are named name-0, name-1 and so on.
+func saveAddresses(props *props.AppProps, name string, addresses []Address, networkVersion int, kdf string) error {
+	ks := keystore.New(props.Config.KeystoreDir())
+	passphrase, err := common.ReadPassphrase("New passphrase: ", true)
+	if err != nil {
+		return err
+	}
+	for i, addr := range addresses {
+		key, err := keys.ParsePrivateKey(addr.PrivateKey)
+		if err != nil {
+			return err
+		}
+		keyName := name
+		if len(addresses) > 1 {
+			keyName = fmt.Sprintf("%s-%d", name, i)
+		}
+		if _, err := saveKey(ks, keyName, key, byte(networkVersion), passphrase, kdf); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Saved %s as %s\n", addr.Address, keyName)
+	}
+	return nil
+}
+
+func createKeysExportCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "export",
+		Usage: "decrypt a key from the keystore and print it as hex",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "name",
+				Usage:    "name of the key in the keystore",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:  "public",
+				Usage: "print the public key instead, as used to derive multisig addresses",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			key, err := signing.Unlock(props, c.String("name"))
+			if err != nil {
+				return err
+			}
+			if c.Bool("public") {
+				fmt.Println(hex.EncodeToString(key.PublicKey()))
+				return nil
+			}
+			fmt.Println(key.Hex())
+			return nil
+		},
+	}
+}
+
+func createKeysListCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "list",
+		Usage: "list the keys in the keystore",
+		Action: func(c *cli.Context) error {
+			stored, skipped, err := keystore.New(props.Config.KeystoreDir()).List()
+			if err != nil {
+				return err
+			}
+			for _, err := range skipped {
+				fmt.Fprintf(os.Stderr, "Skipping unreadable keystore file: %v\n", err)
+			}
+			if len(stored) == 0 {
+				fmt.Println("The keystore is empty")
+				return nil
+			}
+
+			var rows [][]string
+			for _, k := range stored {
+				rows = append(rows, []string{k.Name, k.Address, k.Crypto.KDF, k.CreatedAt.Local().Format("2006-01-02 15:04:05")})
+			}
+			return tui.PrintRows(c, []tui.Column{{Title: "Name"}, {Title: "Address", Kind: tui.Address}, {Title: "KDF"}, {Title: "Created", Kind: tui.Timestamp}}, rows)
+		},
+	}
+}
+
+func createKeysDeleteCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "delete",
+		Usage: "remove a key from the keystore",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "name",
+				Usage:    "name of the key in the keystore",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "delete without asking for confirmation",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			ks := keystore.New(props.Config.KeystoreDir())
+			name := c.String("name")
+			stored, err := ks.Load(name)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			if !c.Bool("force") {
+				fmt.Fprintf(os.Stderr, "Delete %s (%s)? The key cannot be recovered without a backup. [y/N]: ", name, stored.Address)
+				answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+				if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+					return errors.New("aborted")
+				}
+			}
+			return ks.Delete(name)
+		},
+	}
+}
@@ -0,0 +1,236 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hashhavoc/teller/internal/commands/props"
+	"github.com/hashhavoc/teller/internal/commands/signing"
+	"github.com/hashhavoc/teller/pkg/stacks/transaction"
+	"github.com/urfave/cli/v2"
+)
+
+func createMultisigCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "multisig",
+		Usage: "derive multisig addresses and collect signatures for multisig transactions",
+		Subcommands: []*cli.Command{
+			createMultisigAddressCommand(props),
+			createMultisigSendCommand(props),
+			createMultisigSignCommand(props),
+			createMultisigCombineCommand(props),
+		},
+	}
+}
+
+func multisigKeyFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:     "pubkey",
+			Usage:    "hex public key of a signer, repeat for each key in order",
+			Required: true,
+		},
+		&cli.IntFlag{
+			Name:     "required",
+			Aliases:  []string{"m"},
+			Usage:    "number of signatures required to spend",
+			Required: true,
+		},
+	}
+}
+
+func multisigPublicKeys(c *cli.Context) ([][]byte, error) {
+	var publicKeys [][]byte
+	for _, s := range c.StringSlice("pubkey") {
+		pk, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %q: %w", s, err)
+		}
+		publicKeys = append(publicKeys, pk)
+	}
+	return publicKeys, nil
+}
+
+func createMultisigAddressCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "address",
+		Usage: "derive the address of an M-of-N multisig from its public keys",
+		Flags: append(multisigKeyFlags(),
+			&cli.StringFlag{
+				Name:    "network",
+				Aliases: []string{"n"},
+				Usage:   "network of the address: mainnet, testnet or devnet (default: the network of the profile)",
+			},
+		),
+		Action: func(c *cli.Context) error {
+			network, err := signing.Network(c, props)
+			if err != nil {
+				return err
+			}
+			publicKeys, err := multisigPublicKeys(c)
+			if err != nil {
+				return err
+			}
+			address, err := transaction.MultiSigAddress(network, c.Int("required"), publicKeys)
+			if err != nil {
+				return err
+			}
+			fmt.Println(address)
+			return nil
+		},
+	}
+}
+
+func createMultisigSendCommand(props *props.AppProps) *cli.Command {
+	flags := append(multisigKeyFlags(),
+		&cli.StringFlag{
+			Name:     "to",
+			Usage:    "recipient principal",
+			Required: true,
+			Action:   props.CheckPrincipal,
+		},
+		&cli.Uint64Flag{
+			Name:     "amount",
+			Aliases:  []string{"a"},
+			Usage:    "amount to send in micro-STX",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "memo",
+			Usage: "memo of up to 34 bytes",
+		},
+		&cli.BoolFlag{
+			Name:  "sequential",
+			Usage: "use the legacy sequential hash mode, where keys must sign in the order they are listed",
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "print the decoded transaction instead of its hex encoding",
+		},
+	)
+	return &cli.Command{
+		Name:  "send",
+		Usage: "build an unsigned STX transfer from a multisig address, to be signed with 'multisig sign'",
+		Flags: append(flags, signing.BuildFlags()...),
+		Action: func(c *cli.Context) error {
+			network, err := signing.Network(c, props)
+			if err != nil {
+				return err
+			}
+			publicKeys, err := multisigPublicKeys(c)
+			if err != nil {
+				return err
+			}
+			origin, err := transaction.NewMultiSigSpendingCondition(c.Int("required"), publicKeys, c.Bool("sequential"), 0, 0)
+			if err != nil {
+				return err
+			}
+			payload, err := transaction.NewTokenTransfer(c.String("to"), c.Uint64("amount"), c.String("memo"))
+			if err != nil {
+				return err
+			}
+
+			tx := transaction.New(network, origin, payload)
+			if err := signing.Prepare(c, props, tx); err != nil {
+				return err
+			}
+			_, err = signing.Submit(c, props, tx)
+			return err
+		},
+	}
+}
+
+func txFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "tx",
+		Usage: "hex encoded transaction (default: read from stdin)",
+	}
+}
+
+// readTransaction decodes the hex transaction given as s, or read from
+// stdin when s is empty.
+func readTransaction(s string) (*transaction.Transaction, error) {
+	if s == "" {
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("error reading transaction: %w", err)
+		}
+		s = string(b)
+	}
+	return transaction.DecodeHex(s)
+}
+
+func createMultisigSignCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "sign",
+		Usage: "add the signature of a keystore key to a multisig transaction",
+		Flags: append([]cli.Flag{
+			txFlag(),
+			&cli.StringFlag{
+				Name:    "from",
+				Usage:   "name of the keystore key to sign with (default: the only key in the keystore)",
+				EnvVars: []string{"TELLER_KEY"},
+			},
+		}, signing.SubmitFlags()...),
+		Action: func(c *cli.Context) error {
+			tx, err := readTransaction(c.String("tx"))
+			if err != nil {
+				return err
+			}
+			key, err := signing.Key(c, props)
+			if err != nil {
+				return err
+			}
+			if err := tx.SignMultiSig(key); err != nil {
+				return err
+			}
+			if c.Bool("broadcast") {
+				if err := tx.VerifyOrigin(); err != nil {
+					return fmt.Errorf("transaction is not ready to broadcast: %w", err)
+				}
+			}
+			_, err = signing.Submit(c, props, tx)
+			return err
+		},
+	}
+}
+
+func createMultisigCombineCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "combine",
+		Usage: "merge the signatures of copies of a multisig transaction signed separately",
+		Flags: append([]cli.Flag{
+			&cli.StringSliceFlag{
+				Name:     "tx",
+				Usage:    "hex encoded partially signed transaction, repeat for each copy",
+				Required: true,
+			},
+		}, signing.SubmitFlags()...),
+		Action: func(c *cli.Context) error {
+			var txs []*transaction.Transaction
+			for i, s := range c.StringSlice("tx") {
+				tx, err := transaction.DecodeHex(s)
+				if err != nil {
+					return fmt.Errorf("transaction %d: %w", i+1, err)
+				}
+				txs = append(txs, tx)
+			}
+			tx, err := transaction.CombineMultiSig(txs...)
+			if err != nil {
+				return err
+			}
+			if c.Bool("broadcast") {
+				if err := tx.VerifyOrigin(); err != nil {
+					return fmt.Errorf("transaction is not ready to broadcast: %w", err)
+				}
+			} else if tx.Auth.Origin.SignatureCount() < int(tx.Auth.Origin.SignaturesRequired) {
+				props.Logger.Warn().Msg("transaction still needs more signatures")
+			}
+			_, err = signing.Submit(c, props, tx)
+			return err
+		},
+	}
+}
@@ -0,0 +1,344 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/hashhavoc/teller/internal/commands/props"
+	"github.com/hashhavoc/teller/internal/common"
+	"github.com/hashhavoc/teller/internal/common/tui"
+	"github.com/hashhavoc/teller/internal/config"
+	"github.com/hashhavoc/teller/pkg/clarity"
+	"github.com/urfave/cli/v2"
+)
+
+// stxDecimals is the number of decimals of STX, balances are in micro-STX.
+const stxDecimals = 6
+
+// alexDeployers deploy the tokens ALEX prices by contract name.
+var alexDeployers = []string{
+	"SP3K8BC0PPEVCV7NZ6QSRWPQ2JE9E5B6N3PA0KBR9",
+	"SP102V8P0F7JX67ARQ77WEA3D3CFB5XW39REDT0AM",
+}
+
+// quote is what a price source knows of a token. Sources without price
+// changes leave them nil.
+type quote struct {
+	price    float64
+	change1D *float64
+	change7D *float64
+	decimals *int
+}
+
+// asset is STX or a fungible token held by the configured wallets.
+type asset struct {
+	name string
+	// contractID is empty for STX.
+	contractID string
+	balance    *big.Int
+	decimals   int
+	// priced is set when a source has a price, source names it.
+	priced   bool
+	source   string
+	price    float64
+	change1D *float64
+	change7D *float64
+}
+
+// value returns the balance of a in USD.
+func (a *asset) value() float64 {
+	amount := new(big.Rat).SetFrac(a.balance, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(a.decimals)), nil))
+	f, _ := amount.Float64()
+	return f * a.price
+}
+
+// priceSources load the quotes of the tokens they price keyed by contract
+// ID, with STX under the empty ID.
+var priceSources = map[string]func(ctx context.Context, props *props.AppProps) (map[string]quote, error){
+	"alex":     alexQuotes,
+	"stxtools": stxToolsQuotes,
+}
+
+func alexQuotes(ctx context.Context, props *props.AppProps) (map[string]quote, error) {
+	resp, err := props.AlexClient.FetchLatestPricesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	quotes := make(map[string]quote)
+	for _, p := range resp.Data.LaplaceCurrentTokenPrice {
+		q := quote{price: p.AvgPriceUSD}
+		switch {
+		case p.Token == "token-wstx":
+			quotes[""] = q
+		case strings.Contains(p.Token, "."):
+			quotes[p.Token] = q
+		default:
+			// ALEX names its own tokens by contract name.
+			for _, deployer := range alexDeployers {
+				quotes[deployer+"."+p.Token] = q
+			}
+		}
+	}
+	return quotes, nil
+}
+
+func stxToolsQuotes(ctx context.Context, props *props.AppProps) (map[string]quote, error) {
+	tokens, err := props.StxToolsClient.GetAllTokensContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	quotes := make(map[string]quote)
+	for _, t := range tokens {
+		q := quote{
+			price:    t.Metrics.PriceUSD,
+			change1D: &t.Metrics.PriceChange1D,
+			change7D: &t.Metrics.PriceChange7D,
+			decimals: &t.Decimals,
+		}
+		if strings.EqualFold(t.ContractID, "stx") {
+			quotes[""] = q
+		} else {
+			quotes[t.ContractID] = q
+		}
+	}
+	return quotes, nil
+}
+
+func createPortfolioCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "portfolio",
+		Usage: "value the STX and fungible tokens of the configured wallets in USD",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "price-source",
+				Usage:   fmt.Sprintf("Price sources to try in order, of %s (default: the portfolio price_sources of the config, or all of them)", strings.Join(config.PriceSourceNames, ", ")),
+				Aliases: []string{"s"},
+			},
+		},
+		Action: func(c *cli.Context) error {
+			sources := props.Config.Portfolio.Sources()
+			if c.IsSet("price-source") {
+				sources = c.StringSlice("price-source")
+			}
+			for _, name := range sources {
+				if err := config.ValidPriceSource(name); err != nil {
+					return err
+				}
+			}
+
+			assets, err := portfolioAssets(c.Context, props)
+			if err != nil {
+				return err
+			}
+
+			var quotes []map[string]quote
+			for _, name := range sources {
+				q, err := priceSources[name](c.Context, props)
+				if err != nil {
+					// The next sources may still price the tokens.
+					props.Logger.Warn().Err(err).Str("source", name).Msg("Failed to fetch prices")
+				}
+				quotes = append(quotes, q)
+			}
+
+			for _, a := range assets {
+				if a.contractID != "" {
+					decimals, err := tokenDecimals(c.Context, props, a.contractID, quotes)
+					if err != nil {
+						props.Logger.Warn().Err(err).Str("contract", a.contractID).Msg("Failed to get decimals, leaving the token unpriced")
+						continue
+					}
+					a.decimals = decimals
+				}
+				priceAsset(a, sources, quotes)
+			}
+
+			view := tui.View{
+				Columns: portfolioColumns,
+				Rows:    portfolioRows(assets),
+				Export:  "portfolio.csv",
+				Actions: portfolioActions(props),
+			}
+			total := portfolioTotal(assets)
+			view.Title = "Portfolio of the configured wallets: " + total
+
+			if !common.Interactive(c) {
+				if err := tui.PrintRows(c, view.Columns, view.Rows); err != nil {
+					return err
+				}
+				fmt.Fprintln(os.Stderr, "Total:", total)
+				return nil
+			}
+
+			if err := tui.Run(c.Context, view); err != nil {
+				props.Logger.Fatal().Err(err).Msg("Failed to run program")
+			}
+
+			return nil
+		},
+	}
+}
+
+// portfolioAssets sums the STX and fungible token balances of the
+// configured wallets.
+func portfolioAssets(ctx context.Context, props *props.AppProps) ([]*asset, error) {
+	stx := &asset{name: "STX", balance: new(big.Int), decimals: stxDecimals}
+	assets := []*asset{stx}
+	tokens := make(map[string]*asset)
+
+	for _, w := range props.Config.Active().Wallets {
+		fmt.Fprintln(os.Stderr, "Wallet:", w)
+		resp, err := props.HeroClient.GetAccountBalanceContext(ctx, w, 0)
+		if err != nil {
+			return nil, err
+		}
+		if err := addBalance(stx.balance, resp.Stx.Balance); err != nil {
+			return nil, fmt.Errorf("error parsing STX balance of %s: %w", w, err)
+		}
+		for k, v := range resp.FungibleTokens {
+			a, ok := tokens[k]
+			if !ok {
+				contractID, name, _ := strings.Cut(k, "::")
+				a = &asset{name: name, contractID: contractID, balance: new(big.Int)}
+				tokens[k] = a
+				assets = append(assets, a)
+			}
+			if err := addBalance(a.balance, v.Balance); err != nil {
+				return nil, fmt.Errorf("error parsing %s balance of %s: %w", k, w, err)
+			}
+		}
+	}
+
+	// Tokens the wallets no longer hold are not part of the portfolio.
+	held := assets[:0]
+	for _, a := range assets {
+		if a.contractID == "" || a.balance.Sign() > 0 {
+			held = append(held, a)
+		}
+	}
+	return held, nil
+}
+
+func addBalance(sum *big.Int, balance string) error {
+	x, ok := new(big.Int).SetString(balance, 10)
+	if !ok {
+		return fmt.Errorf("invalid balance %q", balance)
+	}
+	sum.Add(sum, x)
+	return nil
+}
+
+// tokenDecimals returns the decimals of a token from the first source that
+// knows them, or from its get-decimals function.
+func tokenDecimals(ctx context.Context, props *props.AppProps, contractID string, quotes []map[string]quote) (int, error) {
+	for _, q := range quotes {
+		if quote, ok := q[contractID]; ok && quote.decimals != nil {
+			return *quote.decimals, nil
+		}
+	}
+	resp, err := props.HeroClient.GetContractReadOnlyContext(ctx, contractID, "get-decimals", "", []string{})
+	if err != nil {
+		return 0, err
+	}
+	decimals, err := strconv.Atoi(clarity.Display(clarity.Unwrap(resp)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse decimals: %w", err)
+	}
+	return decimals, nil
+}
+
+// priceAsset prices a from the first source with a price for it. The price
+// changes come from the first source that has them, as ALEX has none.
+func priceAsset(a *asset, sources []string, quotes []map[string]quote) {
+	for i, q := range quotes {
+		quote, ok := q[a.contractID]
+		if !ok || quote.price <= 0 {
+			continue
+		}
+		if !a.priced {
+			a.priced = true
+			a.source = sources[i]
+			a.price = quote.price
+		}
+		if a.change1D == nil && quote.change1D != nil {
+			a.change1D, a.change7D = quote.change1D, quote.change7D
+		}
+	}
+}
+
+// portfolioRows returns the rows of the assets, most valuable first.
+func portfolioRows(assets []*asset) []table.Row {
+	var total float64
+	for _, a := range assets {
+		if a.priced {
+			total += a.value()
+		}
+	}
+	sort.SliceStable(assets, func(i, j int) bool {
+		if assets[i].priced != assets[j].priced {
+			return assets[i].priced
+		}
+		return assets[i].value() > assets[j].value()
+	})
+
+	rows := make([]table.Row, 0, len(assets))
+	for _, a := range assets {
+		row := table.Row{a.name, a.contractID, common.InsertDecimal(a.balance.String(), a.decimals), "", "", "", "", "", ""}
+		if a.priced {
+			row[3] = strconv.FormatFloat(a.price, 'f', -1, 64)
+			row[4] = fmt.Sprintf("%.2f", a.value())
+			row[5] = formatChange(a.change1D)
+			row[6] = formatChange(a.change7D)
+			if total > 0 {
+				row[7] = fmt.Sprintf("%.2f", a.value()/total*100)
+			}
+			row[8] = a.source
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func formatChange(change *float64) string {
+	if change == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.2f", *change)
+}
+
+// portfolioTotal describes the USD value of the assets and its 24h and 7d
+// change, over the assets with a known change.
+func portfolioTotal(assets []*asset) string {
+	var total float64
+	for _, a := range assets {
+		if a.priced {
+			total += a.value()
+		}
+	}
+	s := fmt.Sprintf("$%.2f", total)
+	for _, period := range []struct {
+		name   string
+		change func(a *asset) *float64
+	}{
+		{"24h", func(a *asset) *float64 { return a.change1D }},
+		{"7d", func(a *asset) *float64 { return a.change7D }},
+	} {
+		var now, before float64
+		for _, a := range assets {
+			if change := period.change(a); a.priced && change != nil && *change > -100 {
+				now += a.value()
+				before += a.value() / (1 + *change/100)
+			}
+		}
+		if before > 0 {
+			s += fmt.Sprintf(", %s %+.2f%%", period.name, (now-before)/before*100)
+		}
+	}
+	return s
+}
@@ -0,0 +1,54 @@
+package wallet
+
+import (
+	"github.com/hashhavoc/teller/internal/commands/props"
+	"github.com/hashhavoc/teller/internal/commands/signing"
+	"github.com/hashhavoc/teller/pkg/stacks/transaction"
+	"github.com/urfave/cli/v2"
+)
+
+func createSendCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "send",
+		Usage: "build, sign and optionally broadcast a STX transfer",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:     "to",
+				Usage:    "recipient principal",
+				Required: true,
+				Action:   props.CheckPrincipal,
+			},
+			&cli.Uint64Flag{
+				Name:     "amount",
+				Aliases:  []string{"a"},
+				Usage:    "amount to send in micro-STX",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "memo",
+				Usage: "memo of up to 34 bytes",
+			},
+		}, signing.Flags()...),
+		Action: func(c *cli.Context) error {
+			network, err := signing.Network(c, props)
+			if err != nil {
+				return err
+			}
+			key, err := signing.Key(c, props)
+			if err != nil {
+				return err
+			}
+			payload, err := transaction.NewTokenTransfer(c.String("to"), c.Uint64("amount"), c.String("memo"))
+			if err != nil {
+				return err
+			}
+
+			tx := transaction.New(network, transaction.NewSingleSigSpendingCondition(key.PublicKey(), 0, 0), payload)
+			if err := signing.Sign(c, props, tx, key); err != nil {
+				return err
+			}
+			_, err = signing.Submit(c, props, tx)
+			return err
+		},
+	}
+}
@@ -0,0 +1,76 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// snapshot is the balances of a wallet at a block height. Balances at a
+// confirmed height do not change, so snapshots are kept and only missing
+// heights are fetched again.
+type snapshot struct {
+	Height int    `json:"height"`
+	Hash   string `json:"hash"`
+	Time   string `json:"time"`
+	STX    string `json:"stx"`
+	// FungibleTokens maps contract::asset to its balance.
+	FungibleTokens map[string]string `json:"fungible_tokens,omitempty"`
+}
+
+// snapshotStore keeps the snapshots of each wallet of a network in a JSON
+// file named after the wallet.
+type snapshotStore struct {
+	dir string
+}
+
+func newSnapshotStore(historyDir, network string) snapshotStore {
+	return snapshotStore{dir: filepath.Join(historyDir, network)}
+}
+
+func (s snapshotStore) path(wallet string) string {
+	return filepath.Join(s.dir, wallet+".json")
+}
+
+// load returns the snapshots of wallet by height, none if it has not been
+// sampled yet.
+func (s snapshotStore) load(wallet string) (map[int]snapshot, error) {
+	snapshots := make(map[int]snapshot)
+	data, err := os.ReadFile(s.path(wallet))
+	if errors.Is(err, os.ErrNotExist) {
+		return snapshots, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []snapshot
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	for _, snap := range list {
+		snapshots[snap.Height] = snap
+	}
+	return snapshots, nil
+}
+
+// save writes the snapshots of wallet up to the height confirmed in order
+// of height, leaving out those that may still change.
+func (s snapshotStore) save(wallet string, snapshots map[int]snapshot, confirmed int) error {
+	list := make([]snapshot, 0, len(snapshots))
+	for _, snap := range snapshots {
+		if snap.Height <= confirmed {
+			list = append(list, snap)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Height < list[j].Height })
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path(wallet), data, 0644)
+}
@@ -1,91 +1,109 @@
 package wallet
 
 import (
-	"sort"
-	"strconv"
+	"context"
 
 	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/hashhavoc/teller/internal/common"
-	"github.com/hashhavoc/teller/pkg/api/hiro"
+	"github.com/hashhavoc/teller/internal/commands/props"
+	"github.com/hashhavoc/teller/internal/common/tui"
+	"github.com/hashhavoc/teller/pkg/utils"
 )
 
-type tableModel struct {
-	table            table.Model
-	client           *hiro.APIClient
-	sortAscending    bool
-	lastSortedColumn int
+var balanceColumns = []tui.Column{
+	{Title: "Name"},
+	{Title: "Type"},
+	{Title: "Balance", Kind: tui.Integer},
+	{Title: "Contract ID", Kind: tui.Address},
+	{Title: "Display Name"},
 }
 
-func (m tableModel) Init() tea.Cmd {
-	return nil
+var portfolioColumns = []tui.Column{
+	{Title: "Asset"},
+	{Title: "Contract ID", Kind: tui.Address},
+	{Title: "Balance", Kind: tui.Decimal},
+	{Title: "Price USD", Kind: tui.Decimal},
+	{Title: "Value USD", Kind: tui.Decimal},
+	{Title: "24h %", Kind: tui.Decimal},
+	{Title: "7d %", Kind: tui.Decimal},
+	{Title: "Allocation %", Kind: tui.Decimal},
+	{Title: "Source"},
 }
 
-func (m tableModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
-	switch msg := msg.(type) {
-	case tea.WindowSizeMsg:
-		m.table.SetHeight(msg.Height - common.TableHeightPadding)
-		return m, nil
-	case tea.KeyMsg:
-		switch msg.String() {
-		case "esc":
-			if m.table.Focused() {
-				m.table.Blur()
-			} else {
-				m.table.Focus()
-			}
-		case "q", "ctrl+c":
-			return m, tea.Quit
-		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
-			columnIndex := int(msg.Runes[0] - '1') // Convert rune to int and adjust for 0-based indexing
-			currentRows := m.table.Rows()
-			columnCount := len(currentRows[0]) // Assuming all rows have the same number of columns
-
-			if columnIndex < columnCount {
-				// Check if the same column is being sorted again
-				if m.lastSortedColumn == columnIndex {
-					// Toggle the sorting direction
-					m.sortAscending = !m.sortAscending
-				} else {
-					// New column, start with ascending order
-					m.sortAscending = true
-					m.lastSortedColumn = columnIndex
-				}
+var historyColumns = []tui.Column{
+	{Title: "Asset"},
+	{Title: "Contract ID", Kind: tui.Address},
+	{Title: "Start", Kind: tui.Decimal},
+	{Title: "End", Kind: tui.Decimal},
+	{Title: "Change", Kind: tui.Decimal},
+	{Title: "Min", Kind: tui.Decimal},
+	{Title: "Max", Kind: tui.Decimal},
+	{Title: "Trend"},
+}
 
-				// Sort rows based on the dynamically chosen column and current sorting direction
-				sort.SliceStable(currentRows, func(i, j int) bool {
-					// Attempt to parse both values as int64
-					valI, errI := strconv.ParseFloat(currentRows[i][columnIndex], 64)
-					valJ, errJ := strconv.ParseFloat(currentRows[j][columnIndex], 64)
+var seriesColumns = []tui.Column{
+	{Title: "Height", Kind: tui.Integer},
+	{Title: "Time", Kind: tui.Timestamp},
+	{Title: "Balance", Kind: tui.Decimal},
+	{Title: "Change", Kind: tui.Decimal},
+}
 
-					// If both values are successfully parsed as int64, compare them as int64
-					if errI == nil && errJ == nil {
-						if m.sortAscending {
-							return valI < valJ
-						} else {
-							return valI > valJ
-						}
-					}
+// exportColumns are the columns of an exported history, one row per height
+// and asset.
+var exportColumns = []tui.Column{
+	{Title: "Height", Kind: tui.Integer},
+	{Title: "Time", Kind: tui.Timestamp},
+	{Title: "Asset"},
+	{Title: "Contract ID", Kind: tui.Address},
+	{Title: "Balance", Kind: tui.Decimal},
+}
 
-					// If parsing into int64 fails for either value, compare them as strings
-					if m.sortAscending {
-						return currentRows[i][columnIndex] < currentRows[j][columnIndex]
-					} else {
-						return currentRows[i][columnIndex] > currentRows[j][columnIndex]
-					}
-				})
+var addressColumns = []tui.Column{
+	{Title: "Address", Kind: tui.Address},
+	{Title: "Balance", Kind: tui.Integer},
+}
 
-				// Set the sorted rows back to the table
-				m.table.SetRows(currentRows)
-			}
-		}
+func balanceActions(props *props.AppProps) []tui.Action {
+	return []tui.Action{
+		{
+			Key:  "enter",
+			Help: "open the token contract in the explorer",
+			Run: func(ctx context.Context, row table.Row) tea.Cmd {
+				// STX has no contract.
+				if row[3] != "" {
+					utils.OpenBrowser(props.Config.ExplorerURL("txid/" + row[3]))
+				}
+				return nil
+			},
+		},
+	}
+}
 
+func portfolioActions(props *props.AppProps) []tui.Action {
+	return []tui.Action{
+		{
+			Key:  "enter",
+			Help: "open the token contract in the explorer",
+			Run: func(ctx context.Context, row table.Row) tea.Cmd {
+				// STX has no contract.
+				if row[1] != "" {
+					utils.OpenBrowser(props.Config.ExplorerURL("txid/" + row[1]))
+				}
+				return nil
+			},
+		},
 	}
-	m.table, cmd = m.table.Update(msg)
-	return m, cmd
 }
 
-func (m tableModel) View() string {
-	return common.BaseTableStyle.Render(m.table.View())
+func addressActions(props *props.AppProps) []tui.Action {
+	return []tui.Action{
+		{
+			Key:  "enter",
+			Help: "open the address in the explorer",
+			Run: func(ctx context.Context, row table.Row) tea.Cmd {
+				utils.OpenBrowser(props.Config.ExplorerURL("address/" + row[0]))
+				return nil
+			},
+		},
+	}
 }
@@ -1,19 +1,24 @@
 package wallet
 
 import (
-	"encoding/hex"
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/table"
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/hashhavoc/teller/internal/commands/props"
 	"github.com/hashhavoc/teller/internal/common"
+	"github.com/hashhavoc/teller/internal/common/tui"
 	"github.com/hashhavoc/teller/pkg/api/hiro"
+	"github.com/hashhavoc/teller/pkg/bip39"
+	"github.com/hashhavoc/teller/pkg/clarity"
+	"github.com/hashhavoc/teller/pkg/stacks/address"
+	"github.com/hashhavoc/teller/pkg/stacks/keys"
 	"github.com/hashhavoc/teller/pkg/utils"
-	"github.com/okx/go-wallet-sdk/coins/stacks"
 	"github.com/urfave/cli/v2"
 )
 
@@ -24,10 +29,17 @@ func CreateWalletCommand(props *props.AppProps) *cli.Command {
 		Subcommands: []*cli.Command{
 			createBalanceCommand(props),
 			createBalancesCommand(props),
+			createPortfolioCommand(props),
+			createHistoryCommand(props),
 			createAddWalletCommand(props),
 			createRemoveWalletCommand(props),
 			createGenerateWalletCommand(props),
+			createRecoverWalletCommand(props),
 			createBalancesByAddressCommand(props),
+			createSendCommand(props),
+			createKeysCommand(props),
+			createMultisigCommand(props),
+			createConvertCommand(props),
 		},
 	}
 }
@@ -41,8 +53,7 @@ func createGenerateWalletCommand(props *props.AppProps) *cli.Command {
 			&cli.StringFlag{
 				Name:    "networkType",
 				Aliases: []string{"n"},
-				Usage:   "Specify the network type",
-				Value:   "mainnet",
+				Usage:   "Specify the network type: mainnet, testnet or devnet (default: the network of the profile)",
 			},
 			&cli.IntFlag{
 				Name:     "amount",
@@ -62,83 +73,237 @@ func createGenerateWalletCommand(props *props.AppProps) *cli.Command {
 				Usage:   "Specify the csv file location to output contents to (default: stdout)",
 				Value:   "",
 			},
+			&cli.BoolFlag{
+				Name:    "mnemonic",
+				Aliases: []string{"m"},
+				Usage:   "Generate a BIP39 phrase and derive the addresses from it along m/44'/5757'/0'/0/i",
+			},
+			&cli.IntFlag{
+				Name:  "words",
+				Usage: "Number of words in the generated phrase (12 or 24)",
+				Value: 24,
+			},
+			&cli.StringFlag{
+				Name:  "passphrase",
+				Usage: "Optional BIP39 passphrase used with the phrase",
+			},
+			&cli.StringFlag{
+				Name:  "save",
+				Usage: "Encrypt the private keys into the keystore under this name (suffixed with -i when deriving several) instead of printing them",
+			},
+			kdfFlag(),
 		},
 		Action: func(c *cli.Context) error {
-			networkType := c.String("networkType")
-			outputFile := c.String("file")
+			networkVersion, err := singleSigVersion(c, props)
+			if err != nil {
+				return err
+			}
 			amount := c.Int("amount")
-			private := c.Bool("private")
-
-			var networkVersion int
-			switch networkType {
-			case "mainnet":
-				networkVersion = stacks.MainnetSingleSig
-			case "testnet":
-				networkVersion = 26 // Testnet single sig version
-			default:
-				props.Logger.Error().Msg("Invalid network type. Please specify either 'mainnet' or 'testnet'.")
-				return nil
+			if c.String("save") != "" && c.Bool("private") {
+				return errors.New("--save and --private are mutually exclusive")
 			}
 
 			var addresses []Address
-			for i := 0; i < amount; i++ {
-				privKey, err := utils.MakeRandomPrivKey()
+			if c.Bool("mnemonic") {
+				words := c.Int("words")
+				if words != 12 && words != 24 {
+					return fmt.Errorf("invalid word count %d, expected 12 or 24", words)
+				}
+				entropy, err := bip39.NewEntropy(words)
 				if err != nil {
-					props.Logger.Err(err).Msg("Error generating private key")
-					continue
+					return err
 				}
-
-				encoded := hex.EncodeToString(privKey.PrivateKey.Serialize())
-
-				addr, err := stacks.NewAddress(encoded, networkVersion)
+				phrase, err := bip39.NewMnemonic(entropy)
+				if err != nil {
+					return err
+				}
+				addresses, err = deriveAddresses(phrase, c.String("passphrase"), networkVersion, amount)
 				if err != nil {
-					props.Logger.Err(err).Msg("Error generating address")
-					continue
+					return err
+				}
+				// The phrase goes to stderr so that stdout and the csv file
+				// keep their address format.
+				fmt.Fprintf(os.Stderr, "Mnemonic: %s\n", phrase)
+				fmt.Fprintln(os.Stderr, "Write down the phrase and keep it safe, it is the only way to recover these addresses.")
+			} else {
+				for i := 0; i < amount; i++ {
+					privKey, err := utils.MakeRandomPrivKey()
+					if err != nil {
+						props.Logger.Err(err).Msg("Error generating private key")
+						continue
+					}
+
+					key := keys.NewPrivateKey(privKey.PrivateKey)
+					addr, err := key.Address(byte(networkVersion))
+					if err != nil {
+						props.Logger.Err(err).Msg("Error generating address")
+						continue
+					}
+					addresses = append(addresses, Address{PrivateKey: key.Hex(), Address: addr})
 				}
-				addresses = append(addresses, Address{PrivateKey: encoded, Address: addr})
 			}
 
-			var file *os.File
-			var err error
-			if outputFile != "" {
-				file, err = os.Create(outputFile)
-				if err != nil {
-					props.Logger.Err(err).Msg("Failed to create file")
+			if c.String("save") != "" {
+				if err := saveAddresses(props, c.String("save"), addresses, networkVersion, c.String("kdf")); err != nil {
+					return err
 				}
-				defer file.Close()
 			}
+			return writeAddresses(addresses, c.Bool("private"), c.String("file"))
+		},
+	}
+}
 
-			for _, addr := range addresses {
-				if private {
-					if file != nil {
-						// Write to file
-						_, err := file.WriteString(fmt.Sprintf("%s,%s\n", addr.Address, addr.PrivateKey))
-						if err != nil {
-							props.Logger.Err(err).Msg("Failed to write to file")
-						}
-					} else {
-						// Print to stdout
-						fmt.Printf("%s,%s\n", addr.Address, addr.PrivateKey)
-					}
-				} else {
-					if file != nil {
-						// Write to file
-						_, err := file.WriteString(fmt.Sprintf("%s\n", addr.Address))
-						if err != nil {
-							props.Logger.Err(err).Msg("Failed to write to file")
-						}
-					} else {
-						// Print to stdout
-						fmt.Println(addr.Address)
-					}
+func createRecoverWalletCommand(props *props.AppProps) *cli.Command {
+	return &cli.Command{
+		Name:  "recover",
+		Usage: "Regenerate the addresses of a BIP39 phrase, compatible with Leather and Xverse",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "mnemonic",
+				Aliases: []string{"m"},
+				Usage:   "BIP39 phrase to recover from (default: read from stdin)",
+				EnvVars: []string{"TELLER_MNEMONIC"},
+			},
+			&cli.StringFlag{
+				Name:  "passphrase",
+				Usage: "Optional BIP39 passphrase used with the phrase",
+			},
+			&cli.StringFlag{
+				Name:    "networkType",
+				Aliases: []string{"n"},
+				Usage:   "Specify the network type: mainnet, testnet or devnet (default: the network of the profile)",
+			},
+			&cli.IntFlag{
+				Name:    "amount",
+				Aliases: []string{"a"},
+				Usage:   "Number of accounts to derive",
+				Value:   1,
+			},
+			&cli.BoolFlag{
+				Name:    "private",
+				Aliases: []string{"p"},
+				Usage:   "Specify if you want to return a hex version of the private key",
+				Value:   false,
+			},
+			&cli.StringFlag{
+				Name:    "file",
+				Aliases: []string{"f"},
+				Usage:   "Specify the csv file location to output contents to (default: stdout)",
+				Value:   "",
+			},
+			&cli.StringFlag{
+				Name:  "save",
+				Usage: "Encrypt the private keys into the keystore under this name (suffixed with -i when deriving several) instead of printing them",
+			},
+			kdfFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			networkVersion, err := singleSigVersion(c, props)
+			if err != nil {
+				return err
+			}
+			if c.String("save") != "" && c.Bool("private") {
+				return errors.New("--save and --private are mutually exclusive")
+			}
+
+			phrase := c.String("mnemonic")
+			if phrase == "" {
+				fmt.Fprint(os.Stderr, "Mnemonic: ")
+				line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+				if err != nil && line == "" {
+					return fmt.Errorf("error reading mnemonic: %w", err)
 				}
+				phrase = line
+			}
+			if _, err := bip39.EntropyFromMnemonic(phrase); err != nil {
+				return err
 			}
 
-			return nil
+			addresses, err := deriveAddresses(phrase, c.String("passphrase"), networkVersion, c.Int("amount"))
+			if err != nil {
+				return err
+			}
+			if c.String("save") != "" {
+				if err := saveAddresses(props, c.String("save"), addresses, networkVersion, c.String("kdf")); err != nil {
+					return err
+				}
+			}
+			return writeAddresses(addresses, c.Bool("private"), c.String("file"))
 		},
 	}
 }
 
+// singleSigVersion returns the address version of the networkType flag,
+// or of the network of the profile.
+func singleSigVersion(c *cli.Context, props *props.AppProps) (int, error) {
+	name := props.Config.NetworkName()
+	if c.IsSet("networkType") {
+		name = c.String("networkType")
+	}
+	network, err := address.NetworkByName(name)
+	if err != nil {
+		return 0, err
+	}
+	return int(network.SingleSig()), nil
+}
+
+// deriveAddresses derives the first amount accounts of a phrase along the
+// Stacks derivation path.
+func deriveAddresses(phrase, passphrase string, networkVersion, amount int) ([]Address, error) {
+	seed := bip39.NewSeed(phrase, passphrase)
+	var addresses []Address
+	for i := 0; i < amount; i++ {
+		key, err := keys.DeriveStacksAccount(seed, uint32(i))
+		if err != nil {
+			return nil, fmt.Errorf("error deriving account %d: %w", i, err)
+		}
+		addr, err := key.Address(byte(networkVersion))
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, Address{PrivateKey: key.Hex(), Address: addr})
+	}
+	return addresses, nil
+}
+
+// writeAddresses prints the addresses, and optionally their private keys,
+// to stdout or a csv file.
+func writeAddresses(addresses []Address, private bool, outputFile string) error {
+	if outputFile == "" {
+		return printAddresses(os.Stdout, addresses, private)
+	}
+	file, err := os.OpenFile(outputFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("error creating %s: %w", outputFile, err)
+	}
+	// Private keys must not be readable by other users. OpenFile only sets
+	// the mode of new files, so an existing file is restricted too.
+	if private {
+		if err := file.Chmod(0600); err != nil {
+			file.Close()
+			return fmt.Errorf("error restricting the permissions of %s: %w", outputFile, err)
+		}
+	}
+	if err := printAddresses(file, addresses, private); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func printAddresses(w io.Writer, addresses []Address, private bool) error {
+	for _, addr := range addresses {
+		line := addr.Address
+		if private {
+			line += "," + addr.PrivateKey
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("error writing addresses: %w", err)
+		}
+	}
+	return nil
+}
+
 func createAddWalletCommand(props *props.AppProps) *cli.Command {
 	return &cli.Command{
 		Name:  "add",
@@ -149,6 +314,7 @@ func createAddWalletCommand(props *props.AppProps) *cli.Command {
 				Usage:    "Principal address",
 				Aliases:  []string{"p"},
 				Required: true,
+				Action:   props.CheckPrincipal,
 			},
 		},
 		Action: func(c *cli.Context) error {
@@ -198,6 +364,7 @@ func createBalanceCommand(props *props.AppProps) *cli.Command {
 				Usage:    "Principal address",
 				Aliases:  []string{"p"},
 				Required: true,
+				Action:   props.CheckPrincipal,
 			},
 		},
 		Action: func(c *cli.Context) error {
@@ -208,8 +375,8 @@ func createBalanceCommand(props *props.AppProps) *cli.Command {
 			nonFungibleTokenCounts := make(map[string]int64)
 
 			address := c.String("principal")
-			fmt.Println("Fetching balance for address:", address)
-			resp, err := props.HeroClient.GetAccountBalance(address, 0)
+			fmt.Fprintln(os.Stderr, "Fetching balance for address:", address)
+			resp, err := props.HeroClient.GetAccountBalanceContext(c.Context, address, 0)
 			if err != nil {
 				return err
 			}
@@ -220,20 +387,6 @@ func createBalanceCommand(props *props.AppProps) *cli.Command {
 				return fmt.Errorf("error parsing STX balance: %v", err)
 			}
 
-			// Prepare the table
-			headers := []table.Column{
-				{Title: "Name", Width: len("Name")},
-				{Title: "Type", Width: len("Type")},
-				{Title: "Balance", Width: len("Balance")},
-				{Title: "Contract ID", Width: len("Contract ID")},
-				{Title: "Display Name", Width: len("Display Name")},
-			}
-
-			maxWidths := make([]int, len(headers))
-			for i, header := range headers {
-				maxWidths[i] = header.Width
-			}
-
 			rows = append(rows, table.Row{"stx", "STX", fmt.Sprint(stxBalance), "", ""})
 			for k, v := range resp.FungibleTokens {
 				balance, _ := strconv.ParseInt(v.Balance, 10, 64)
@@ -254,9 +407,10 @@ func createBalanceCommand(props *props.AppProps) *cli.Command {
 
 			for k, balance := range fungibleTokenBalances {
 				split := strings.Split(k, "::")
-				contractName, err := props.HeroClient.GetContractReadOnly(split[0], "get-name", "string", []string{})
-				if err != nil {
-					rows = append(rows, table.Row{split[1], "Fungible", strconv.FormatInt(balance, 10), split[0], ""})
+				var contractName string
+				resp, err := props.HeroClient.GetContractReadOnlyContext(c.Context, split[0], "get-name", "", []string{})
+				if err == nil {
+					contractName = clarity.Display(clarity.Unwrap(resp))
 				}
 				rows = append(rows, table.Row{split[1], "Fungible", strconv.FormatInt(balance, 10), split[0], strings.TrimSpace(contractName)})
 			}
@@ -266,29 +420,19 @@ func createBalanceCommand(props *props.AppProps) *cli.Command {
 				rows = append(rows, table.Row{split[1], "Non-Fungible", strconv.FormatInt(count, 10), split[0], ""})
 			}
 
-			for _, row := range rows {
-				for i, cell := range row {
-					cellStr := fmt.Sprint(cell)
-					if len(cellStr) > maxWidths[i] {
-						maxWidths[i] = len(cellStr)
-					}
-				}
+			view := tui.View{
+				Title:   "Balance of " + address,
+				Columns: balanceColumns,
+				Rows:    rows,
+				Export:  address + "-balances.csv",
+				Actions: balanceActions(props),
 			}
 
-			for i, maxWidth := range maxWidths {
-				headers[i].Width = maxWidth
+			if !common.Interactive(c) {
+				return tui.PrintRows(c, view.Columns, view.Rows)
 			}
 
-			t := table.New(
-				table.WithColumns(headers),
-				table.WithRows(rows),
-				table.WithFocused(true),
-				table.WithStyles(common.TableStyles),
-			)
-
-			// Render the table
-			m := tableModel{table: t, client: props.HeroClient}
-			if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+			if err := tui.Run(c.Context, view); err != nil {
 				props.Logger.Fatal().Err(err).Msg("Failed to run program")
 			}
 
@@ -317,9 +461,9 @@ func createBalancesCommand(props *props.AppProps) *cli.Command {
 			fungibleTokenBalances := make(map[string]int64)
 			nonFungibleTokenCounts := make(map[string]int64)
 
-			for _, w := range props.Config.Wallets {
-				fmt.Println("Wallet:", w)
-				resp, err := props.HeroClient.GetAccountBalance(w, c.Int("block"))
+			for _, w := range props.Config.Active().Wallets {
+				fmt.Fprintln(os.Stderr, "Wallet:", w)
+				resp, err := props.HeroClient.GetAccountBalanceContext(c.Context, w, c.Int("block"))
 				if err != nil {
 					return err
 				}
@@ -331,19 +475,6 @@ func createBalancesCommand(props *props.AppProps) *cli.Command {
 				stxBalance += x
 			}
 
-			headers := []table.Column{
-				{Title: "Name", Width: len("Name")},
-				{Title: "Type", Width: len("Type")},
-				{Title: "Balance", Width: len("Balance")},
-				{Title: "Contract ID", Width: len("Contract ID")},
-				{Title: "Display Name", Width: len("Display Name")},
-			}
-
-			maxWidths := make([]int, len(headers))
-			for i, header := range headers {
-				maxWidths[i] = header.Width
-			}
-
 			rows = append(rows, table.Row{"stx", "STX", fmt.Sprint(stxBalance), "", ""})
 
 			for _, wallet := range wallets {
@@ -367,9 +498,10 @@ func createBalancesCommand(props *props.AppProps) *cli.Command {
 
 			for k, balance := range fungibleTokenBalances {
 				split := strings.Split(k, "::")
-				contractName, err := props.HeroClient.GetContractReadOnly(split[0], "get-name", "string", []string{})
-				if err != nil {
-					rows = append(rows, table.Row{split[1], "Fungible", strconv.FormatInt(balance, 10), split[0], ""})
+				var contractName string
+				resp, err := props.HeroClient.GetContractReadOnlyContext(c.Context, split[0], "get-name", "", []string{})
+				if err == nil {
+					contractName = clarity.Display(clarity.Unwrap(resp))
 				}
 				rows = append(rows, table.Row{split[1], "Fungible", strconv.FormatInt(balance, 10), split[0], strings.TrimSpace(contractName)})
 			}
@@ -379,28 +511,19 @@ func createBalancesCommand(props *props.AppProps) *cli.Command {
 				rows = append(rows, table.Row{split[1], "Non-Fungible", strconv.FormatInt(count, 10), split[0], ""})
 			}
 
-			for _, row := range rows {
-				for i, cell := range row {
-					cellStr := fmt.Sprint(cell)
-					if len(cellStr) > maxWidths[i] {
-						maxWidths[i] = len(cellStr)
-					}
-				}
+			view := tui.View{
+				Title:   "Balances of the configured wallets",
+				Columns: balanceColumns,
+				Rows:    rows,
+				Export:  "balances.csv",
+				Actions: balanceActions(props),
 			}
 
-			for i, maxWidth := range maxWidths {
-				headers[i].Width = maxWidth
+			if !common.Interactive(c) {
+				return tui.PrintRows(c, view.Columns, view.Rows)
 			}
 
-			t := table.New(
-				table.WithColumns(headers),
-				table.WithRows(rows),
-				table.WithFocused(true),
-				table.WithStyles(common.TableStyles),
-			)
-
-			m := tableModel{table: t, client: props.HeroClient}
-			if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+			if err := tui.Run(c.Context, view); err != nil {
 				props.Logger.Fatal().Err(err).Msg("Failed to run program")
 			}
 
@@ -425,9 +548,9 @@ func createBalancesByAddressCommand(props *props.AppProps) *cli.Command {
 			var wallets []hiro.BalanceResponseByAddress
 			var rows []table.Row
 
-			for _, w := range props.Config.Wallets {
-				fmt.Println("Wallet:", w)
-				resp, err := props.HeroClient.GetAccountBalance(w, c.Int("block"))
+			for _, w := range props.Config.Active().Wallets {
+				fmt.Fprintln(os.Stderr, "Wallet:", w)
+				resp, err := props.HeroClient.GetAccountBalanceContext(c.Context, w, c.Int("block"))
 				if err != nil {
 					return err
 				}
@@ -443,38 +566,19 @@ func createBalancesByAddressCommand(props *props.AppProps) *cli.Command {
 				rows = append(rows, table.Row{w.Address, fmt.Sprint(x)})
 			}
 
-			headers := []table.Column{
-				{Title: "Address", Width: len("Address")},
-				{Title: "Balance", Width: len("Balance")},
+			view := tui.View{
+				Title:   "Balances of the configured wallets",
+				Columns: addressColumns,
+				Rows:    rows,
+				Export:  "wallets.csv",
+				Actions: addressActions(props),
 			}
 
-			maxWidths := make([]int, len(headers))
-			for i, header := range headers {
-				maxWidths[i] = header.Width
+			if !common.Interactive(c) {
+				return tui.PrintRows(c, view.Columns, view.Rows)
 			}
 
-			for _, row := range rows {
-				for i, cell := range row {
-					cellStr := fmt.Sprint(cell)
-					if len(cellStr) > maxWidths[i] {
-						maxWidths[i] = len(cellStr)
-					}
-				}
-			}
-
-			for i, maxWidth := range maxWidths {
-				headers[i].Width = maxWidth
-			}
-
-			t := table.New(
-				table.WithColumns(headers),
-				table.WithRows(rows),
-				table.WithFocused(true),
-				table.WithStyles(common.TableStyles),
-			)
-
-			m := tableModel{table: t, client: props.HeroClient}
-			if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+			if err := tui.Run(c.Context, view); err != nil {
 				props.Logger.Fatal().Err(err).Msg("Failed to run program")
 			}
 
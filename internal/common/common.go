@@ -44,53 +44,6 @@ const (
 // TableData represents a single row of data to be displayed in the table.
 type TableData []string
 
-// CreateTable creates a table with dynamic column widths based on the provided headers and data.
-// headers: Titles of the columns.
-// dataRows: Data to be displayed, where each TableData represents a row.
-func CreateTable(headers []string, dataRows []TableData) table.Model {
-	// Initialize table columns with default widths based on header titles
-	columns := make([]table.Column, len(headers))
-	for i, title := range headers {
-		columns[i] = table.Column{Title: title, Width: len(title)}
-	}
-
-	// Prepare to track maximum widths
-	maxWidths := make([]int, len(columns))
-	for i, column := range columns {
-		maxWidths[i] = column.Width
-	}
-
-	// Iterate over data to find maximum widths
-	for _, row := range dataRows {
-		for i, value := range row {
-			if len(value) > maxWidths[i] {
-				maxWidths[i] = len(value)
-			}
-		}
-	}
-
-	// Set the calculated widths to headers
-	for i, maxWidth := range maxWidths {
-		columns[i].Width = maxWidth
-	}
-
-	// Convert dataRows to table.Rows
-	rows := make([]table.Row, len(dataRows))
-	for i, dataRow := range dataRows {
-		rows[i] = table.Row(dataRow)
-	}
-
-	// Create and return the table model
-	t := table.New(
-		table.WithColumns(columns),
-		table.WithRows(rows),
-		table.WithFocused(true),
-		table.WithStyles(TableStyles),
-	)
-
-	return t
-}
-
 func InsertDecimal(str string, position int) string {
 	// If position is 0, return the original string
 	if position == 0 {
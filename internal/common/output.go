@@ -0,0 +1,197 @@
+package common
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	prettytable "github.com/jedib0t/go-pretty/table"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v2"
+)
+
+// Formats of the global --output flag.
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputCSV   = "csv"
+	OutputYAML  = "yaml"
+	OutputTSV   = "tsv"
+)
+
+var OutputFormats = []string{OutputTable, OutputJSON, OutputCSV, OutputYAML, OutputTSV}
+
+// ValidOutputFormat returns an error unless format is one of OutputFormats.
+func ValidOutputFormat(format string) error {
+	for _, f := range OutputFormats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown output format %q, expected one of %s", format, strings.Join(OutputFormats, ", "))
+}
+
+// OutputFormat returns the --output format of the command.
+func OutputFormat(c *cli.Context) string {
+	if format := c.String("output"); format != "" {
+		return format
+	}
+	return OutputTable
+}
+
+// Interactive reports whether the command should run its bubbletea table,
+// which is only the case for table output to a terminal.
+func Interactive(c *cli.Context) bool {
+	return OutputFormat(c) == OutputTable && term.IsTerminal(os.Stdout.Fd())
+}
+
+// WriteRows writes rows in format. Table output is a static table. JSON and
+// YAML output a list of objects keyed by the snake cased headers. Cells of
+// the columns flagged in numeric are written as numbers, all other cells as
+// strings, so that a token named 1000 stays a string.
+func WriteRows(w io.Writer, format string, headers []string, numeric []bool, rows [][]string) error {
+	isNumber := func(column int, cell string) bool {
+		return column < len(numeric) && numeric[column] && number.MatchString(cell)
+	}
+	switch format {
+	case OutputTable:
+		t := prettytable.NewWriter()
+		t.SetOutputMirror(w)
+		t.SetStyle(prettytable.StyleRounded)
+		header := make(prettytable.Row, len(headers))
+		for i, h := range headers {
+			header[i] = h
+		}
+		t.AppendHeader(header)
+		for _, row := range rows {
+			r := make(prettytable.Row, len(row))
+			for i, cell := range row {
+				r[i] = cell
+			}
+			t.AppendRow(r)
+		}
+		t.Render()
+		return nil
+	case OutputCSV:
+		cw := csv.NewWriter(w)
+		cw.Write(headers)
+		cw.WriteAll(rows)
+		return cw.Error()
+	case OutputTSV:
+		var buf bytes.Buffer
+		for _, row := range append([][]string{headers}, rows...) {
+			for i, cell := range row {
+				if i > 0 {
+					buf.WriteByte('\t')
+				}
+				buf.WriteString(tsvEscaper.Replace(cell))
+			}
+			buf.WriteByte('\n')
+		}
+		_, err := w.Write(buf.Bytes())
+		return err
+	case OutputJSON:
+		return writeJSON(w, headers, rows, isNumber)
+	case OutputYAML:
+		records := make([]yaml.MapSlice, len(rows))
+		keys := fieldNames(headers)
+		for i, row := range rows {
+			record := make(yaml.MapSlice, len(row))
+			for j, cell := range row {
+				var value interface{} = cell
+				if isNumber(j, cell) {
+					value = yamlNumber(cell)
+				}
+				record[j] = yaml.MapItem{Key: keys[j], Value: value}
+			}
+			records[i] = record
+		}
+		b, err := yaml.Marshal(records)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	default:
+		return ValidOutputFormat(format)
+	}
+}
+
+var tsvEscaper = strings.NewReplacer("\t", " ", "\n", " ", "\r", "")
+
+var nonWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+// fieldNames snake cases headers, "Liquidity in USD" becomes
+// liquidity_in_usd.
+func fieldNames(headers []string) []string {
+	names := make([]string, len(headers))
+	for i, h := range headers {
+		names[i] = strings.Trim(nonWord.ReplaceAllString(strings.ToLower(h), "_"), "_")
+	}
+	return names
+}
+
+var number = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?$`)
+
+// writeJSON writes the records by hand, as maps would lose the column order.
+func writeJSON(w io.Writer, headers []string, rows [][]string, isNumber func(column int, cell string) bool) error {
+	keys := fieldNames(headers)
+	var buf bytes.Buffer
+	buf.WriteString("[")
+	for i, row := range rows {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n  {")
+		for j, cell := range row {
+			if j > 0 {
+				buf.WriteString(", ")
+			}
+			key, _ := json.Marshal(keys[j])
+			buf.Write(key)
+			buf.WriteString(": ")
+			if isNumber(j, cell) {
+				// Numbers are written verbatim so large amounts keep their
+				// precision.
+				buf.WriteString(cell)
+			} else {
+				value, _ := json.Marshal(cell)
+				buf.Write(value)
+			}
+		}
+		buf.WriteString("}")
+	}
+	if len(rows) > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("]\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// yamlNumber returns the number cell as an integer or float, or as the
+// string itself if neither represents it exactly.
+func yamlNumber(cell string) interface{} {
+	if i, err := strconv.ParseInt(cell, 10, 64); err == nil {
+		return i
+	}
+	if u, err := strconv.ParseUint(cell, 10, 64); err == nil {
+		return u
+	}
+	exact := cell
+	if strings.Contains(exact, ".") {
+		exact = strings.TrimSuffix(strings.TrimRight(exact, "0"), ".")
+	}
+	if f, err := strconv.ParseFloat(cell, 64); err == nil && strconv.FormatFloat(f, 'f', -1, 64) == exact {
+		return f
+	}
+	// Keep values float64 cannot represent exactly as strings.
+	return cell
+}
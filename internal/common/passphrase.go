@@ -0,0 +1,58 @@
+package common
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// PassphraseEnv holds the keystore passphrase for non-interactive use.
+const PassphraseEnv = "TELLER_KEYSTORE_PASSPHRASE"
+
+// ReadPassphrase returns the keystore passphrase from PassphraseEnv or, when
+// it is unset, prompts for it on the terminal without echoing. With confirm
+// set the passphrase is asked twice and must not be empty.
+func ReadPassphrase(prompt string, confirm bool) ([]byte, error) {
+	if env, ok := os.LookupEnv(PassphraseEnv); ok {
+		if confirm && env == "" {
+			return nil, fmt.Errorf("%s is empty", PassphraseEnv)
+		}
+		return []byte(env), nil
+	}
+	passphrase, err := ReadSecret(prompt)
+	if err != nil {
+		return nil, err
+	}
+	if !confirm {
+		return passphrase, nil
+	}
+	if len(passphrase) == 0 {
+		return nil, errors.New("the passphrase must not be empty")
+	}
+	again, err := ReadSecret("Repeat passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(passphrase, again) {
+		return nil, errors.New("the passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+// ReadSecret prompts on stderr and reads a line from the terminal without
+// echoing it.
+func ReadSecret(prompt string) ([]byte, error) {
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return nil, fmt.Errorf("stdin is not a terminal, set %s to provide the passphrase", PassphraseEnv)
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	secret, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("error reading from terminal: %w", err)
+	}
+	return secret, nil
+}
@@ -0,0 +1,37 @@
+package common
+
+import "strings"
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws values as a line of block characters from the lowest to
+// the highest value. Values beyond width are averaged into width buckets.
+func Sparkline(values []float64, width int) string {
+	if width > 0 && len(values) > width {
+		buckets := make([]float64, width)
+		for i := range buckets {
+			start, end := i*len(values)/width, (i+1)*len(values)/width
+			for _, v := range values[start:end] {
+				buckets[i] += v
+			}
+			buckets[i] /= float64(end - start)
+		}
+		values = buckets
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	low, high := values[0], values[0]
+	for _, v := range values {
+		low, high = min(low, v), max(high, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if high > low {
+			i = int((v - low) / (high - low) * float64(len(sparks)-1))
+		}
+		b.WriteRune(sparks[i])
+	}
+	return b.String()
+}
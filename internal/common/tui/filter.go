@@ -0,0 +1,154 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// filter is a parsed search query. Terms are separated by spaces and all
+// have to match. A term such as balance>1000000 or status=success compares
+// a column by its Kind, any other term is a fuzzy search of the row.
+type filter struct {
+	terms []term
+	err   error
+}
+
+type term struct {
+	// column is -1 for fuzzy terms.
+	column int
+	op     string
+	value  string
+	// parsed is value parsed by the Kind of the column.
+	parsed value
+}
+
+var expression = regexp.MustCompile(`^([^<>=!~]+)(>=|<=|!=|=|>|<|~)(.*)$`)
+
+func parseFilter(query string, columns []Column) filter {
+	var f filter
+	for _, field := range strings.Fields(query) {
+		m := expression.FindStringSubmatch(field)
+		if m == nil {
+			f.terms = append(f.terms, term{column: -1, value: field})
+			continue
+		}
+		column := columnIndex(columns, m[1])
+		if column < 0 {
+			f.err = fmt.Errorf("unknown column %q", m[1])
+			continue
+		}
+		parsed, ok := columns[column].parse(m[3])
+		if !ok && m[2] != "~" {
+			f.err = fmt.Errorf("%s cannot be compared to %q", columns[column].Title, m[3])
+			continue
+		}
+		f.terms = append(f.terms, term{column: column, op: m[2], value: m[3], parsed: parsed})
+	}
+	return f
+}
+
+// columnIndex finds a column by its title, ignoring case, spaces and
+// punctuation so "Total Supply" is total_supply or totalsupply.
+func columnIndex(columns []Column, name string) int {
+	name = normalize(name)
+	for i, column := range columns {
+		if normalize(column.Title) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func normalize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
+
+func (f filter) empty() bool {
+	return len(f.terms) == 0
+}
+
+func (f filter) match(r parsedRow, columns []Column) bool {
+	for _, t := range f.terms {
+		if !t.match(r, columns) {
+			return false
+		}
+	}
+	return true
+}
+
+// match compares the cell of the term's column as parsed when the rows were
+// set, rather than parsing it again for every query.
+func (t term) match(r parsedRow, columns []Column) bool {
+	if t.column < 0 {
+		for _, cell := range r.row {
+			if fuzzy(cell, t.value) {
+				return true
+			}
+		}
+		return false
+	}
+	if t.column >= len(r.row) {
+		return false
+	}
+	cell := r.cells[t.column]
+	if t.op == "~" {
+		return containsFold(cell.text, t.value)
+	}
+
+	column := columns[t.column]
+	var c int
+	switch {
+	case column.Kind == Text:
+		c = strings.Compare(strings.ToLower(cell.text), strings.ToLower(t.value))
+	case !cell.ok:
+		// Cells that are not values of the column never match a comparison.
+		return false
+	default:
+		c = column.compareValues(cell.value, t.parsed)
+	}
+	switch t.op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	default:
+		return c <= 0
+	}
+}
+
+// fuzzy reports whether the letters of pattern appear in s in order,
+// ignoring case.
+func fuzzy(s, pattern string) bool {
+	for _, p := range pattern {
+		p = unicode.ToLower(p)
+		for {
+			r, size := utf8.DecodeRuneInString(s)
+			if size == 0 {
+				return false
+			}
+			s = s[size:]
+			if unicode.ToLower(r) == p {
+				break
+			}
+		}
+	}
+	return true
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
@@ -0,0 +1,187 @@
+package tui
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/hashhavoc/teller/pkg/utils/uint128"
+)
+
+// Kind is the type of the values of a column, which decides how the column
+// sorts and how filters compare it.
+type Kind int
+
+const (
+	// Text sorts lexically.
+	Text Kind = iota
+	// Integer sorts whole numbers of any size, such as block heights,
+	// counts and 256 bit supplies.
+	Integer
+	// Decimal sorts decimal numbers such as prices and token amounts. Set
+	// the Decimals of the column when it holds amounts of a token, as
+	// written by common.InsertDecimal.
+	Decimal
+	// Uint128 sorts unsigned 128 bit integers such as raw token balances.
+	Uint128
+	// Timestamp sorts times such as 2006-01-02 15:04:05, RFC 3339 or Unix
+	// seconds.
+	Timestamp
+	// Address sorts Stacks, Bitcoin and EVM addresses ignoring case, with
+	// contract principals following the address that deployed them.
+	Address
+)
+
+var timestampLayouts = []string{time.RFC3339, time.DateTime, time.DateOnly}
+
+// value is a cell parsed for comparison by the Kind of its column.
+type value struct {
+	integer *big.Int
+	number  *big.Rat
+	uint128 uint128.Uint128
+	time    time.Time
+	address [2]string
+}
+
+// cell is a cell of a column parsed for sorting.
+type cell struct {
+	text  string
+	value value
+	ok    bool
+}
+
+func (column Column) cell(s string) cell {
+	v, ok := column.parse(s)
+	return cell{text: s, value: v, ok: ok}
+}
+
+// parsedRow is a row with its cells parsed by the Kind of their columns when
+// the rows are set, so sorting and filtering never parse a cell again.
+type parsedRow struct {
+	row   table.Row
+	cells []cell
+}
+
+// cell returns the parsed cell of column, a missing cell if the row is
+// shorter.
+func (r parsedRow) cell(column int) cell {
+	if column < len(r.cells) {
+		return r.cells[column]
+	}
+	return cell{}
+}
+
+func parseRows(rows []table.Row, columns []Column) []parsedRow {
+	parsed := make([]parsedRow, len(rows))
+	for i, row := range rows {
+		cells := make([]cell, len(row))
+		for j, s := range row {
+			if j < len(columns) {
+				cells[j] = columns[j].cell(s)
+			} else {
+				cells[j] = cell{text: s, ok: true}
+			}
+		}
+		parsed[i] = parsedRow{row: row, cells: cells}
+	}
+	return parsed
+}
+
+// compare compares two cells of column. Cells that do not parse as the
+// Kind of the column sort before those that do, and compare lexically among
+// themselves.
+func (column Column) compare(a, b cell) int {
+	switch {
+	case column.Kind == Text:
+		return strings.Compare(a.text, b.text)
+	case a.ok && b.ok:
+		return column.compareValues(a.value, b.value)
+	case a.ok:
+		return 1
+	case b.ok:
+		return -1
+	default:
+		return strings.Compare(a.text, b.text)
+	}
+}
+
+// parse parses s by the Kind of column, reporting whether s is one of its
+// values. Every string is a Text value.
+func (column Column) parse(s string) (value, bool) {
+	var v value
+	ok := true
+	switch column.Kind {
+	case Integer:
+		v.integer, ok = new(big.Int).SetString(s, 10)
+	case Decimal:
+		if column.Decimals > 0 {
+			v.integer, ok = parseFixed(s, column.Decimals)
+		} else {
+			v.number, ok = new(big.Rat).SetString(s)
+		}
+	case Uint128:
+		var err error
+		v.uint128, err = uint128.FromDecimal(s)
+		ok = err == nil
+	case Timestamp:
+		v.time, ok = parseTimestamp(s)
+	case Address:
+		if s == "" {
+			return v, false
+		}
+		address, name, _ := strings.Cut(strings.ToLower(s), ".")
+		v.address = [2]string{address, name}
+	}
+	return v, ok
+}
+
+func (column Column) compareValues(x, y value) int {
+	switch column.Kind {
+	case Integer:
+		return x.integer.Cmp(y.integer)
+	case Decimal:
+		if column.Decimals > 0 {
+			return x.integer.Cmp(y.integer)
+		}
+		return x.number.Cmp(y.number)
+	case Uint128:
+		return x.uint128.Cmp(y.uint128)
+	case Timestamp:
+		return x.time.Compare(y.time)
+	case Address:
+		if c := strings.Compare(x.address[0], y.address[0]); c != 0 {
+			return c
+		}
+		return strings.Compare(x.address[1], y.address[1])
+	default:
+		return 0
+	}
+}
+
+// parseFixed parses an amount with at most decimals places, such as
+// 1234.567890, as the integer 1234567890 of its smallest units. Comparing
+// these is exact and much cheaper than comparing fractions.
+func parseFixed(s string, decimals int) (*big.Int, bool) {
+	whole, fraction, _ := strings.Cut(s, ".")
+	if len(fraction) > decimals || strings.ContainsAny(fraction, "+-") || (whole == "" && fraction == "") {
+		return nil, false
+	}
+	if whole == "" || whole == "-" || whole == "+" {
+		whole += "0"
+	}
+	return new(big.Int).SetString(whole+fraction+strings.Repeat("0", decimals-len(fraction)), 10)
+}
+
+func parseTimestamp(s string) (time.Time, bool) {
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	if seconds, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(seconds, 0), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,551 @@
+// Package tui is the interactive table shared by the commands. A command
+// declares the columns, rows and row actions of a View; the Model adds
+// sorting, searching, CSV export, drill down into further views and a help
+// overlay with the same key bindings everywhere.
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/hashhavoc/teller/internal/common"
+	"github.com/urfave/cli/v2"
+)
+
+// Column is a column of a View.
+type Column struct {
+	Title string
+	Kind  Kind
+	// Decimals is the number of decimal places of a Decimal column of
+	// token amounts, or 0 if its cells can have any number.
+	Decimals int
+}
+
+// Action is run by a key on the selected row of a View. Run is called from
+// the update loop, so anything slow, such as an API call, belongs in the
+// returned command. ctx is cancelled when the key is pressed again, when the
+// view is closed and when the program exits.
+type Action struct {
+	Key  string
+	Help string
+	Run  func(ctx context.Context, row table.Row) tea.Cmd
+}
+
+// View is a page of the Model: a table, or a scrolling text such as a
+// contract source when Text is set.
+type View struct {
+	Title   string
+	Columns []Column
+	Rows    []table.Row
+	Text    string
+	// Export is the CSV file the rows are written to by 'a', none if empty.
+	Export  string
+	Actions []Action
+}
+
+// Titles returns the titles of the columns of v.
+func (v View) Titles() []string {
+	titles := make([]string, len(v.Columns))
+	for i, column := range v.Columns {
+		titles[i] = column.Title
+	}
+	return titles
+}
+
+// Numeric reports whether the cells of the column are numbers, which
+// JSON and YAML output write as such.
+func (c Column) Numeric() bool {
+	switch c.Kind {
+	case Integer, Decimal, Uint128:
+		return true
+	default:
+		return false
+	}
+}
+
+// WriteRows writes rows with the titles of columns in format, typing the
+// cells of numeric columns as numbers in JSON and YAML.
+func WriteRows[R ~[]string](w io.Writer, format string, columns []Column, rows []R) error {
+	titles := make([]string, len(columns))
+	numeric := make([]bool, len(columns))
+	for i, column := range columns {
+		titles[i] = column.Title
+		numeric[i] = column.Numeric()
+	}
+	plain := make([][]string, len(rows))
+	for i, row := range rows {
+		plain[i] = row
+	}
+	return common.WriteRows(w, format, titles, numeric, plain)
+}
+
+// PrintRows prints rows to stdout in the --output format of the command.
+func PrintRows[R ~[]string](c *cli.Context, columns []Column, rows []R) error {
+	return WriteRows(os.Stdout, common.OutputFormat(c), columns, rows)
+}
+
+// Rows converts the rows built by a command to table rows.
+func Rows[R ~[]string](rows []R) []table.Row {
+	converted := make([]table.Row, len(rows))
+	for i, row := range rows {
+		converted[i] = table.Row(row)
+	}
+	return converted
+}
+
+type pushMsg struct{ view View }
+
+type statusMsg string
+
+type errorMsg struct{ err error }
+
+// Push returns the message opening view on top of the current one, for
+// actions drilling down into the selected row.
+func Push(view View) tea.Msg {
+	return pushMsg{view}
+}
+
+// Status returns the message showing a line of text under the table.
+func Status(format string, a ...any) tea.Msg {
+	return statusMsg(fmt.Sprintf(format, a...))
+}
+
+// Error returns the message showing err under the table. Cancelled
+// contexts are not reported.
+func Error(err error) tea.Msg {
+	return errorMsg{err}
+}
+
+type page struct {
+	view   View
+	table  table.Model
+	text   viewport.Model
+	ctx    context.Context
+	cancel context.CancelFunc
+	// running cancels the in flight command of each action key.
+	running map[string]context.CancelFunc
+
+	// rows are all the rows in their sort order, the table only shows
+	// those matching the search query.
+	rows   []parsedRow
+	query  string
+	filter filter
+
+	sortColumn    int
+	sortAscending bool
+}
+
+// Model is the bubbletea model showing a stack of views, the first one
+// given to New and the others pushed by actions.
+type Model struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	pages  []*page
+	status string
+	help   bool
+
+	// searching is set while the search query is typed into input.
+	searching bool
+	input     textinput.Model
+
+	windowWidth  int
+	windowHeight int
+}
+
+// New returns a model showing view. Actions are cancelled with ctx and when
+// the user quits.
+func New(ctx context.Context, view View) Model {
+	ctx, cancel := context.WithCancel(ctx)
+	m := Model{ctx: ctx, cancel: cancel, input: textinput.New()}
+	m.input.Prompt = "/"
+	m.input.Placeholder = "text or column filters such as balance>1000000 status=success"
+	m.pages = []*page{m.newPage(view)}
+	return m
+}
+
+// Run shows view in the alternate screen until the user quits or ctx is
+// done.
+func Run(ctx context.Context, view View) error {
+	_, err := tea.NewProgram(New(ctx, view), tea.WithAltScreen(), tea.WithContext(ctx)).Run()
+	return err
+}
+
+func (m Model) newPage(view View) *page {
+	ctx, cancel := context.WithCancel(m.ctx)
+	p := &page{view: view, ctx: ctx, cancel: cancel, running: make(map[string]context.CancelFunc), rows: parseRows(view.Rows, view.Columns), sortColumn: -1}
+	if view.Text != "" {
+		p.text = viewport.New(m.windowWidth, m.windowHeight-common.TableHeightPadding)
+		p.text.SetContent(view.Text)
+	} else {
+		p.table = table.New(
+			table.WithColumns(columnWidths(view.Columns, view.Rows)),
+			table.WithRows(view.Rows),
+			table.WithFocused(true),
+			table.WithStyles(common.TableStyles),
+		)
+	}
+	m.resize(p)
+	return p
+}
+
+// columnWidths sizes the columns to their widest cell.
+func columnWidths(columns []Column, rows []table.Row) []table.Column {
+	widths := make([]table.Column, len(columns))
+	for i, column := range columns {
+		widths[i] = table.Column{Title: column.Title, Width: lipgloss.Width(column.Title)}
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if i < len(widths) && lipgloss.Width(cell) > widths[i].Width {
+				widths[i].Width = lipgloss.Width(cell)
+			}
+		}
+	}
+	return widths
+}
+
+func (m Model) resize(p *page) {
+	if m.windowHeight == 0 {
+		return
+	}
+	height := m.windowHeight - common.TableHeightPadding
+	p.table.SetHeight(height)
+	p.text.Width = m.windowWidth - common.TableWidthPadding
+	p.text.Height = height
+}
+
+func (m Model) top() *page {
+	return m.pages[len(m.pages)-1]
+}
+
+func (m Model) Init() tea.Cmd {
+	return tea.SetWindowTitle("Teller")
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	p := m.top()
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.windowWidth = msg.Width
+		m.windowHeight = msg.Height
+		for _, p := range m.pages {
+			m.resize(p)
+		}
+		return m, nil
+	case pushMsg:
+		m.pages = append(m.pages, m.newPage(msg.view))
+		m.status = ""
+		m.searching = false
+		m.input.Blur()
+		return m, nil
+	case statusMsg:
+		m.status = string(msg)
+		return m, nil
+	case errorMsg:
+		if !errors.Is(msg.err, context.Canceled) {
+			m.status = "Error: " + msg.err.Error()
+		}
+		return m, nil
+	case tea.KeyMsg:
+		key := msg.String()
+		if m.help {
+			// Any key closes the help, ctrl+c still quits.
+			m.help = false
+			if key != "ctrl+c" {
+				return m, nil
+			}
+		}
+		if m.searching {
+			return m.updateSearch(p, msg)
+		}
+		switch key {
+		case "ctrl+c":
+			m.cancel()
+			return m, tea.Quit
+		case "/":
+			if p.view.Text == "" {
+				m.searching = true
+				m.input.SetValue(p.query)
+				m.input.CursorEnd()
+				return m, m.input.Focus()
+			}
+		case "q", "esc":
+			if key == "esc" && p.query != "" {
+				m.search(p, "")
+				return m, nil
+			}
+			if len(m.pages) > 1 {
+				p.cancel()
+				m.pages = m.pages[:len(m.pages)-1]
+				m.status = ""
+				return m, nil
+			}
+			if key == "q" {
+				m.cancel()
+				return m, tea.Quit
+			}
+			return m, nil
+		case "?":
+			m.help = true
+			return m, nil
+		case "a":
+			if p.view.Export != "" && p.view.Text == "" {
+				m.status = m.export(p)
+				return m, nil
+			}
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			if p.view.Text == "" {
+				m.sort(p, int(msg.Runes[0]-'1'))
+				return m, nil
+			}
+		}
+		for _, action := range p.view.Actions {
+			if action.Key != key {
+				continue
+			}
+			if cancel, ok := p.running[key]; ok {
+				cancel()
+			}
+			ctx, cancel := context.WithCancel(p.ctx)
+			p.running[key] = cancel
+			var row table.Row
+			if p.view.Text == "" {
+				row = p.table.SelectedRow()
+				if row == nil {
+					return m, nil
+				}
+			}
+			cmd := action.Run(ctx, row)
+			if cmd != nil {
+				m.status = action.Help + "..."
+			}
+			return m, cmd
+		}
+	}
+
+	var cmd tea.Cmd
+	if p.view.Text != "" {
+		p.text, cmd = p.text.Update(msg)
+	} else {
+		p.table, cmd = p.table.Update(msg)
+	}
+	return m, cmd
+}
+
+// sort sorts the rows of p by column, reversing the order when the column
+// is already sorted.
+func (m *Model) sort(p *page, column int) {
+	if column >= len(p.view.Columns) {
+		return
+	}
+	if p.sortColumn == column {
+		p.sortAscending = !p.sortAscending
+	} else {
+		p.sortColumn = column
+		p.sortAscending = true
+	}
+	col := p.view.Columns[column]
+	slices.SortStableFunc(p.rows, func(a, b parsedRow) int {
+		c := col.compare(a.cell(column), b.cell(column))
+		if !p.sortAscending {
+			c = -c
+		}
+		return c
+	})
+	p.applyFilter()
+}
+
+// updateSearch handles the keys typed while searching. The rows are
+// filtered as the query is typed, enter keeps the query and esc drops it.
+func (m Model) updateSearch(p *page, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.cancel()
+		return m, tea.Quit
+	case "enter":
+		m.searching = false
+		m.input.Blur()
+		return m, nil
+	case "esc":
+		m.searching = false
+		m.input.Blur()
+		m.search(p, "")
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != p.query {
+		m.search(p, m.input.Value())
+	}
+	return m, cmd
+}
+
+// search shows the rows of p matching query.
+func (m *Model) search(p *page, query string) {
+	p.query = query
+	p.filter = parseFilter(query, p.view.Columns)
+	p.applyFilter()
+	p.table.GotoTop()
+}
+
+func (p *page) applyFilter() {
+	var matches []table.Row
+	for _, r := range p.rows {
+		if p.filter.match(r, p.view.Columns) {
+			matches = append(matches, r.row)
+		}
+	}
+	p.table.SetRows(matches)
+}
+
+// export writes the rows of p to its CSV file and returns the status to
+// show.
+func (m *Model) export(p *page) string {
+	rows := append([]table.Row{p.view.Titles()}, p.table.Rows()...)
+	if err := common.WriteRowsToCSV(rows, p.view.Export); err != nil {
+		return "Error: " + err.Error()
+	}
+	return fmt.Sprintf("Table dumped to %s", p.view.Export)
+}
+
+var (
+	titleStyle = lipgloss.NewStyle().Bold(true).Padding(0, 1)
+	faintStyle = lipgloss.NewStyle().Faint(true).Padding(0, 1)
+)
+
+func (m Model) View() string {
+	if m.help {
+		return m.helpView()
+	}
+	p := m.top()
+
+	header := []string{p.view.Title}
+	var body string
+	if p.view.Text != "" {
+		body = p.text.View()
+	} else {
+		if p.filter.empty() {
+			header = append(header, fmt.Sprintf("Total: %d", len(p.rows)))
+		} else {
+			header = append(header, fmt.Sprintf("Matches: %d of %d", len(p.table.Rows()), len(p.rows)))
+		}
+		if p.sortColumn >= 0 {
+			order := "▲"
+			if !p.sortAscending {
+				order = "▼"
+			}
+			header = append(header, fmt.Sprintf("sorted by %s %s", p.view.Columns[p.sortColumn].Title, order))
+		}
+		body = p.table.View()
+	}
+	if p.view.Title == "" {
+		header = header[1:]
+	}
+
+	footer := m.status
+	switch {
+	case m.searching:
+		footer = m.input.View()
+		if p.filter.err != nil {
+			footer += "  " + p.filter.err.Error()
+		}
+	case footer != "":
+	case p.query != "":
+		footer = fmt.Sprintf("/%s · esc clear · %s", p.query, m.hint(p))
+	default:
+		footer = m.hint(p)
+	}
+	return lipgloss.JoinVertical(
+		lipgloss.Top,
+		titleStyle.Render(strings.Join(header, " · ")),
+		common.BaseTableStyle.Render(body),
+		faintStyle.Render(footer))
+}
+
+// hint lists the actions of p under the table.
+func (m Model) hint(p *page) string {
+	var keys []string
+	for _, action := range p.view.Actions {
+		keys = append(keys, fmt.Sprintf("%s %s", action.Key, action.Help))
+	}
+	if p.view.Text == "" {
+		keys = append(keys, "/ search")
+	}
+	if p.view.Export != "" && p.view.Text == "" {
+		keys = append(keys, "a export")
+	}
+	if len(m.pages) > 1 {
+		keys = append(keys, "q back")
+	} else {
+		keys = append(keys, "q quit")
+	}
+	return strings.Join(append(keys, "? help"), " · ")
+}
+
+func (m Model) helpView() string {
+	p := m.top()
+	var b strings.Builder
+	section := func(title string, bindings [][2]string) {
+		b.WriteString(titleStyle.Render(title) + "\n")
+		for _, binding := range bindings {
+			fmt.Fprintf(&b, "   %-16s %s\n", binding[0], binding[1])
+		}
+		b.WriteString("\n")
+	}
+
+	if p.view.Text != "" {
+		section("Navigation", [][2]string{
+			{"↑/k ↓/j", "scroll"},
+			{"pgup pgdown", "scroll a page"},
+		})
+	} else {
+		section("Navigation", [][2]string{
+			{"↑/k ↓/j", "move the selection"},
+			{"pgup/b pgdown/f", "move a page"},
+			{"g/home G/end", "go to the first or last row"},
+			{"1-9", "sort by the column, again to reverse"},
+		})
+		section("Search", [][2]string{
+			{"/", "search the rows, enter to keep the query"},
+			{"text", "fuzzy match of any cell, e.g. stx matches sip-010-token"},
+			{"column op value", "compare a column with =, !=, <, <=, >, >= or ~ (contains)"},
+			{"", "e.g. balance>1000000 status=success, all terms have to match"},
+			{"esc", "clear the search"},
+		})
+	}
+
+	general := [][2]string{}
+	if p.view.Export != "" && p.view.Text == "" {
+		general = append(general, [2]string{"a", "export the rows to " + p.view.Export})
+	}
+	general = append(general,
+		[2]string{"q/esc", "close this view, q quits the first one"},
+		[2]string{"ctrl+c", "quit"},
+		[2]string{"?", "toggle this help"},
+	)
+	section("General", general)
+
+	if len(p.view.Actions) > 0 {
+		var actions [][2]string
+		for _, action := range p.view.Actions {
+			actions = append(actions, [2]string{action.Key, action.Help})
+		}
+		title := p.view.Title
+		if title == "" {
+			title = "Actions"
+		}
+		section(title, actions)
+	}
+	b.WriteString(faintStyle.Render("Press any key to close the help"))
+	return common.BaseTableStyle.Render(b.String())
+}
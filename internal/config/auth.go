@@ -0,0 +1,173 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/hashhavoc/teller/pkg/api/transport"
+)
+
+// EndpointNames are the endpoints of ConfigEndpoints, as used to key Auth.
+var EndpointNames = []string{"hiro", "ord", "alex", "stxtools", "bob"}
+
+// ValidEndpoint returns an error unless name is one of EndpointNames.
+func ValidEndpoint(name string) error {
+	for _, n := range EndpointNames {
+		if n == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown endpoint %q, expected one of %s", name, strings.Join(EndpointNames, ", "))
+}
+
+// ConfigAuth holds the credentials sent with every request to an endpoint.
+type ConfigAuth struct {
+	// APIKey is sent as the X-Api-Key header, as Hiro expects.
+	APIKey *Secret `yaml:"api_key,omitempty"`
+	// Username and Password are sent as basic auth.
+	Username string  `yaml:"username,omitempty"`
+	Password *Secret `yaml:"password,omitempty"`
+	// Headers are sent as is, for example an Authorization bearer token.
+	Headers map[string]*Secret `yaml:"headers,omitempty"`
+}
+
+// Secret is a credential given inline, read from an environment variable
+// or printed by a command such as `pass show teller/hiro`. In yaml a plain
+// string is an inline value, otherwise one of value, env or command is set.
+type Secret struct {
+	Value   string `yaml:"value,omitempty"`
+	Env     string `yaml:"env,omitempty"`
+	Command string `yaml:"command,omitempty"`
+}
+
+func (s *Secret) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value string
+	if err := unmarshal(&value); err == nil {
+		*s = Secret{Value: value}
+		return nil
+	}
+	type plain Secret
+	if err := unmarshal((*plain)(s)); err != nil {
+		return err
+	}
+	n := 0
+	for _, v := range []string{s.Value, s.Env, s.Command} {
+		if v != "" {
+			n++
+		}
+	}
+	if n != 1 {
+		return errors.New("a secret needs exactly one of value, env or command")
+	}
+	return nil
+}
+
+func (s Secret) MarshalYAML() (interface{}, error) {
+	if s.Env == "" && s.Command == "" {
+		return s.Value, nil
+	}
+	type plain Secret
+	return plain(s), nil
+}
+
+// Source describes where the secret comes from without revealing it.
+func (s Secret) Source() string {
+	switch {
+	case s.Env != "":
+		return "env " + s.Env
+	case s.Command != "":
+		return "command " + s.Command
+	default:
+		return "inline"
+	}
+}
+
+// Resolve returns the secret, running its command through the shell if it
+// has one.
+func (s Secret) Resolve() (string, error) {
+	switch {
+	case s.Env != "":
+		value := os.Getenv(s.Env)
+		if value == "" {
+			return "", fmt.Errorf("environment variable %s is not set", s.Env)
+		}
+		return value, nil
+	case s.Command != "":
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.Command("cmd", "/C", s.Command)
+		} else {
+			cmd = exec.Command("sh", "-c", s.Command)
+		}
+		var stderr bytes.Buffer
+		cmd.Stdin = os.Stdin
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("running %q: %w: %s", s.Command, err, strings.TrimSpace(stderr.String()))
+		}
+		// Password managers print the secret on the first line, possibly
+		// followed by metadata.
+		value, _, _ := strings.Cut(string(out), "\n")
+		return strings.TrimSpace(value), nil
+	default:
+		return s.Value, nil
+	}
+}
+
+// Header resolves the credentials into the headers to send.
+func (a ConfigAuth) Header() (http.Header, error) {
+	header := make(http.Header)
+	if a.APIKey != nil {
+		key, err := a.APIKey.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("api key: %w", err)
+		}
+		header.Set(transport.APIKeyHeader, key)
+	}
+	if a.Username != "" || a.Password != nil {
+		var password string
+		if a.Password != nil {
+			var err error
+			if password, err = a.Password.Resolve(); err != nil {
+				return nil, fmt.Errorf("password: %w", err)
+			}
+		}
+		credentials := base64.StdEncoding.EncodeToString([]byte(a.Username + ":" + password))
+		header.Set("Authorization", "Basic "+credentials)
+	}
+	for name, secret := range a.Headers {
+		value, err := secret.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("header %s: %w", name, err)
+		}
+		header.Set(name, value)
+	}
+	return header, nil
+}
+
+// empty reports whether a holds no credentials.
+func (a ConfigAuth) empty() bool {
+	return a.APIKey == nil && a.Username == "" && a.Password == nil && len(a.Headers) == 0
+}
+
+// inline reports whether a stores a secret in the config file itself.
+func (a ConfigAuth) inline() bool {
+	secrets := []*Secret{a.APIKey, a.Password}
+	for _, s := range a.Headers {
+		secrets = append(secrets, s)
+	}
+	for _, s := range secrets {
+		if s != nil && s.Env == "" && s.Command == "" {
+			return true
+		}
+	}
+	return false
+}
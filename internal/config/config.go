@@ -2,43 +2,107 @@ package config
 
 import (
 	"fmt"
+	"net/http"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/hashhavoc/teller/pkg/api/alex"
 	"github.com/hashhavoc/teller/pkg/api/gobob"
 	"github.com/hashhavoc/teller/pkg/api/hiro"
 	"github.com/hashhavoc/teller/pkg/api/stxtools"
+	"github.com/hashhavoc/teller/pkg/api/transport"
+	"github.com/hashhavoc/teller/pkg/stacks/address"
 
 	"gopkg.in/yaml.v2"
 )
 
 type Config struct {
-	Path      string          `yaml:"-"`
-	Endpoints ConfigEndpoints `yaml:"endpoints"`
-	Wallets   []string        `yaml:"wallets"`
+	Path string `yaml:"-"`
+	// Home is the directory of the local state, see DataDir.
+	Home string `yaml:"-"`
+	// The top level endpoints and wallets are used without a profile, and
+	// for the endpoints a profile leaves unset.
+	ConfigProfile `yaml:",inline"`
+	HTTP          ConfigHTTP  `yaml:"http,omitempty"`
+	Cache         ConfigCache `yaml:"cache,omitempty"`
+	// Auth maps an endpoint name to the credentials sent with its requests.
+	Auth map[string]ConfigAuth `yaml:"auth,omitempty"`
+	// Profile is the profile used unless --profile selects another.
+	Profile  string                    `yaml:"profile,omitempty"`
+	Profiles map[string]*ConfigProfile `yaml:"profiles,omitempty"`
+	// Portfolio configures the prices of wallet portfolio.
+	Portfolio ConfigPortfolio `yaml:"portfolio,omitempty"`
+
+	// active is the name of the profile in use, empty for the top level.
+	active string
 }
 
 type ConfigEndpoints struct {
-	Hiro     string `yaml:"hiro"`
-	Ord      string `yaml:"ord"`
-	Alex     string `yaml:"alex"`
-	StxTools string `yaml:"stxtools"`
-	Bob      string `yaml:"bob"`
+	Hiro     string `yaml:"hiro,omitempty"`
+	Ord      string `yaml:"ord,omitempty"`
+	Alex     string `yaml:"alex,omitempty"`
+	StxTools string `yaml:"stxtools,omitempty"`
+	Bob      string `yaml:"bob,omitempty"`
+}
+
+// ConfigHTTP tunes the transport shared by the API clients, zero values
+// keep the transport defaults.
+type ConfigHTTP struct {
+	Timeout           string  `yaml:"timeout,omitempty"`
+	UserAgent         string  `yaml:"user_agent,omitempty"`
+	MaxRetries        int     `yaml:"max_retries,omitempty"`
+	RequestsPerSecond float64 `yaml:"requests_per_second,omitempty"`
+	Burst             int     `yaml:"burst,omitempty"`
+}
+
+// ConfigCache controls the response cache. TTLs are durations such as 10m,
+// responses that never change are cached regardless of them.
+type ConfigCache struct {
+	Disabled bool `yaml:"disabled,omitempty"`
+	// TTL applies to endpoints without their own entry in Endpoints. The
+	// default of zero only caches responses that never change.
+	TTL       string            `yaml:"ttl,omitempty"`
+	Endpoints map[string]string `yaml:"endpoints,omitempty"`
 }
 
-func NewConfig(path string) *Config {
+func NewConfig(path, home string) *Config {
 	config := &Config{
 		Path: path,
-		Endpoints: ConfigEndpoints{
-			Hiro:     hiro.DefaultApiBase,
-			Alex:     alex.DefaultApiBase,
-			StxTools: stxtools.DefaultApiBase,
-			Bob:      gobob.DefaultApiBase,
+		Home: home,
+		ConfigProfile: ConfigProfile{
+			Endpoints: ConfigEndpoints{
+				Hiro:     hiro.DefaultApiBase,
+				Alex:     alex.DefaultApiBase,
+				StxTools: stxtools.DefaultApiBase,
+				Bob:      gobob.DefaultApiBase,
+			},
 		},
 	}
 	return config
 }
 
+// Locate returns the config file to use. An explicit path, from --config or
+// TELLER_CONFIG, wins. Otherwise it is the first file that exists of
+// $XDG_CONFIG_HOME/teller/config.yaml, which defaults to ~/.config, and
+// ~/.teller.yaml, falling back to ~/.teller.yaml for a new config.
+func Locate(explicit, home string) string {
+	if explicit != "" {
+		return explicit
+	}
+	legacy := filepath.Join(home, ".teller.yaml")
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(home, ".config")
+	}
+	for _, path := range []string{filepath.Join(configHome, "teller", "config.yaml"), legacy} {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return legacy
+}
+
 func (c *Config) ReadConfig() error {
 	bytes, err := os.ReadFile(c.Path)
 	if err != nil {
@@ -48,34 +112,62 @@ func (c *Config) ReadConfig() error {
 		return err
 	}
 
+	if err := c.ConfigProfile.validate(); err != nil {
+		return err
+	}
+	if err := c.Portfolio.validate(); err != nil {
+		return err
+	}
+	for name, profile := range c.Profiles {
+		if profile == nil {
+			c.Profiles[name] = &ConfigProfile{}
+			continue
+		}
+		if err := profile.validate(); err != nil {
+			return fmt.Errorf("profile %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *ConfigProfile) validate() error {
+	if p.Network != "" {
+		if _, err := address.NetworkByName(p.Network); err != nil {
+			return err
+		}
+	}
 	// Ensure wallets are unique
 	walletMap := make(map[string]bool)
-	for _, wallet := range c.Wallets {
+	for _, wallet := range p.Wallets {
 		if _, exists := walletMap[wallet]; exists {
 			return fmt.Errorf("duplicate wallet found: %s", wallet)
 		}
 		walletMap[wallet] = true
 	}
-
 	return nil
 }
 
+// AddWallet adds wallet to the active profile.
 func (c *Config) AddWallet(wallet string) error {
+	profile := c.Active()
 	// Check for duplicate before adding
-	for _, w := range c.Wallets {
+	for _, w := range profile.Wallets {
 		if w == wallet {
 			return fmt.Errorf("duplicate wallet: %s", wallet)
 		}
 	}
 	// If not found, append the wallet
-	c.Wallets = append(c.Wallets, wallet)
+	profile.Wallets = append(profile.Wallets, wallet)
 	return nil
 }
 
+// RemoveWallet removes wallet from the active profile.
 func (c *Config) RemoveWallet(wallet string) {
-	for i, w := range c.Wallets {
+	profile := c.Active()
+	for i, w := range profile.Wallets {
 		if w == wallet {
-			c.Wallets = append(c.Wallets[:i], c.Wallets[i+1:]...)
+			profile.Wallets = append(profile.Wallets[:i], profile.Wallets[i+1:]...)
 			break
 		}
 	}
@@ -86,5 +178,104 @@ func (c *Config) WriteConfig() error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(c.Path, bytes, 0644)
+	// Keep inline credentials private to the user.
+	var perm os.FileMode = 0644
+	for _, auth := range c.Auth {
+		if auth.inline() {
+			perm = 0600
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.Path, bytes, perm); err != nil {
+		return err
+	}
+	return os.Chmod(c.Path, perm)
+}
+
+// SetAuth updates the credentials of endpoint with update, dropping the
+// entry if it ends up empty.
+func (c *Config) SetAuth(endpoint string, update func(*ConfigAuth)) error {
+	if err := ValidEndpoint(endpoint); err != nil {
+		return err
+	}
+	auth := c.Auth[endpoint]
+	update(&auth)
+	if auth.empty() {
+		delete(c.Auth, endpoint)
+		return nil
+	}
+	if c.Auth == nil {
+		c.Auth = make(map[string]ConfigAuth)
+	}
+	c.Auth[endpoint] = auth
+	return nil
+}
+
+// DataDir returns the directory teller keeps its local state in. It stays
+// ~/.teller wherever the config file is, so the keystore does not move with
+// it.
+func (c *Config) DataDir() string {
+	return filepath.Join(c.Home, ".teller")
+}
+
+// KeystoreDir returns the directory of the encrypted keystore.
+func (c *Config) KeystoreDir() string {
+	return filepath.Join(c.DataDir(), "keystore")
+}
+
+// CacheDir returns the directory of the response cache.
+func (c *Config) CacheDir() string {
+	return filepath.Join(c.DataDir(), "cache")
+}
+
+// HistoryDir returns the directory of the balance snapshots of wallet
+// history.
+func (c *Config) HistoryDir() string {
+	return filepath.Join(c.DataDir(), "history")
+}
+
+// TxStorePath returns the transaction database of network.
+func (c *Config) TxStorePath(network string) string {
+	return filepath.Join(c.DataDir(), "transactions", network+".db")
+}
+
+// TransportOptions returns the transport options of the named endpoint:
+// hiro, ord, alex, stxtools or bob.
+func (c *Config) TransportOptions(endpoint string) (transport.Options, error) {
+	opts := transport.Options{
+		UserAgent:         c.HTTP.UserAgent,
+		MaxRetries:        c.HTTP.MaxRetries,
+		RequestsPerSecond: c.HTTP.RequestsPerSecond,
+		Burst:             c.HTTP.Burst,
+	}
+	if c.HTTP.Timeout != "" {
+		timeout, err := time.ParseDuration(c.HTTP.Timeout)
+		if err != nil {
+			return opts, fmt.Errorf("invalid http timeout %q: %w", c.HTTP.Timeout, err)
+		}
+		opts.Timeout = timeout
+	}
+	ttl := c.Cache.TTL
+	if endpointTTL, ok := c.Cache.Endpoints[endpoint]; ok {
+		ttl = endpointTTL
+	}
+	if ttl != "" {
+		cacheTTL, err := time.ParseDuration(ttl)
+		if err != nil {
+			return opts, fmt.Errorf("invalid cache ttl %q: %w", ttl, err)
+		}
+		opts.CacheTTL = cacheTTL
+	}
+	if auth, ok := c.Auth[endpoint]; ok {
+		opts.Credentials = func() (http.Header, error) {
+			header, err := auth.Header()
+			if err != nil {
+				return nil, fmt.Errorf("%s auth: %w", endpoint, err)
+			}
+			return header, nil
+		}
+	}
+	return opts, nil
 }
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PriceSourceNames are the price sources of the portfolio, in their default
+// order.
+var PriceSourceNames = []string{"alex", "stxtools"}
+
+// ConfigPortfolio configures the valuation of wallet portfolio.
+type ConfigPortfolio struct {
+	// PriceSources are tried in order for the price of each token, the
+	// default is PriceSourceNames.
+	PriceSources []string `yaml:"price_sources,omitempty"`
+}
+
+// ValidPriceSource returns an error unless name is one of PriceSourceNames.
+func ValidPriceSource(name string) error {
+	for _, n := range PriceSourceNames {
+		if n == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown price source %q, expected one of %s", name, strings.Join(PriceSourceNames, ", "))
+}
+
+// Sources returns the configured price sources, or the default ones.
+func (p ConfigPortfolio) Sources() []string {
+	if len(p.PriceSources) == 0 {
+		return PriceSourceNames
+	}
+	return p.PriceSources
+}
+
+func (p ConfigPortfolio) validate() error {
+	for _, name := range p.PriceSources {
+		if err := ValidPriceSource(name); err != nil {
+			return fmt.Errorf("portfolio: %w", err)
+		}
+	}
+	return nil
+}
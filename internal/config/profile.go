@@ -0,0 +1,191 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashhavoc/teller/pkg/api/hiro"
+)
+
+// ConfigProfile is a named set of endpoints and wallets, such as mainnet,
+// testnet or devnet. Endpoints a profile leaves empty fall back to the top
+// level ones, wallets do not.
+type ConfigProfile struct {
+	// Network is mainnet, testnet or devnet. It defaults to the name of a
+	// profile with one of these names.
+	Network   string          `yaml:"network,omitempty"`
+	Endpoints ConfigEndpoints `yaml:"endpoints,omitempty"`
+	Wallets   []string        `yaml:"wallets,omitempty"`
+}
+
+// UseProfile makes name the active profile, or the top level settings if
+// name is empty.
+func (c *Config) UseProfile(name string) error {
+	if name != "" && c.Profiles[name] == nil {
+		if len(c.Profiles) == 0 {
+			return fmt.Errorf("unknown profile %q, no profiles are configured", name)
+		}
+		return fmt.Errorf("unknown profile %q, expected one of %s", name, strings.Join(c.ProfileNames(), ", "))
+	}
+	c.active = name
+	return nil
+}
+
+// ActiveProfile returns the name of the active profile, empty for the top
+// level settings.
+func (c *Config) ActiveProfile() string {
+	return c.active
+}
+
+// Active returns the endpoints and wallets of the active profile, for
+// commands to read or change.
+func (c *Config) Active() *ConfigProfile {
+	if c.active == "" {
+		return &c.ConfigProfile
+	}
+	return c.Profiles[c.active]
+}
+
+// NetworkName returns the network of the active profile: mainnet, testnet
+// or devnet. Profiles without one use the top level network.
+func (c *Config) NetworkName() string {
+	return c.ProfileNetworkName(c.active)
+}
+
+// ProfileNetworkName returns the network of the named profile, or of the
+// top level settings if name is empty.
+func (c *Config) ProfileNetworkName(name string) string {
+	if profile := c.Profiles[name]; name != "" && profile != nil && profile.Network != "" {
+		return profile.Network
+	}
+	switch name {
+	case "mainnet", "testnet", "devnet":
+		return name
+	}
+	return c.topNetwork()
+}
+
+// ProfileNames returns the names of the profiles in order.
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AddProfile adds an empty profile.
+func (c *Config) AddProfile(name string) error {
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return fmt.Errorf("invalid profile name %q", name)
+	}
+	if c.Profiles[name] != nil {
+		return fmt.Errorf("profile %s already exists", name)
+	}
+	if c.Profiles == nil {
+		c.Profiles = make(map[string]*ConfigProfile)
+	}
+	c.Profiles[name] = &ConfigProfile{}
+	return nil
+}
+
+// RemoveProfile removes a profile, and unsets it as the default.
+func (c *Config) RemoveProfile(name string) error {
+	if c.Profiles[name] == nil {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+	delete(c.Profiles, name)
+	if c.Profile == name {
+		c.Profile = ""
+	}
+	return nil
+}
+
+// hiroAPIs are the Hiro APIs of each network.
+var hiroAPIs = map[string]string{
+	"mainnet": hiro.DefaultApiBase,
+	"testnet": hiro.TestnetApiBase,
+	"devnet":  hiro.DevnetApiBase,
+}
+
+// EndpointURL returns the base URL of the named endpoint. A
+// TELLER_ENDPOINT_<NAME> environment variable, such as TELLER_ENDPOINT_HIRO,
+// overrides the active profile, which overrides the top level endpoints.
+// The top level Hiro endpoint serves the top level network, so a profile
+// on another network without its own uses the Hiro API of its network, as
+// does a testnet or devnet left with the default mainnet API.
+func (c *Config) EndpointURL(name string) string {
+	return c.ProfileEndpointURL(c.active, name)
+}
+
+// ProfileEndpointURL returns the base URL of the named endpoint for a
+// profile, or for the top level settings if profile is empty, resolved as
+// EndpointURL does for the active profile.
+func (c *Config) ProfileEndpointURL(profile, name string) string {
+	if url := os.Getenv(EndpointEnv(name)); url != "" {
+		return url
+	}
+	if p := c.Profiles[profile]; profile != "" && p != nil {
+		if url := *p.Endpoints.field(name); url != "" {
+			return url
+		}
+	}
+	url := *c.Endpoints.field(name)
+	if name == "hiro" {
+		network := c.ProfileNetworkName(profile)
+		if network != c.topNetwork() || url == "" || (network != "mainnet" && url == hiro.DefaultApiBase) {
+			return hiroAPIs[network]
+		}
+	}
+	return url
+}
+
+// ExplorerURL returns the URL of path, such as txid/0x..., on the Hiro
+// explorer for the network of the active profile. Devnets are shown by
+// pointing the explorer at their API.
+func (c *Config) ExplorerURL(path string) string {
+	u := "https://explorer.hiro.so/" + path
+	switch c.NetworkName() {
+	case "testnet":
+		u += "?chain=testnet"
+	case "devnet":
+		u += "?chain=testnet&api=" + url.QueryEscape(c.EndpointURL("hiro"))
+	}
+	return u
+}
+
+// topNetwork returns the network of the top level settings.
+func (c *Config) topNetwork() string {
+	if c.ConfigProfile.Network != "" {
+		return c.ConfigProfile.Network
+	}
+	return "mainnet"
+}
+
+// EndpointEnv returns the environment variable overriding the URL of the
+// named endpoint.
+func EndpointEnv(name string) string {
+	return "TELLER_ENDPOINT_" + strings.ToUpper(name)
+}
+
+// field returns the URL of the named endpoint, one of EndpointNames.
+func (e *ConfigEndpoints) field(name string) *string {
+	switch name {
+	case "hiro":
+		return &e.Hiro
+	case "ord":
+		return &e.Ord
+	case "alex":
+		return &e.Alex
+	case "stxtools":
+		return &e.StxTools
+	case "bob":
+		return &e.Bob
+	default:
+		panic("unknown endpoint " + name)
+	}
+}
@@ -1,32 +1,41 @@
 package alex
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
+
+	"github.com/hashhavoc/teller/pkg/api/transport"
 )
 
 const DefaultApiBase = "https://api.alexgo.io"
 const DefaultGraphQLEndpoint = "https://gql.alexlab.co/v1/graphql"
 
+// APIClient wraps the API. Each method has a variant suffixed with Context
+// whose context cancels its requests, the plain method uses
+// context.Background.
 type APIClient struct {
 	BaseURL string
-	Client  *http.Client
+	Client  *transport.Client
 }
 
-func NewAPIClient(baseURL string) *APIClient {
+func NewAPIClient(baseURL string, opts transport.Options) *APIClient {
 	return &APIClient{
 		BaseURL: baseURL,
-		Client:  &http.Client{},
+		Client:  transport.New(opts),
 	}
 }
 
 func (c *APIClient) FetchLatestPrices() (TokenPriceResponse, error) {
+	return c.FetchLatestPricesContext(context.Background())
+}
+
+func (c *APIClient) FetchLatestPricesContext(ctx context.Context) (TokenPriceResponse, error) {
 	const query = `{"query":"query FetchLatestPrices { laplace_current_token_price { avg_price_usd token } }"}`
 
-	body, err := c.ExecuteGraphQLQuery(query)
+	body, err := c.ExecuteGraphQLQueryContext(ctx, query)
 	if err != nil {
 		return TokenPriceResponse{}, err
 	}
@@ -41,63 +50,33 @@ func (c *APIClient) FetchLatestPrices() (TokenPriceResponse, error) {
 }
 
 func (c *APIClient) ExecuteGraphQLQuery(query string) ([]byte, error) {
-	payload := strings.NewReader(query)
+	return c.ExecuteGraphQLQueryContext(context.Background(), query)
+}
 
-	req, err := http.NewRequest("POST", DefaultGraphQLEndpoint, payload)
+func (c *APIClient) ExecuteGraphQLQueryContext(ctx context.Context, query string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", DefaultGraphQLEndpoint, strings.NewReader(query))
 	if err != nil {
 		return nil, err
 	}
-
 	req.Header.Add("Content-Type", "application/json")
 
-	res, err := c.Client.Do(req)
+	body, err := c.Client.Fetch(req)
 	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
-
-	if res.StatusCode != 200 {
-		return nil, fmt.Errorf("failed to make graphql request: %s", res.Status)
+		return nil, fmt.Errorf("failed to make graphql request: %w", err)
 	}
-
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
-
 	return body, nil
 }
 
 func (c *APIClient) GetPairs() ([]CurrencyPair, error) {
-	url := fmt.Sprintf("%s/v2/coin-gecko/tickers", c.BaseURL)
-
-	req, err := http.NewRequest("GET", url, nil)
-
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Add("Accept", "application/json")
-
-	res, err := c.Client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
-
-	if res.StatusCode != 200 {
-		return nil, fmt.Errorf("failed to get pairs: %s", res.Status)
-	}
+	return c.GetPairsContext(context.Background())
+}
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
+func (c *APIClient) GetPairsContext(ctx context.Context) ([]CurrencyPair, error) {
+	url := fmt.Sprintf("%s/v2/coin-gecko/tickers", c.BaseURL)
 
 	var response []CurrencyPair
-	err = json.Unmarshal(body, &response)
-	if err != nil {
-		return nil, err
+	if err := c.Client.GetJSON(ctx, url, &response); err != nil {
+		return nil, fmt.Errorf("failed to get pairs: %w", err)
 	}
-
 	return response, nil
 }
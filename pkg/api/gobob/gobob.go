@@ -1,124 +1,82 @@
 package gobob
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"net/http"
 
 	"github.com/google/go-querystring/query"
+	"github.com/hashhavoc/teller/pkg/api/paginate"
+	"github.com/hashhavoc/teller/pkg/api/transport"
 )
 
 const DefaultApiBase = "https://explorer.gobob.xyz"
 
+// APIClient wraps the API. Each method has a variant suffixed with Context
+// whose context cancels its requests, the plain method uses
+// context.Background.
 type APIClient struct {
 	BaseURL string
-	Client  *http.Client
+	Client  *transport.Client
 }
 
-func NewAPIClient(baseURL string) *APIClient {
+func NewAPIClient(baseURL string, opts transport.Options) *APIClient {
 	return &APIClient{
 		BaseURL: baseURL,
-		Client:  &http.Client{},
+		Client:  transport.New(opts),
 	}
 }
 
 func (c *APIClient) GetAllTokens() ([]TokenItems, error) {
-	var allResults []TokenItems
-	var params NextPageParams
-
-	for {
-		var url string
-		if params == (NextPageParams{}) {
-			url = fmt.Sprintf("%s/api/v2/tokens", c.BaseURL)
-		} else {
-			v, _ := query.Values(params)
-			url = fmt.Sprintf("%s/api/v2/tokens?%s", c.BaseURL, v.Encode())
-		}
-
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return nil, err
-		}
-		req.Header.Add("Accept", "application/json")
-
-		res, err := c.Client.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer res.Body.Close()
-
-		if res.StatusCode != 200 {
-			return nil, fmt.Errorf("failed to get all tokens: %s", res.Status)
-		}
-
-		body, err := io.ReadAll(res.Body)
-		if err != nil {
-			return nil, err
-		}
+	return c.GetAllTokensContext(context.Background())
+}
 
-		var response TokenResponse
-		err = json.Unmarshal(body, &response)
-		if err != nil {
-			return nil, err
-		}
-		allResults = append(allResults, response.Items...)
-		params = response.NextPageParams
+func (c *APIClient) GetAllTokensContext(ctx context.Context) ([]TokenItems, error) {
+	return c.TokensPaginator().All(ctx)
+}
 
-		if response.NextPageParams == (NextPageParams{}) {
-			break
-		}
+// TokensPaginator pages through the tokens, following the next page
+// parameters of each response.
+func (c *APIClient) TokensPaginator() *paginate.Cursor[TokenItems, NextPageParams] {
+	return &paginate.Cursor[TokenItems, NextPageParams]{
+		Fetch: func(ctx context.Context, params NextPageParams) ([]TokenItems, NextPageParams, error) {
+			url := fmt.Sprintf("%s/api/v2/tokens", c.BaseURL)
+			if params != (NextPageParams{}) {
+				v, _ := query.Values(params)
+				url = fmt.Sprintf("%s/api/v2/tokens?%s", c.BaseURL, v.Encode())
+			}
+
+			var response TokenResponse
+			if err := c.Client.GetJSON(ctx, url, &response); err != nil {
+				return nil, NextPageParams{}, fmt.Errorf("failed to get all tokens: %w", err)
+			}
+			return response.Items, response.NextPageParams, nil
+		},
 	}
-
-	return allResults, nil
 }
 
 func (c *APIClient) GetTokenHolders(contractId string) ([]TokenHolderItem, error) {
-	var allResults []TokenHolderItem
-	var params TokenHoldersNextPageParams
-
-	for {
-		var url string
-		if params == (TokenHoldersNextPageParams{}) {
-			url = fmt.Sprintf("%s/api/v2/tokens/%s/holders", c.BaseURL, contractId)
-		} else {
-			v, _ := query.Values(params)
-			url = fmt.Sprintf("%s/api/v2/tokens/%s/holders?%s", c.BaseURL, contractId, v.Encode())
-		}
-
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return nil, err
-		}
-		req.Header.Add("Accept", "application/json")
-
-		res, err := c.Client.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer res.Body.Close()
-
-		if res.StatusCode != 200 {
-			return nil, fmt.Errorf("failed to get token holders: %s", res.Status)
-		}
-
-		body, err := io.ReadAll(res.Body)
-		if err != nil {
-			return nil, err
-		}
+	return c.GetTokenHoldersContext(context.Background(), contractId)
+}
 
-		var response TokenHoldersResponse
-		err = json.Unmarshal(body, &response)
-		if err != nil {
-			return nil, err
-		}
-		allResults = append(allResults, response.Items...)
-		params = response.NextPageParams
+func (c *APIClient) GetTokenHoldersContext(ctx context.Context, contractId string) ([]TokenHolderItem, error) {
+	return c.TokenHoldersPaginator(contractId).All(ctx)
+}
 
-		if response.NextPageParams == (TokenHoldersNextPageParams{}) {
-			break
-		}
+// TokenHoldersPaginator pages through the holders of a token.
+func (c *APIClient) TokenHoldersPaginator(contractId string) *paginate.Cursor[TokenHolderItem, TokenHoldersNextPageParams] {
+	return &paginate.Cursor[TokenHolderItem, TokenHoldersNextPageParams]{
+		Fetch: func(ctx context.Context, params TokenHoldersNextPageParams) ([]TokenHolderItem, TokenHoldersNextPageParams, error) {
+			url := fmt.Sprintf("%s/api/v2/tokens/%s/holders", c.BaseURL, contractId)
+			if params != (TokenHoldersNextPageParams{}) {
+				v, _ := query.Values(params)
+				url = fmt.Sprintf("%s/api/v2/tokens/%s/holders?%s", c.BaseURL, contractId, v.Encode())
+			}
+
+			var response TokenHoldersResponse
+			if err := c.Client.GetJSON(ctx, url, &response); err != nil {
+				return nil, TokenHoldersNextPageParams{}, fmt.Errorf("failed to get token holders: %w", err)
+			}
+			return response.Items, response.NextPageParams, nil
+		},
 	}
-
-	return allResults, nil
 }
@@ -0,0 +1,55 @@
+package hiro
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+func (c *APIClient) GetBlockByHeight(height int) (Block, error) {
+	return c.GetBlockByHeightContext(context.Background(), height)
+}
+
+func (c *APIClient) GetBlockByHeightContext(ctx context.Context, height int) (Block, error) {
+	url := fmt.Sprintf("%s/extended/v2/blocks/%d", c.BaseURL, height)
+
+	var response Block
+	if err := c.Client.GetJSON(ctx, url, &response); err != nil {
+		return Block{}, fmt.Errorf("failed to get block %d: %w", height, err)
+	}
+	return response, nil
+}
+
+func (c *APIClient) GetBlockByHash(hash string) (Block, error) {
+	return c.GetBlockByHashContext(context.Background(), hash)
+}
+
+// GetBlockByHashContext returns the block with the given block hash or
+// index block hash.
+func (c *APIClient) GetBlockByHashContext(ctx context.Context, hash string) (Block, error) {
+	url := fmt.Sprintf("%s/extended/v2/blocks/%s", c.BaseURL, hash)
+
+	var response Block
+	if err := c.Client.GetJSON(ctx, url, &response); err != nil {
+		return Block{}, fmt.Errorf("failed to get block %s: %w", hash, err)
+	}
+	return response, nil
+}
+
+func (c *APIClient) GetLatestBlock() (Block, error) {
+	return c.GetLatestBlockContext(context.Background())
+}
+
+// GetLatestBlockContext returns the block at the tip of the chain.
+func (c *APIClient) GetLatestBlockContext(ctx context.Context) (Block, error) {
+	url := fmt.Sprintf("%s/extended/v2/blocks?limit=1", c.BaseURL)
+
+	var response BlocksResponse
+	if err := c.Client.GetJSON(ctx, url, &response); err != nil {
+		return Block{}, fmt.Errorf("failed to get the latest block: %w", err)
+	}
+	if len(response.Results) == 0 {
+		return Block{}, errors.New("failed to get the latest block: no blocks")
+	}
+	return response.Results[0], nil
+}
@@ -1,89 +1,70 @@
 package hiro
 
 import (
-	"bytes"
-	"encoding/hex"
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"strings"
 
-	"github.com/hashhavoc/teller/pkg/utils/uint128"
+	"github.com/hashhavoc/teller/pkg/clarity"
 )
 
 func (c *APIClient) GetContractDetails(contractID string) (ContractDetailsResponse, error) {
-	url := fmt.Sprintf("%s/extended/v1/contract/%s", c.BaseURL, contractID)
-	method := "GET"
-
-	req, err := http.NewRequest(method, url, nil)
-	if err != nil {
-		return ContractDetailsResponse{}, err
-	}
-
-	req.Header.Add("Accept", "application/json")
-
-	res, err := c.Client.Do(req)
-	if err != nil {
-		return ContractDetailsResponse{}, err
-	}
-	defer res.Body.Close()
-
-	if res.StatusCode != 200 {
-		return ContractDetailsResponse{}, fmt.Errorf("failed to get contract details: %s", res.Status)
-	}
+	return c.GetContractDetailsContext(context.Background(), contractID)
+}
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return ContractDetailsResponse{}, err
-	}
+func (c *APIClient) GetContractDetailsContext(ctx context.Context, contractID string) (ContractDetailsResponse, error) {
+	url := fmt.Sprintf("%s/extended/v1/contract/%s", c.BaseURL, contractID)
 
 	var response ContractDetailsResponse
-	err = json.Unmarshal(body, &response)
-	if err != nil {
-		return ContractDetailsResponse{}, err
+	if err := c.Client.GetJSON(ctx, url, &response); err != nil {
+		return ContractDetailsResponse{}, fmt.Errorf("failed to get contract details: %w", err)
 	}
-
 	return response, nil
 }
 
-func (c *APIClient) GetContractSource(id string) (string, error) {
-	split, err := ContractValidateSplit(id)
-	if err != nil {
-		return "", err
+// Interface parses the ABI attached to the contract details. Implemented
+// traits are read from the contract source since the ABI omits them.
+func (r ContractDetailsResponse) Interface() (clarity.ContractInterface, error) {
+	if r.ABI == "" {
+		return clarity.ContractInterface{}, fmt.Errorf("contract %s has no abi", r.ContractID)
 	}
-	url := fmt.Sprintf("%s/v2/contracts/source/%s/%s", c.BaseURL, split[0], split[1])
-	method := "GET"
-
-	req, err := http.NewRequest(method, url, nil)
-
+	abi, err := clarity.ParseContractInterface([]byte(r.ABI))
 	if err != nil {
-		return "", err
+		return clarity.ContractInterface{}, err
 	}
-	req.Header.Add("Accept", "application/json")
+	abi.ImplementedTraits = clarity.ParseImplementedTraits(r.SourceCode)
+	return abi, nil
+}
+
+// GetContractInterface fetches and parses the ABI of a contract.
+func (c *APIClient) GetContractInterface(id string) (clarity.ContractInterface, error) {
+	return c.GetContractInterfaceContext(context.Background(), id)
+}
 
-	res, err := c.Client.Do(req)
+func (c *APIClient) GetContractInterfaceContext(ctx context.Context, id string) (clarity.ContractInterface, error) {
+	details, err := c.GetContractDetailsContext(ctx, id)
 	if err != nil {
-		return "", err
+		return clarity.ContractInterface{}, err
 	}
-	defer res.Body.Close()
+	return details.Interface()
+}
 
-	if res.StatusCode != 200 {
-		return "", fmt.Errorf("failed to get contract source: %s", res.Status)
-	}
+func (c *APIClient) GetContractSource(id string) (string, error) {
+	return c.GetContractSourceContext(context.Background(), id)
+}
 
-	body, err := io.ReadAll(res.Body)
+func (c *APIClient) GetContractSourceContext(ctx context.Context, id string) (string, error) {
+	split, err := ContractValidateSplit(id)
 	if err != nil {
 		return "", err
 	}
+	url := fmt.Sprintf("%s/v2/contracts/source/%s/%s", c.BaseURL, split[0], split[1])
 
 	var response ContractSourceResponse
-	err = json.Unmarshal(body, &response)
-	if err != nil {
-		return "", err
+	if err := c.Client.GetJSON(ctx, url, &response); err != nil {
+		return "", fmt.Errorf("failed to get contract source: %w", err)
 	}
-
 	return response.Source, nil
 }
 
@@ -98,88 +79,44 @@ func ContractValidateSplit(c string) ([]string, error) {
 	return split, nil
 }
 
-func (c *APIClient) GetContractReadOnly(id string, function string, responseType string, arguments []string) (string, error) {
-	split, err := ContractValidateSplit(id)
-	if err != nil {
-		return "", err
-	}
-	url := fmt.Sprintf("%s/v2/contracts/call-read/%s/%s/%s", c.BaseURL, split[0], split[1], function)
-	method := "POST"
-
-	rawPayload := ReadOnlyPayload{
-		// random address
-		Sender:    "SP3D49HARD6Y36MKPT3PKP2YHG0ZNQMK0YP70RZHS",
-		Arguments: arguments,
-	}
+// DefaultReadOnlySender is used as tx-sender for read-only calls when the
+// caller does not provide one. It is an arbitrary mainnet address.
+const DefaultReadOnlySender = "SP3D49HARD6Y36MKPT3PKP2YHG0ZNQMK0YP70RZHS"
 
-	jsonPayload, err := json.Marshal(rawPayload)
-	if err != nil {
-		return "", err
-	}
+// GetContractReadOnly calls a read-only function as sender and decodes the
+// returned Clarity value. Arguments must already be hex encoded Clarity
+// values. An empty sender falls back to DefaultReadOnlySender.
+func (c *APIClient) GetContractReadOnly(id string, function string, sender string, arguments []string) (clarity.Value, error) {
+	return c.GetContractReadOnlyContext(context.Background(), id, function, sender, arguments)
+}
 
-	req, err := http.NewRequest(method, url, bytes.NewBuffer(jsonPayload))
+func (c *APIClient) GetContractReadOnlyContext(ctx context.Context, id string, function string, sender string, arguments []string) (clarity.Value, error) {
+	split, err := ContractValidateSplit(id)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	url := fmt.Sprintf("%s/v2/contracts/call-read/%s/%s/%s", c.BaseURL, split[0], split[1], function)
 
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("Accept", "application/json")
-
-	res, err := c.Client.Do(req)
-	if err != nil {
-		return "", err
+	if sender == "" {
+		sender = DefaultReadOnlySender
 	}
-	defer res.Body.Close()
-
-	if res.StatusCode != 200 {
-		return "", fmt.Errorf("failed to get contract source: %s", res.Status)
+	if arguments == nil {
+		arguments = []string{}
 	}
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return "", err
+	payload := ReadOnlyPayload{
+		Sender:    sender,
+		Arguments: arguments,
 	}
 
 	var response ReadOnlyResponse
-	err = json.Unmarshal(body, &response)
-	if err != nil {
-		return "", err
+	if err := c.Client.PostJSON(ctx, url, payload, &response); err != nil {
+		return nil, fmt.Errorf("failed to call read-only function: %w", err)
 	}
 
-	switch t := responseType; t {
-	case "string":
-		if len(response.Result) < 14 {
-			return "", fmt.Errorf("invalid response: %s", response.Result)
-		}
-		decoded, err := hex.DecodeString(response.Result[14:])
-		if err != nil {
-			return "", err
-		}
-		switch tt := function; tt {
-		case "get-token-uri":
-			return string(decoded)[1:], nil
-		default:
-			return string(decoded), nil
-		}
-	case "uint128":
-		if len(response.Result) < 6 {
-			return "", fmt.Errorf("invalid response: %s", response.Result)
-		}
-		u, err := uint128.FromString(response.Result[6:])
-		if err != nil {
-			return "", err
-		}
-		s := u.String()
-		return s, nil
-
-	default:
-		if len(response.Result) < 14 {
-			return "", fmt.Errorf("invalid response: %s", response.Result)
-		}
-		decoded, err := hex.DecodeString(response.Result[14:])
-		if err != nil {
-			return "", err
-		}
-		return string(decoded), nil
+	if !response.Okay {
+		return nil, fmt.Errorf("read-only call to %s failed: %s", function, response.Cause)
 	}
+
+	return clarity.DecodeHex(response.Result)
 }
@@ -1,19 +1,116 @@
 package hiro
 
 import (
+	"context"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashhavoc/teller/pkg/api/transport"
+)
+
+const (
+	DefaultApiBase = "https://api.hiro.so"
+	TestnetApiBase = "https://api.testnet.hiro.so"
+	// DevnetApiBase is the API of a local devnet started by Clarinet.
+	DevnetApiBase = "http://localhost:3999"
 )
 
-const DefaultApiBase = "https://api.hiro.so"
+// Confirmations is how far below the chain tip a block must be before
+// queries pinned to it are cached forever. Blocks closer to the tip may
+// still be replaced by a fork.
+const Confirmations = 6
 
+// APIClient wraps the API. Each method has a variant suffixed with Context
+// whose context cancels its requests, the plain method uses
+// context.Background.
 type APIClient struct {
 	BaseURL string
-	Client  *http.Client
+	Client  *transport.Client
+
+	// mu guards the chain tip and block heights looked up by Cacheable.
+	mu      sync.Mutex
+	tip     int
+	heights map[string]int
 }
 
-func NewAPIClient(baseURL string) *APIClient {
-	return &APIClient{
+func NewAPIClient(baseURL string, opts transport.Options) *APIClient {
+	c := &APIClient{
 		BaseURL: baseURL,
-		Client:  &http.Client{},
+		heights: make(map[string]int),
+	}
+	if opts.Cacheable == nil {
+		opts.Cacheable = c.Cacheable
+	}
+	c.Client = transport.New(opts)
+	return c
+}
+
+// Cacheable is the cache policy of the Hiro API. Nonces, fees,
+// transactions and blocks change as blocks are mined and are never cached,
+// nor are broadcasts, so the chain tip is always fetched live. Contract sources never change. Queries pinned to a block with
+// until_block or tip never change once the block is confirmed, so they are
+// only immutable when the block is at least Confirmations blocks below the
+// chain tip. A tip of latest follows the chain and is not pinned.
+func (c *APIClient) Cacheable(req *http.Request) (cacheable, immutable bool) {
+	path := req.URL.Path
+	switch {
+	case req.Method == http.MethodPost:
+		cacheable = strings.HasPrefix(path, "/v2/contracts/call-read/")
+	case req.Method != http.MethodGet,
+		strings.HasSuffix(path, "/nonces"),
+		strings.HasPrefix(path, "/v2/fees/"),
+		strings.HasPrefix(path, "/extended/v1/tx/"),
+		strings.HasPrefix(path, "/extended/v2/blocks"),
+		strings.HasSuffix(path, "/transactions"):
+		return false, false
+	default:
+		cacheable = true
+	}
+	if !cacheable {
+		return false, false
+	}
+	if strings.HasPrefix(path, "/v2/contracts/source/") {
+		return true, true
+	}
+	query := req.URL.Query()
+	block := query.Get("until_block")
+	if block == "" {
+		block = query.Get("tip")
+	}
+	return true, block != "" && c.confirmed(req.Context(), block)
+}
+
+// confirmed reports whether block, a height or an index block hash, is
+// Confirmations blocks below the chain tip. The tip is looked up again only
+// for blocks too close to the last one seen, which errs on the side of not
+// caching since the tip only grows.
+func (c *APIClient) confirmed(ctx context.Context, block string) bool {
+	if block == "latest" {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	height, err := strconv.Atoi(block)
+	if err != nil {
+		var ok bool
+		if height, ok = c.heights[block]; !ok {
+			b, err := c.GetBlockByHashContext(ctx, block)
+			if err != nil || !b.Canonical {
+				return false
+			}
+			height = b.Height
+			c.heights[block] = height
+		}
+	}
+	if height+Confirmations > c.tip {
+		latest, err := c.GetLatestBlockContext(ctx)
+		if err != nil {
+			return false
+		}
+		c.tip = latest.Height
 	}
+	return height >= 0 && height+Confirmations <= c.tip
 }
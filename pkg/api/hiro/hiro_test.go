@@ -0,0 +1,224 @@
+package hiro
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashhavoc/teller/pkg/api/transport"
+)
+
+const (
+	confirmedHash = "0x1111111111111111111111111111111111111111111111111111111111111111"
+	recentHash    = "0x2222222222222222222222222222222222222222222222222222222222222222"
+	orphanedHash  = "0x3333333333333333333333333333333333333333333333333333333333333333"
+)
+
+// chain serves the blocks endpoints of a chain whose tip is at the height
+// stored in tip, counting the requests for the tip.
+func chain(t *testing.T, tip *atomic.Int32, tipLookups *atomic.Int32) *APIClient {
+	t.Helper()
+	blocks := map[string]Block{
+		confirmedHash: {Height: 50, Canonical: true},
+		recentHash:    {Height: 98, Canonical: true},
+		orphanedHash:  {Height: 40, Canonical: false},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/extended/v2/blocks":
+			tipLookups.Add(1)
+			fmt.Fprintf(w, `{"limit":1,"total":%d,"results":[{"height":%d,"canonical":true}]}`, tip.Load(), tip.Load())
+		case strings.HasPrefix(r.URL.Path, "/extended/v2/blocks/"):
+			b, ok := blocks[strings.TrimPrefix(r.URL.Path, "/extended/v2/blocks/")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprintf(w, `{"height":%d,"canonical":%t}`, b.Height, b.Canonical)
+		default:
+			w.Write([]byte("{}"))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return NewAPIClient(srv.URL, transport.Options{RequestsPerSecond: -1})
+}
+
+func request(t *testing.T, c *APIClient, method, path string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequestWithContext(context.Background(), method, c.BaseURL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func TestCacheable(t *testing.T) {
+	var tip, lookups atomic.Int32
+	tip.Store(100)
+	c := chain(t, &tip, &lookups)
+
+	for _, tt := range []struct {
+		method, path         string
+		cacheable, immutable bool
+	}{
+		{"GET", "/extended/v1/address/SP000000000000000000002Q6VF78/balances", true, false},
+		{"GET", "/extended/v1/address/SP000000000000000000002Q6VF78/nonces", false, false},
+		{"GET", "/extended/v2/addresses/SP000000000000000000002Q6VF78/transactions", false, false},
+		{"GET", "/extended/v1/tx/0xabcd", false, false},
+		{"GET", "/v2/fees/transfer", false, false},
+		{"GET", "/extended/v2/blocks?limit=1", false, false},
+		{"GET", "/extended/v2/blocks/150000", false, false},
+		{"GET", "/extended/v2/blocks/" + confirmedHash, false, false},
+		{"POST", "/v2/transactions", false, false},
+		{"POST", "/v2/contracts/call-read/SP000000000000000000002Q6VF78/pox/get-info", true, false},
+		{"GET", "/v2/contracts/source/SP000000000000000000002Q6VF78/pox", true, true},
+
+		// Heights are pinned once they are Confirmations blocks deep.
+		{"GET", "/extended/v1/address/SP000000000000000000002Q6VF78/balances?until_block=50", true, true},
+		{"GET", "/extended/v1/address/SP000000000000000000002Q6VF78/balances?until_block=94", true, true},
+		{"GET", "/extended/v1/address/SP000000000000000000002Q6VF78/balances?until_block=95", true, false},
+		{"GET", "/extended/v1/address/SP000000000000000000002Q6VF78/balances?until_block=100", true, false},
+		{"GET", "/extended/v1/address/SP000000000000000000002Q6VF78/balances?until_block=1000", true, false},
+		{"GET", "/extended/v1/address/SP000000000000000000002Q6VF78/balances?until_block=-1", true, false},
+		{"GET", "/extended/v1/address/SP000000000000000000002Q6VF78/balances?until_block=soon", true, false},
+		{"POST", "/v2/contracts/call-read/SP000000000000000000002Q6VF78/pox/get-info?tip=latest", true, false},
+
+		// Index block hashes are looked up for their height.
+		{"POST", "/v2/contracts/call-read/SP000000000000000000002Q6VF78/pox/get-info?tip=" + confirmedHash, true, true},
+		{"POST", "/v2/contracts/call-read/SP000000000000000000002Q6VF78/pox/get-info?tip=" + recentHash, true, false},
+		{"POST", "/v2/contracts/call-read/SP000000000000000000002Q6VF78/pox/get-info?tip=" + orphanedHash, true, false},
+		{"GET", "/extended/v1/address/SP000000000000000000002Q6VF78/balances?until_block=" + confirmedHash, true, true},
+	} {
+		cacheable, immutable := c.Cacheable(request(t, c, tt.method, tt.path))
+		if cacheable != tt.cacheable || immutable != tt.immutable {
+			t.Errorf("%s %s = %v, %v, want %v, %v", tt.method, tt.path, cacheable, immutable, tt.cacheable, tt.immutable)
+		}
+	}
+}
+
+// The tip is looked up again only for blocks too recent for the last tip
+// seen, so a block becomes immutable once the chain moves past it.
+func TestCacheableTip(t *testing.T) {
+	var tip, lookups atomic.Int32
+	tip.Store(100)
+	c := chain(t, &tip, &lookups)
+	path := "/extended/v1/address/SP000000000000000000002Q6VF78/balances?until_block="
+
+	if _, immutable := c.Cacheable(request(t, c, "GET", path+"50")); !immutable || lookups.Load() != 1 {
+		t.Fatalf("immutable = %v after %d tip lookups", immutable, lookups.Load())
+	}
+	c.Cacheable(request(t, c, "GET", path+"60"))
+	c.Cacheable(request(t, c, "GET", path))
+	if lookups.Load() != 1 {
+		t.Errorf("tip looked up %d times for confirmed and unpinned queries, want once", lookups.Load())
+	}
+
+	if _, immutable := c.Cacheable(request(t, c, "GET", path+"100")); immutable {
+		t.Error("block at the tip is immutable")
+	}
+	tip.Store(120)
+	if _, immutable := c.Cacheable(request(t, c, "GET", path+"100")); !immutable {
+		t.Error("block 20 below the new tip is not immutable")
+	}
+}
+
+// A snapshot at the tip is cached for the ttl only, so it is fetched again
+// once the ttl expires rather than kept forever.
+func TestCacheTipSnapshot(t *testing.T) {
+	var balanceCalls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/extended/v2/blocks":
+			w.Write([]byte(`{"results":[{"height":100,"canonical":true}]}`))
+		default:
+			fmt.Fprintf(w, `{"stx":{"balance":"%d"}}`, balanceCalls.Add(1))
+		}
+	}))
+	defer srv.Close()
+
+	cache := transport.NewCache(t.TempDir())
+	c := NewAPIClient(srv.URL, transport.Options{RequestsPerSecond: -1, Cache: cache, CacheTTL: time.Nanosecond})
+	for _, height := range []int{100, 100, 50, 50} {
+		if _, err := c.GetAccountBalance("SP000000000000000000002Q6VF78", height); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if balanceCalls.Load() != 3 {
+		t.Errorf("%d balance requests, want 2 at the tip and 1 for the confirmed block", balanceCalls.Load())
+	}
+	stats, err := cache.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 1 || stats[0].Immutable != 1 {
+		t.Errorf("Stats = %+v, want only the confirmed block immutable", stats)
+	}
+}
+
+// The tip is fetched live even with a ttl, so that a new block is seen as
+// soon as it is mined.
+func TestCacheTip(t *testing.T) {
+	var tip, lookups atomic.Int32
+	tip.Store(100)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups.Add(1)
+		fmt.Fprintf(w, `{"results":[{"height":%d,"canonical":true}]}`, tip.Load())
+	}))
+	defer srv.Close()
+
+	c := NewAPIClient(srv.URL, transport.Options{RequestsPerSecond: -1, Cache: transport.NewCache(t.TempDir()), CacheTTL: time.Hour})
+	for _, want := range []int32{100, 101} {
+		tip.Store(want)
+		block, err := c.GetLatestBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if block.Height != int(want) {
+			t.Errorf("GetLatestBlock = %d, want %d", block.Height, want)
+		}
+	}
+	if lookups.Load() != 2 {
+		t.Errorf("%d tip requests, want 2", lookups.Load())
+	}
+}
+
+// Events are paged by offset until a short page, as the endpoint reports no
+// total.
+func TestGetTransactionEvents(t *testing.T) {
+	for _, total := range []int{0, 10, 50, 110} {
+		var offsets []string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			offsets = append(offsets, r.URL.Query().Get("offset"))
+			var events []string
+			for i := offset; i < total && i < offset+50; i++ {
+				events = append(events, fmt.Sprintf(`{"event_index":%d}`, i))
+			}
+			fmt.Fprintf(w, `{"limit":50,"offset":%d,"events":[%s]}`, offset, strings.Join(events, ","))
+		}))
+		c := NewAPIClient(srv.URL, transport.Options{RequestsPerSecond: -1})
+		events, err := c.GetTransactionEvents("0xabcd")
+		srv.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) != total {
+			t.Errorf("%d events: got %d", total, len(events))
+		}
+		for i, e := range events {
+			if e.EventIndex != i {
+				t.Errorf("%d events: event %d has index %d", total, i, e.EventIndex)
+				break
+			}
+		}
+		if want := total/50 + 1; len(offsets) != want {
+			t.Errorf("%d events: fetched offsets %v, want %d pages", total, offsets, want)
+		}
+	}
+}
@@ -1,150 +1,74 @@
 package hiro
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"net/http"
+
+	"github.com/hashhavoc/teller/pkg/api/paginate"
 )
 
 func (c *APIClient) GetAllNames() ([]Names, error) {
-	var allResults []Names
-	offset := 0
-	limit := 100000
-
-	for {
-		url := fmt.Sprintf("%s/v2/names?offset=%d&limit=%d", c.BaseURL, offset, limit)
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return nil, err
-		}
-		req.Header.Add("Accept", "application/json")
-
-		res, err := c.Client.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer res.Body.Close()
-
-		if res.StatusCode != 200 {
-			return nil, fmt.Errorf("failed to get contract source: %s", res.Status)
-		}
-
-		body, err := io.ReadAll(res.Body)
-		if err != nil {
-			return nil, err
-		}
-
-		var response NamesListResponse
-		err = json.Unmarshal(body, &response)
-		if err != nil {
-			return nil, err
-		}
-
-		allResults = append(allResults, response.Results...)
+	return c.GetAllNamesContext(context.Background())
+}
 
-		if len(allResults) >= response.Total {
-			break
-		}
+func (c *APIClient) GetAllNamesContext(ctx context.Context) ([]Names, error) {
+	return c.NamesPaginator().All(ctx)
+}
 
-		offset += limit
+// NamesPaginator pages through every BNS name.
+func (c *APIClient) NamesPaginator() *paginate.Offset[Names] {
+	limit := 100000
+	return &paginate.Offset[Names]{
+		PageSize: limit,
+		Fetch: func(ctx context.Context, index int) (paginate.Page[Names], error) {
+			url := fmt.Sprintf("%s/v2/names?offset=%d&limit=%d", c.BaseURL, index*limit, limit)
+			var response NamesListResponse
+			if err := c.Client.GetJSON(ctx, url, &response); err != nil {
+				return paginate.Page[Names]{}, fmt.Errorf("failed to get names: %w", err)
+			}
+			return paginate.Page[Names]{Items: response.Results, Total: response.Total}, nil
+		},
 	}
-
-	return allResults, nil
 }
 
 func (c *APIClient) GetName(name string) (NameDetails, error) {
-	url := fmt.Sprintf("%s/v1/names/%s", c.BaseURL, name)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return NameDetails{}, err
-	}
-	req.Header.Add("Accept", "application/json")
-
-	res, err := c.Client.Do(req)
-	if err != nil {
-		return NameDetails{}, err
-	}
-	defer res.Body.Close()
-
-	if res.StatusCode != 200 {
-		return NameDetails{}, fmt.Errorf("failed to fetch names: %s", res.Status)
-	}
+	return c.GetNameContext(context.Background(), name)
+}
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return NameDetails{}, err
-	}
+func (c *APIClient) GetNameContext(ctx context.Context, name string) (NameDetails, error) {
+	url := fmt.Sprintf("%s/v1/names/%s", c.BaseURL, name)
 
 	var response NameDetails
-	err = json.Unmarshal(body, &response)
-	if err != nil {
-		return NameDetails{}, err
+	if err := c.Client.GetJSON(ctx, url, &response); err != nil {
+		return NameDetails{}, fmt.Errorf("failed to fetch names: %w", err)
 	}
-
 	return response, nil
 }
 
 func (c *APIClient) GetNamesByAddress(address string) (NameReverseLookupResponse, error) {
-	url := fmt.Sprintf("%s/v1/addresses/stacks/%s", c.BaseURL, address)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return NameReverseLookupResponse{}, err
-	}
-	req.Header.Add("Accept", "application/json")
-
-	res, err := c.Client.Do(req)
-	if err != nil {
-		return NameReverseLookupResponse{}, err
-	}
-	defer res.Body.Close()
-
-	if res.StatusCode != 200 {
-		return NameReverseLookupResponse{}, fmt.Errorf("failed to fetch names: %s", res.Status)
-	}
+	return c.GetNamesByAddressContext(context.Background(), address)
+}
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return NameReverseLookupResponse{}, err
-	}
+func (c *APIClient) GetNamesByAddressContext(ctx context.Context, address string) (NameReverseLookupResponse, error) {
+	url := fmt.Sprintf("%s/v1/addresses/stacks/%s", c.BaseURL, address)
 
 	var response NameReverseLookupResponse
-	err = json.Unmarshal(body, &response)
-	if err != nil {
-		return NameReverseLookupResponse{}, err
+	if err := c.Client.GetJSON(ctx, url, &response); err != nil {
+		return NameReverseLookupResponse{}, fmt.Errorf("failed to fetch names: %w", err)
 	}
-
 	return response, nil
 }
 
 func (c *APIClient) GetNameZoneFile(name string) (NameZoneFileResponse, error) {
-	url := fmt.Sprintf("%s/v1/names/%s/zonefile", c.BaseURL, name)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return NameZoneFileResponse{}, err
-	}
-	req.Header.Add("Accept", "application/json")
-
-	res, err := c.Client.Do(req)
-	if err != nil {
-		return NameZoneFileResponse{}, err
-	}
-	defer res.Body.Close()
-
-	if res.StatusCode != 200 {
-		return NameZoneFileResponse{}, fmt.Errorf("failed to fetch names: %s", res.Status)
-	}
+	return c.GetNameZoneFileContext(context.Background(), name)
+}
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return NameZoneFileResponse{}, err
-	}
+func (c *APIClient) GetNameZoneFileContext(ctx context.Context, name string) (NameZoneFileResponse, error) {
+	url := fmt.Sprintf("%s/v1/names/%s/zonefile", c.BaseURL, name)
 
 	var response NameZoneFileResponse
-	err = json.Unmarshal(body, &response)
-	if err != nil {
-		return NameZoneFileResponse{}, err
+	if err := c.Client.GetJSON(ctx, url, &response); err != nil {
+		return NameZoneFileResponse{}, fmt.Errorf("failed to fetch names: %w", err)
 	}
-
 	return response, nil
 }
@@ -1,6 +1,9 @@
 package hiro
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type TransactionsResponse struct {
 	Limit   int           `json:"limit"`
@@ -42,41 +45,41 @@ type ClarityValue struct {
 }
 
 type Tx struct {
-	TxID                     string        `json:"tx_id,omitempty"`
-	Nonce                    int           `json:"nonce,omitempty"`
-	FeeRate                  string        `json:"fee_rate,omitempty"`
-	SenderAddress            string        `json:"sender_address,omitempty"`
-	Sponsored                bool          `json:"sponsored,omitempty"`
-	PostConditionMode        string        `json:"post_condition_mode,omitempty"`
-	PostConditions           []any         `json:"post_conditions,omitempty"`
-	AnchorMode               string        `json:"anchor_mode,omitempty"`
-	IsUnanchored             bool          `json:"is_unanchored,omitempty"`
-	BlockHash                string        `json:"block_hash,omitempty"`
-	ParentBlockHash          string        `json:"parent_block_hash,omitempty"`
-	BlockHeight              int           `json:"block_height,omitempty"`
-	BlockTime                int           `json:"block_time,omitempty"`
-	BlockTimeIso             time.Time     `json:"block_time_iso,omitempty"`
-	BurnBlockTime            int           `json:"burn_block_time,omitempty"`
-	BurnBlockTimeIso         time.Time     `json:"burn_block_time_iso,omitempty"`
-	ParentBurnBlockTime      int           `json:"parent_burn_block_time,omitempty"`
-	ParentBurnBlockTimeIso   time.Time     `json:"parent_burn_block_time_iso,omitempty"`
-	Canonical                bool          `json:"canonical,omitempty"`
-	ContractCall             ContractCall  `json:"contract_call,omitempty"`
-	TxIndex                  int           `json:"tx_index,omitempty"`
-	TxStatus                 string        `json:"tx_status,omitempty"`
-	TxResult                 TxResult      `json:"tx_result,omitempty"`
-	MicroblockHash           string        `json:"microblock_hash,omitempty"`
-	MicroblockSequence       int64         `json:"microblock_sequence,omitempty"`
-	MicroblockCanonical      bool          `json:"microblock_canonical,omitempty"`
-	EventCount               int           `json:"event_count,omitempty"`
-	Events                   []Event       `json:"events,omitempty"`
-	ExecutionCostReadCount   int           `json:"execution_cost_read_count,omitempty"`
-	ExecutionCostReadLength  int           `json:"execution_cost_read_length,omitempty"`
-	ExecutionCostRuntime     int           `json:"execution_cost_runtime,omitempty"`
-	ExecutionCostWriteCount  int           `json:"execution_cost_write_count,omitempty"`
-	ExecutionCostWriteLength int           `json:"execution_cost_write_length,omitempty"`
-	TxType                   string        `json:"tx_type,omitempty"`
-	TokenTransfer            TokenTransfer `json:"token_transfer,omitempty"`
+	TxID                     string          `json:"tx_id,omitempty"`
+	Nonce                    int             `json:"nonce,omitempty"`
+	FeeRate                  string          `json:"fee_rate,omitempty"`
+	SenderAddress            string          `json:"sender_address,omitempty"`
+	Sponsored                bool            `json:"sponsored,omitempty"`
+	PostConditionMode        string          `json:"post_condition_mode,omitempty"`
+	PostConditions           []PostCondition `json:"post_conditions,omitempty"`
+	AnchorMode               string          `json:"anchor_mode,omitempty"`
+	IsUnanchored             bool            `json:"is_unanchored,omitempty"`
+	BlockHash                string          `json:"block_hash,omitempty"`
+	ParentBlockHash          string          `json:"parent_block_hash,omitempty"`
+	BlockHeight              int             `json:"block_height,omitempty"`
+	BlockTime                int             `json:"block_time,omitempty"`
+	BlockTimeIso             time.Time       `json:"block_time_iso,omitempty"`
+	BurnBlockTime            int             `json:"burn_block_time,omitempty"`
+	BurnBlockTimeIso         time.Time       `json:"burn_block_time_iso,omitempty"`
+	ParentBurnBlockTime      int             `json:"parent_burn_block_time,omitempty"`
+	ParentBurnBlockTimeIso   time.Time       `json:"parent_burn_block_time_iso,omitempty"`
+	Canonical                bool            `json:"canonical,omitempty"`
+	ContractCall             ContractCall    `json:"contract_call,omitempty"`
+	TxIndex                  int             `json:"tx_index,omitempty"`
+	TxStatus                 string          `json:"tx_status,omitempty"`
+	TxResult                 TxResult        `json:"tx_result,omitempty"`
+	MicroblockHash           string          `json:"microblock_hash,omitempty"`
+	MicroblockSequence       int64           `json:"microblock_sequence,omitempty"`
+	MicroblockCanonical      bool            `json:"microblock_canonical,omitempty"`
+	EventCount               int             `json:"event_count,omitempty"`
+	Events                   []Event         `json:"events,omitempty"`
+	ExecutionCostReadCount   int             `json:"execution_cost_read_count,omitempty"`
+	ExecutionCostReadLength  int             `json:"execution_cost_read_length,omitempty"`
+	ExecutionCostRuntime     int             `json:"execution_cost_runtime,omitempty"`
+	ExecutionCostWriteCount  int             `json:"execution_cost_write_count,omitempty"`
+	ExecutionCostWriteLength int             `json:"execution_cost_write_length,omitempty"`
+	TxType                   string          `json:"tx_type,omitempty"`
+	TokenTransfer            TokenTransfer   `json:"token_transfer,omitempty"`
 }
 
 type Stx struct {
@@ -104,22 +107,61 @@ type Events struct {
 }
 
 type Event struct {
-	EventIndex  int         `json:"event_index,omitempty"`
-	EventType   string      `json:"event_type,omitempty"`
-	TxId        string      `json:"tx_id,omitempty"`
-	Asset       Asset       `json:"asset,omitempty"`
-	ContractLog ContractLog `json:"contract_log,omitempty"`
+	EventIndex   int          `json:"event_index,omitempty"`
+	EventType    string       `json:"event_type,omitempty"`
+	TxId         string       `json:"tx_id,omitempty"`
+	Asset        Asset        `json:"asset,omitempty"`
+	ContractLog  ContractLog  `json:"contract_log,omitempty"`
+	StxLockEvent StxLockEvent `json:"stx_lock_event,omitempty"`
 }
 
 type Asset struct {
 	AssetEventType string       `json:"asset_event_type,omitempty"`
 	Sender         string       `json:"sender,omitempty"`
 	Recipient      string       `json:"recipient,omitempty"`
-	Amount         int          `json:"amount,omitempty"`
+	Amount         string       `json:"amount,omitempty"`
+	Memo           string       `json:"memo,omitempty"`
 	AssetId        string       `json:"asset_id,omitempty"`
 	Value          ClarityValue `json:"value,omitempty"`
 }
 
+type StxLockEvent struct {
+	LockedAmount  string `json:"locked_amount,omitempty"`
+	UnlockHeight  int    `json:"unlock_height,omitempty"`
+	LockedAddress string `json:"locked_address,omitempty"`
+}
+
+type EventsResponse struct {
+	Limit  int     `json:"limit"`
+	Offset int     `json:"offset"`
+	Events []Event `json:"events"`
+}
+
+// PostCondition is a post condition as listed by the API. Type is stx,
+// fungible or non_fungible.
+type PostCondition struct {
+	Type          string                 `json:"type,omitempty"`
+	ConditionCode string                 `json:"condition_code,omitempty"`
+	Amount        string                 `json:"amount,omitempty"`
+	Principal     PostConditionPrincipal `json:"principal,omitempty"`
+	Asset         PostConditionAsset     `json:"asset,omitempty"`
+	AssetValue    ClarityValue           `json:"asset_value,omitempty"`
+}
+
+// PostConditionPrincipal is principal_origin, principal_standard or
+// principal_contract.
+type PostConditionPrincipal struct {
+	TypeID       string `json:"type_id,omitempty"`
+	Address      string `json:"address,omitempty"`
+	ContractName string `json:"contract_name,omitempty"`
+}
+
+type PostConditionAsset struct {
+	AssetName       string `json:"asset_name,omitempty"`
+	ContractAddress string `json:"contract_address,omitempty"`
+	ContractName    string `json:"contract_name,omitempty"`
+}
+
 type ContractLog struct {
 	ContractId string       `json:"contract_id,omitempty"`
 	Topic      string       `json:"topic,omitempty"`
@@ -204,6 +246,7 @@ type ReadOnlyPayload struct {
 type ReadOnlyResponse struct {
 	Okay   bool   `json:"okay"`
 	Result string `json:"result"`
+	Cause  string `json:"cause"`
 }
 
 type ContractDetailsResponse struct {
@@ -248,3 +291,47 @@ type NameReverseLookupResponse struct {
 type NameZoneFileResponse struct {
 	Zonefile string `json:"zonefile"`
 }
+
+type NoncesResponse struct {
+	LastMempoolTxNonce    *int  `json:"last_mempool_tx_nonce"`
+	LastExecutedTxNonce   *int  `json:"last_executed_tx_nonce"`
+	PossibleNextNonce     int   `json:"possible_next_nonce"`
+	DetectedMissingNonces []int `json:"detected_missing_nonces"`
+}
+
+// BroadcastRejection is returned by the node when a transaction is not
+// accepted into the mempool.
+type BroadcastRejection struct {
+	Message    string         `json:"error"`
+	Reason     string         `json:"reason"`
+	ReasonData map[string]any `json:"reason_data"`
+	TxID       string         `json:"txid"`
+}
+
+func (r *BroadcastRejection) Error() string {
+	if r.Reason == "" {
+		return r.Message
+	}
+	return fmt.Sprintf("%s: %s", r.Message, r.Reason)
+}
+
+type Block struct {
+	Canonical       bool   `json:"canonical"`
+	Height          int    `json:"height"`
+	Hash            string `json:"hash"`
+	IndexBlockHash  string `json:"index_block_hash"`
+	ParentBlockHash string `json:"parent_block_hash"`
+	BlockTime       int64  `json:"block_time"`
+	BlockTimeISO    string `json:"block_time_iso"`
+	BurnBlockHeight int    `json:"burn_block_height"`
+	BurnBlockHash   string `json:"burn_block_hash"`
+	BurnBlockTime   int64  `json:"burn_block_time"`
+	TxCount         int    `json:"tx_count"`
+}
+
+type BlocksResponse struct {
+	Limit   int     `json:"limit"`
+	Offset  int     `json:"offset"`
+	Total   int     `json:"total"`
+	Results []Block `json:"results"`
+}
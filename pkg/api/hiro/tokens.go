@@ -1,89 +1,46 @@
 package hiro
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"net/http"
+
+	"github.com/hashhavoc/teller/pkg/api/paginate"
 )
 
 func (c *APIClient) GetAllTokens() ([]TokenResult, error) {
-	var allResults []TokenResult
-	offset := 0
-	limit := 60 // or any other limit you want to set
-
-	for {
-		url := fmt.Sprintf("%s/metadata/v1/ft?offset=%d&limit=%d", c.BaseURL, offset, limit)
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return nil, err
-		}
-		req.Header.Add("Accept", "application/json")
-
-		res, err := c.Client.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer res.Body.Close()
-
-		if res.StatusCode != 200 {
-			return nil, fmt.Errorf("failed to get contract source: %s", res.Status)
-		}
-
-		body, err := io.ReadAll(res.Body)
-		if err != nil {
-			return nil, err
-		}
-
-		var response Response
-		err = json.Unmarshal(body, &response)
-		if err != nil {
-			return nil, err
-		}
-
-		allResults = append(allResults, response.Results...)
+	return c.GetAllTokensContext(context.Background())
+}
 
-		if len(allResults) >= response.Total {
-			break
-		}
+func (c *APIClient) GetAllTokensContext(ctx context.Context) ([]TokenResult, error) {
+	return c.TokensPaginator().All(ctx)
+}
 
-		offset += limit
+// TokensPaginator pages through the fungible token metadata.
+func (c *APIClient) TokensPaginator() *paginate.Offset[TokenResult] {
+	limit := 60
+	return &paginate.Offset[TokenResult]{
+		PageSize: limit,
+		Fetch: func(ctx context.Context, index int) (paginate.Page[TokenResult], error) {
+			url := fmt.Sprintf("%s/metadata/v1/ft?offset=%d&limit=%d", c.BaseURL, index*limit, limit)
+			var response Response
+			if err := c.Client.GetJSON(ctx, url, &response); err != nil {
+				return paginate.Page[TokenResult]{}, fmt.Errorf("failed to get tokens: %w", err)
+			}
+			return paginate.Page[TokenResult]{Items: response.Results, Total: response.Total}, nil
+		},
 	}
-
-	return allResults, nil
 }
 
 func (c *APIClient) GetNFTHoldings(principal string) ([]NFTHoldingResponseResults, error) {
-	url := fmt.Sprintf("%s/extended/v1/tokens/nft/holdings?principal=%s", c.BaseURL, principal)
-	method := "GET"
-
-	req, err := http.NewRequest(method, url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Add("Accept", "application/json")
-
-	res, err := c.Client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
-
-	if res.StatusCode != 200 {
-		return nil, fmt.Errorf("failed to get contract source: %s", res.Status)
-	}
+	return c.GetNFTHoldingsContext(context.Background(), principal)
+}
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
+func (c *APIClient) GetNFTHoldingsContext(ctx context.Context, principal string) ([]NFTHoldingResponseResults, error) {
+	url := fmt.Sprintf("%s/extended/v1/tokens/nft/holdings?principal=%s", c.BaseURL, principal)
 
 	var response NFTHoldingResponse
-	err = json.Unmarshal(body, &response)
-	if err != nil {
-		return nil, err
+	if err := c.Client.GetJSON(ctx, url, &response); err != nil {
+		return nil, fmt.Errorf("failed to get nft holdings: %w", err)
 	}
-
 	return response.Results, nil
 }
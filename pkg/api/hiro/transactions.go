@@ -0,0 +1,128 @@
+package hiro
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hashhavoc/teller/pkg/api/paginate"
+	"github.com/hashhavoc/teller/pkg/api/transport"
+)
+
+// BroadcastTransaction submits a serialized transaction to the mempool and
+// returns its txid.
+func (c *APIClient) BroadcastTransaction(tx []byte) (string, error) {
+	return c.BroadcastTransactionContext(context.Background(), tx)
+}
+
+func (c *APIClient) BroadcastTransactionContext(ctx context.Context, tx []byte) (string, error) {
+	url := fmt.Sprintf("%s/v2/transactions", c.BaseURL)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(tx))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Add("Content-Type", "application/octet-stream")
+
+	var txid string
+	err = c.Client.DoJSON(req, &txid)
+	var statusErr *transport.StatusError
+	if errors.As(err, &statusErr) {
+		var rejection BroadcastRejection
+		if err := json.Unmarshal(statusErr.Body, &rejection); err == nil && rejection.Message != "" {
+			return "", &rejection
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to broadcast transaction: %w", err)
+	}
+	return txid, nil
+}
+
+// GetNonces returns the nonce state of an account, including the next nonce
+// to use once pending mempool transactions are taken into account.
+func (c *APIClient) GetNonces(principal string) (NoncesResponse, error) {
+	return c.GetNoncesContext(context.Background(), principal)
+}
+
+func (c *APIClient) GetNoncesContext(ctx context.Context, principal string) (NoncesResponse, error) {
+	url := fmt.Sprintf("%s/extended/v1/address/%s/nonces", c.BaseURL, principal)
+
+	var response NoncesResponse
+	if err := c.Client.GetJSON(ctx, url, &response); err != nil {
+		return NoncesResponse{}, fmt.Errorf("failed to get nonces: %w", err)
+	}
+	return response, nil
+}
+
+// GetTransferFeeRate returns the estimated fee rate in micro-STX per byte
+// of a serialized transaction.
+func (c *APIClient) GetTransferFeeRate() (uint64, error) {
+	return c.GetTransferFeeRateContext(context.Background())
+}
+
+func (c *APIClient) GetTransferFeeRateContext(ctx context.Context) (uint64, error) {
+	url := fmt.Sprintf("%s/v2/fees/transfer", c.BaseURL)
+
+	var rate uint64
+	if err := c.Client.GetJSON(ctx, url, &rate); err != nil {
+		return 0, fmt.Errorf("failed to get fee rate: %w", err)
+	}
+	return rate, nil
+}
+
+// ErrTransactionNotFound is returned by GetTransaction when the API does not
+// know the txid, which is also the case shortly after a broadcast.
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+// GetTransaction returns a mined or mempool transaction by txid.
+func (c *APIClient) GetTransaction(txid string) (Tx, error) {
+	return c.GetTransactionContext(context.Background(), txid)
+}
+
+func (c *APIClient) GetTransactionContext(ctx context.Context, txid string) (Tx, error) {
+	url := fmt.Sprintf("%s/extended/v1/tx/%s", c.BaseURL, txid)
+
+	var response Tx
+	err := c.Client.GetJSON(ctx, url, &response)
+	if transport.IsStatus(err, http.StatusNotFound) {
+		return Tx{}, ErrTransactionNotFound
+	}
+	if err != nil {
+		return Tx{}, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	return response, nil
+}
+
+// GetTransactionEvents returns every event of a transaction, which the
+// transaction itself only lists up to a limit.
+func (c *APIClient) GetTransactionEvents(txid string) ([]Event, error) {
+	return c.GetTransactionEventsContext(context.Background(), txid)
+}
+
+func (c *APIClient) GetTransactionEventsContext(ctx context.Context, txid string) ([]Event, error) {
+	return c.TransactionEventsPaginator(txid).All(ctx)
+}
+
+// TransactionEventsPaginator pages through the events of a transaction. The
+// endpoint does not report a total, so the cursor is the offset of the next
+// page and a short page is the last.
+func (c *APIClient) TransactionEventsPaginator(txid string) *paginate.Cursor[Event, int] {
+	limit := 50
+	return &paginate.Cursor[Event, int]{
+		Fetch: func(ctx context.Context, offset int) ([]Event, int, error) {
+			url := fmt.Sprintf("%s/extended/v1/tx/events?tx_id=%s&offset=%d&limit=%d", c.BaseURL, txid, offset, limit)
+			var response EventsResponse
+			if err := c.Client.GetJSON(ctx, url, &response); err != nil {
+				return nil, 0, fmt.Errorf("failed to get transaction events: %w", err)
+			}
+			if len(response.Events) < limit {
+				return response.Events, 0, nil
+			}
+			return response.Events, offset + limit, nil
+		},
+	}
+}
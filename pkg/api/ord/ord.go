@@ -1,61 +1,46 @@
 package ord
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"strconv"
 
 	"github.com/hashhavoc/teller/internal/common"
+	"github.com/hashhavoc/teller/pkg/api/transport"
 )
 
 const DefaultApiBase = "http://localhost:8080"
 
+// APIClient wraps the API. Each method has a variant suffixed with Context
+// whose context cancels its requests, the plain method uses
+// context.Background.
 type APIClient struct {
 	BaseURL string
-	Client  *http.Client
+	Client  *transport.Client
 }
 
-func NewAPIClient(baseURL string) *APIClient {
+func NewAPIClient(baseURL string, opts transport.Options) *APIClient {
 	return &APIClient{
 		BaseURL: baseURL,
-		Client:  &http.Client{},
+		Client:  transport.New(opts),
 	}
 }
 
 func (c *APIClient) GetAllRunes() ([]Entry, error) {
+	return c.GetAllRunesContext(context.Background())
+}
+
+func (c *APIClient) GetAllRunesContext(ctx context.Context) ([]Entry, error) {
 	var allEntries []Entry
 	offset := 1
 
 	for {
 		url := fmt.Sprintf("%s/runes/%d", c.BaseURL, offset)
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return nil, err
-		}
-		req.Header.Add("Accept", "application/json")
-
-		res, err := c.Client.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer res.Body.Close()
-
-		if res.StatusCode != 200 {
-			return nil, fmt.Errorf("failed to get runes: %s", res.Status)
-		}
-
-		body, err := io.ReadAll(res.Body)
-		if err != nil {
-			return nil, err
-		}
 
 		var response Response
-		err = json.Unmarshal(body, &response)
-		if err != nil {
-			return nil, err
+		if err := c.Client.GetJSON(ctx, url, &response); err != nil {
+			return nil, fmt.Errorf("failed to get runes: %w", err)
 		}
 
 		for _, entry := range response.Entries {
@@ -109,35 +94,15 @@ func (c *APIClient) GetAllRunes() ([]Entry, error) {
 }
 
 func (c *APIClient) GetBalances() (RuneBalanceRespone, error) {
-	url := fmt.Sprintf("%s/runes/balances", c.BaseURL)
-
-	req, err := http.NewRequest("GET", url, nil)
-
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Add("Accept", "application/json")
-
-	res, err := c.Client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
-
-	if res.StatusCode != 200 {
-		return nil, fmt.Errorf("failed to get balances: %s", res.Status)
-	}
+	return c.GetBalancesContext(context.Background())
+}
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
+func (c *APIClient) GetBalancesContext(ctx context.Context) (RuneBalanceRespone, error) {
+	url := fmt.Sprintf("%s/runes/balances", c.BaseURL)
 
 	var response RuneBalanceRespone
-	err = json.Unmarshal(body, &response)
-	if err != nil {
-		return nil, err
+	if err := c.Client.GetJSON(ctx, url, &response); err != nil {
+		return nil, fmt.Errorf("failed to get balances: %w", err)
 	}
-
 	return response, nil
 }
@@ -0,0 +1,172 @@
+// Package paginate walks paginated API endpoints. Offset paginates endpoints
+// that report the total number of results, fetching pages concurrently while
+// yielding them in order. Cursor follows endpoints where each page links to
+// the next, one page at a time.
+package paginate
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// DefaultConcurrency is the number of pages Offset fetches at once when its
+// Concurrency is not set.
+const DefaultConcurrency = 5
+
+// Page is one page of results and the total number of results across all
+// pages.
+type Page[T any] struct {
+	Items []T
+	Total int
+}
+
+// Offset paginates an endpoint addressed by page index. The first page is
+// fetched alone to learn the total, the remaining pages are then fetched
+// Concurrency at a time.
+type Offset[T any] struct {
+	// Fetch returns the page at index, counted from 0. Endpoints taking an
+	// offset rather than a page number use index*PageSize.
+	Fetch       func(ctx context.Context, index int) (Page[T], error)
+	PageSize    int
+	Concurrency int
+}
+
+type result[T any] struct {
+	items []T
+	err   error
+}
+
+// Pages returns an iterator over the items of each page in order. Pages are
+// fetched ahead of the caller, but never more than Concurrency pages beyond
+// the page being processed. Iteration ends after the first error, which is
+// the error of the first request that failed rather than of the first page.
+// Breaking out of the loop cancels the requests in flight.
+func (o *Offset[T]) Pages(ctx context.Context) iter.Seq2[[]T, error] {
+	return func(yield func([]T, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		first, err := o.Fetch(ctx, 0)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if !yield(first.Items, nil) {
+			return
+		}
+		if o.PageSize <= 0 || len(first.Items) == 0 {
+			return
+		}
+		pages := (first.Total + o.PageSize - 1) / o.PageSize
+		if pages <= 1 {
+			return
+		}
+
+		concurrency := o.Concurrency
+		if concurrency <= 0 {
+			concurrency = DefaultConcurrency
+		}
+		// Each page has its own buffered channel so workers never block and
+		// pages are consumed in order whatever order they complete in. A
+		// slot of sem is held from fetching a page until it is consumed.
+		results := make([]chan result[T], pages)
+		for i := range results {
+			results[i] = make(chan result[T], 1)
+		}
+		sem := make(chan struct{}, concurrency)
+		var (
+			once     sync.Once
+			firstErr error
+		)
+		go func() {
+			for i := 1; i < pages; i++ {
+				select {
+				case sem <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				go func(i int) {
+					page, err := o.Fetch(ctx, i)
+					if err != nil {
+						// Stop the other requests, which then fail with
+						// context.Canceled, and keep this error to report.
+						once.Do(func() {
+							firstErr = err
+							cancel()
+						})
+					}
+					results[i] <- result[T]{items: page.Items, err: err}
+				}(i)
+			}
+		}()
+
+		for i := 1; i < pages; i++ {
+			var r result[T]
+			select {
+			case r = <-results[i]:
+			case <-ctx.Done():
+				r.err = ctx.Err()
+			}
+			if r.err != nil {
+				once.Do(func() { firstErr = r.err })
+				yield(nil, firstErr)
+				return
+			}
+			<-sem
+			if !yield(r.items, nil) {
+				return
+			}
+		}
+	}
+}
+
+// All fetches every page and returns their items in order.
+func (o *Offset[T]) All(ctx context.Context) ([]T, error) {
+	return collect(o.Pages(ctx))
+}
+
+// Cursor paginates an endpoint where each page returns the cursor of the
+// next. The zero cursor requests the first page, and a page returning the
+// zero cursor is the last.
+type Cursor[T any, C comparable] struct {
+	Fetch func(ctx context.Context, cursor C) (items []T, next C, err error)
+}
+
+// Pages returns an iterator over the items of each page in order. Iteration
+// ends after the first error, or when a page links back to a cursor already
+// fetched, which would otherwise repeat the same pages forever.
+func (c *Cursor[T, C]) Pages(ctx context.Context) iter.Seq2[[]T, error] {
+	return func(yield func([]T, error) bool) {
+		var cursor, zero C
+		seen := make(map[C]bool)
+		for {
+			seen[cursor] = true
+			items, next, err := c.Fetch(ctx, cursor)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(items, nil) || next == zero || seen[next] {
+				return
+			}
+			cursor = next
+		}
+	}
+}
+
+// All fetches every page and returns their items in order.
+func (c *Cursor[T, C]) All(ctx context.Context) ([]T, error) {
+	return collect(c.Pages(ctx))
+}
+
+func collect[T any](pages iter.Seq2[[]T, error]) ([]T, error) {
+	var all []T
+	for items, err := range pages {
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+	}
+	return all, nil
+}
@@ -0,0 +1,248 @@
+package paginate
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// numbers returns an offset paginator over 0 to total-1, whose pages take a
+// random time so that they complete out of order.
+func numbers(total, pageSize, concurrency int) *Offset[int] {
+	return &Offset[int]{
+		PageSize:    pageSize,
+		Concurrency: concurrency,
+		Fetch: func(ctx context.Context, index int) (Page[int], error) {
+			time.Sleep(time.Duration(rand.Intn(5)) * time.Millisecond)
+			var items []int
+			for n := index * pageSize; n < total && n < (index+1)*pageSize; n++ {
+				items = append(items, n)
+			}
+			return Page[int]{Items: items, Total: total}, nil
+		},
+	}
+}
+
+func TestOffsetOrder(t *testing.T) {
+	for _, tt := range []struct {
+		name                         string
+		total, pageSize, concurrency int
+	}{
+		{"single page", 3, 10, 2},
+		{"exact pages", 30, 10, 2},
+		{"partial last page", 47, 5, 3},
+		{"default concurrency", 100, 3, 0},
+		{"empty", 0, 10, 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := numbers(tt.total, tt.pageSize, tt.concurrency).All(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want []int
+			for n := 0; n < tt.total; n++ {
+				want = append(want, n)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("All = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestOffsetConcurrency(t *testing.T) {
+	var inFlight, most atomic.Int32
+	o := &Offset[int]{
+		PageSize:    1,
+		Concurrency: 3,
+		Fetch: func(ctx context.Context, index int) (Page[int], error) {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				m := most.Load()
+				if n <= m || most.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			return Page[int]{Items: []int{index}, Total: 20}, nil
+		},
+	}
+	items, err := o.All(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 20 {
+		t.Errorf("All returned %d items, want 20", len(items))
+	}
+	if most.Load() > 3 {
+		t.Errorf("%d pages fetched at once, want at most 3", most.Load())
+	}
+}
+
+// The error reported is that of the request that failed, not the
+// cancellation it causes in the requests of earlier pages.
+func TestOffsetFirstError(t *testing.T) {
+	errPage := errors.New("page 4 failed")
+	o := &Offset[int]{
+		PageSize:    1,
+		Concurrency: 5,
+		Fetch: func(ctx context.Context, index int) (Page[int], error) {
+			switch index {
+			case 0:
+				return Page[int]{Items: []int{0}, Total: 10}, nil
+			case 4:
+				return Page[int]{}, errPage
+			default:
+				<-ctx.Done()
+				return Page[int]{}, ctx.Err()
+			}
+		},
+	}
+
+	var pages int
+	var got error
+	for _, err := range o.Pages(context.Background()) {
+		if err != nil {
+			got = err
+			continue
+		}
+		pages++
+	}
+	if got != errPage {
+		t.Errorf("error = %v, want %v", got, errPage)
+	}
+	if pages != 1 {
+		t.Errorf("yielded %d pages before the error, want 1", pages)
+	}
+
+	o = &Offset[int]{
+		PageSize: 1,
+		Fetch:    func(ctx context.Context, index int) (Page[int], error) { return Page[int]{}, errPage },
+	}
+	if _, err := o.All(context.Background()); err != errPage {
+		t.Errorf("All with a failing first page = %v, want %v", err, errPage)
+	}
+}
+
+func TestOffsetBreakCancels(t *testing.T) {
+	var started, canceled atomic.Int32
+	o := &Offset[int]{
+		PageSize:    1,
+		Concurrency: 4,
+		Fetch: func(ctx context.Context, index int) (Page[int], error) {
+			if index < 2 {
+				return Page[int]{Items: []int{index}, Total: 100}, nil
+			}
+			started.Add(1)
+			<-ctx.Done()
+			canceled.Add(1)
+			return Page[int]{}, ctx.Err()
+		},
+	}
+
+	// The later pages are fetched once the first is consumed, leave the
+	// loop at the second while they are in flight.
+	for items := range o.Pages(context.Background()) {
+		if items[0] == 1 {
+			time.Sleep(10 * time.Millisecond)
+			break
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for canceled.Load() < started.Load() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if started.Load() == 0 {
+		t.Fatal("no requests started after the second page")
+	}
+	if started.Load() > 4 {
+		t.Errorf("%d requests started, want at most the concurrency of 4", started.Load())
+	}
+	if canceled.Load() != started.Load() {
+		t.Errorf("%d of %d requests canceled", canceled.Load(), started.Load())
+	}
+}
+
+func TestCursor(t *testing.T) {
+	pages := map[string]struct {
+		items []int
+		next  string
+	}{
+		"":  {[]int{1, 2}, "b"},
+		"b": {[]int{3}, "c"},
+		"c": {[]int{4, 5}, ""},
+	}
+	var fetched []string
+	c := &Cursor[int, string]{
+		Fetch: func(ctx context.Context, cursor string) ([]int, string, error) {
+			fetched = append(fetched, cursor)
+			p := pages[cursor]
+			return p.items, p.next, nil
+		},
+	}
+	got, err := c.All(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{1, 2, 3, 4, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("All = %v, want %v", got, want)
+	}
+
+	fetched = nil
+	for range c.Pages(context.Background()) {
+		break
+	}
+	if len(fetched) != 1 {
+		t.Errorf("fetched %v after break, want only the first page", fetched)
+	}
+}
+
+func TestCursorError(t *testing.T) {
+	errPage := errors.New("page b failed")
+	c := &Cursor[int, string]{
+		Fetch: func(ctx context.Context, cursor string) ([]int, string, error) {
+			if cursor == "b" {
+				return nil, "", errPage
+			}
+			return []int{1}, "b", nil
+		},
+	}
+	if _, err := c.All(context.Background()); err != errPage {
+		t.Errorf("All = %v, want %v", err, errPage)
+	}
+}
+
+// An API returning a cursor it already returned ends the iteration instead
+// of looping forever.
+func TestCursorRepeated(t *testing.T) {
+	for name, next := range map[string]map[string]string{
+		"same cursor": {"": "a", "a": "a"},
+		"cycle":       {"": "a", "a": "b", "b": "a"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var calls int
+			c := &Cursor[string, string]{
+				Fetch: func(ctx context.Context, cursor string) ([]string, string, error) {
+					calls++
+					if calls > 10 {
+						t.Fatal("pagination did not stop")
+					}
+					return []string{cursor}, next[cursor], nil
+				},
+			}
+			got, err := c.All(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(next) {
+				t.Errorf("All = %q, want each cursor once", got)
+			}
+		})
+	}
+}
@@ -1,192 +1,116 @@
 package stxtools
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"net/http"
+
+	"github.com/hashhavoc/teller/pkg/api/paginate"
+	"github.com/hashhavoc/teller/pkg/api/transport"
 )
 
 const DefaultApiBase = "https://api.stxtools.io"
 
+// APIClient wraps the API. Each method has a variant suffixed with Context
+// whose context cancels its requests, the plain method uses
+// context.Background.
 type APIClient struct {
 	BaseURL string
-	Client  *http.Client
+	Client  *transport.Client
 }
 
-func NewAPIClient(baseURL string) *APIClient {
+func NewAPIClient(baseURL string, opts transport.Options) *APIClient {
 	return &APIClient{
 		BaseURL: baseURL,
-		Client:  &http.Client{},
+		Client:  transport.New(opts),
 	}
 }
 
 func (c *APIClient) GetAllTokens() ([]Token, error) {
+	return c.GetAllTokensContext(context.Background())
+}
+
+func (c *APIClient) GetAllTokensContext(ctx context.Context) ([]Token, error) {
 	url := fmt.Sprintf("%s/tokens", c.BaseURL)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Add("Accept", "application/json")
 
-	res, err := c.Client.Do(req)
-	if err != nil {
-		return nil, err
+	var response []Token
+	if err := c.Client.GetJSON(ctx, url, &response); err != nil {
+		return nil, fmt.Errorf("failed to get tokens: %w", err)
 	}
-	defer res.Body.Close()
+	return response, nil
+}
 
-	if res.StatusCode != 200 {
-		return nil, fmt.Errorf("failed to get tokens: %s", res.Status)
-	}
+func (c *APIClient) GetAllHolders(contractId string) (HoldersData, error) {
+	return c.GetAllHoldersContext(context.Background(), contractId)
+}
 
-	body, err := io.ReadAll(res.Body)
+func (c *APIClient) GetAllHoldersContext(ctx context.Context, contractId string) (HoldersData, error) {
+	holders, err := c.HoldersPaginator(contractId).All(ctx)
 	if err != nil {
-		return nil, err
+		return HoldersData{}, err
 	}
+	return HoldersData{TopHolders: holders}, nil
+}
 
-	var response []Token
-	err = json.Unmarshal(body, &response)
-	if err != nil {
-		return nil, err
+// HoldersPaginator pages through the top holders of a token.
+func (c *APIClient) HoldersPaginator(contractId string) *paginate.Offset[TopHolder] {
+	limit := 50
+	return &paginate.Offset[TopHolder]{
+		PageSize: limit,
+		Fetch: func(ctx context.Context, page int) (paginate.Page[TopHolder], error) {
+			url := fmt.Sprintf("%s/tokens/%s/top-holders?page=%d&limit=%d", c.BaseURL, contractId, page, limit)
+			var response HoldersResponse
+			if err := c.Client.GetJSON(ctx, url, &response); err != nil {
+				return paginate.Page[TopHolder]{}, fmt.Errorf("failed to get holders: %w", err)
+			}
+			return paginate.Page[TopHolder]{Items: response.Data.TopHolders, Total: response.Page.TotalElements}, nil
+		},
 	}
-
-	return response, nil
 }
 
-func (c *APIClient) GetAllHolders(contractId string) (HoldersData, error) {
-	var allResults HoldersData
-	page := 0
-	limit := 50 // or any other limit you want to set
-
-	for {
-		url := fmt.Sprintf("%s/tokens/%s/top-holders?page=%d&limit=%d", c.BaseURL, contractId, page, limit)
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return HoldersData{}, err
-		}
-		req.Header.Add("Accept", "application/json")
-
-		res, err := c.Client.Do(req)
-		if err != nil {
-			return HoldersData{}, err
-		}
-		defer res.Body.Close()
-
-		if res.StatusCode != 200 {
-			return HoldersData{}, fmt.Errorf("failed to get contract source: %s", res.Status)
-		}
-
-		body, err := io.ReadAll(res.Body)
-		if err != nil {
-			return HoldersData{}, err
-		}
-
-		var response HoldersResponse
-		err = json.Unmarshal(body, &response)
-		if err != nil {
-			return HoldersData{}, err
-		}
-
-		allResults.TopHolders = append(allResults.TopHolders, response.Data.TopHolders...) // Assuming TopHolders is the slice of TokenResult
-
-		// Assuming the Response struct has a Page field that includes TotalElements
-		if len(allResults.TopHolders) >= response.Page.TotalElements {
-			break
-		}
-		page += 1
-	}
+func (c *APIClient) GetAllSwaps(contractId string) ([]SwapsData, error) {
+	return c.GetAllSwapsContext(context.Background(), contractId)
+}
 
-	return allResults, nil
+func (c *APIClient) GetAllSwapsContext(ctx context.Context, contractId string) ([]SwapsData, error) {
+	return c.SwapsPaginator(contractId).All(ctx)
 }
 
-func (c *APIClient) GetAllSwaps(contractId string) ([]SwapsData, error) {
-	var allSwaps []SwapsData
-	page := 0
-	limit := 50 // Adjust the limit as needed
-
-	for {
-		url := fmt.Sprintf("%s/tokens/%s/swaps?page=%d&limit=%d", c.BaseURL, contractId, page, limit)
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return nil, err
-		}
-		req.Header.Add("Accept", "application/json")
-
-		res, err := c.Client.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer res.Body.Close()
-
-		if res.StatusCode != 200 {
-			return nil, fmt.Errorf("failed to get swaps: %s", res.Status)
-		}
-
-		body, err := io.ReadAll(res.Body)
-		if err != nil {
-			return nil, err
-		}
-
-		var response SwapsResponse
-		err = json.Unmarshal(body, &response)
-		if err != nil {
-			return nil, err
-		}
-
-		allSwaps = append(allSwaps, response.Data...) // Assuming Data is the slice of SwapsData
-
-		// Check if we've fetched all available data
-		if len(allSwaps) >= response.Page.TotalElements {
-			break
-		}
-		page += 1
+// SwapsPaginator pages through the swaps of a token.
+func (c *APIClient) SwapsPaginator(contractId string) *paginate.Offset[SwapsData] {
+	limit := 50
+	return &paginate.Offset[SwapsData]{
+		PageSize: limit,
+		Fetch: func(ctx context.Context, page int) (paginate.Page[SwapsData], error) {
+			url := fmt.Sprintf("%s/tokens/%s/swaps?page=%d&limit=%d", c.BaseURL, contractId, page, limit)
+			var response SwapsResponse
+			if err := c.Client.GetJSON(ctx, url, &response); err != nil {
+				return paginate.Page[SwapsData]{}, fmt.Errorf("failed to get swaps: %w", err)
+			}
+			return paginate.Page[SwapsData]{Items: response.Data, Total: response.Page.TotalElements}, nil
+		},
 	}
-
-	return allSwaps, nil
 }
 
 func (c *APIClient) GetAllTransfers(contractId string) ([]Transaction, error) {
-	var allTransfers []Transaction
-	page := 0
-	limit := 50 // Adjust the limit as needed
-
-	for {
-		url := fmt.Sprintf("%s/tokens/%s/transfers?page=%d&limit=%d", c.BaseURL, contractId, page, limit)
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return nil, err
-		}
-		req.Header.Add("Accept", "application/json")
-
-		res, err := c.Client.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer res.Body.Close()
-
-		if res.StatusCode != 200 {
-			return nil, fmt.Errorf("failed to get transfers: %s", res.Status)
-		}
-
-		body, err := io.ReadAll(res.Body)
-		if err != nil {
-			return nil, err
-		}
-
-		var response TransfersResponse
-		err = json.Unmarshal(body, &response)
-		if err != nil {
-			return nil, err
-		}
-
-		allTransfers = append(allTransfers, response.Data...) // Assuming Data is the slice of Transaction
-
-		// Check if we've fetched all available data
-		if len(allTransfers) >= response.Page.TotalElements {
-			break
-		}
-		page += 1
-	}
+	return c.GetAllTransfersContext(context.Background(), contractId)
+}
 
-	return allTransfers, nil
+func (c *APIClient) GetAllTransfersContext(ctx context.Context, contractId string) ([]Transaction, error) {
+	return c.TransfersPaginator(contractId).All(ctx)
+}
+
+// TransfersPaginator pages through the transfers of a token.
+func (c *APIClient) TransfersPaginator(contractId string) *paginate.Offset[Transaction] {
+	limit := 50
+	return &paginate.Offset[Transaction]{
+		PageSize: limit,
+		Fetch: func(ctx context.Context, page int) (paginate.Page[Transaction], error) {
+			url := fmt.Sprintf("%s/tokens/%s/transfers?page=%d&limit=%d", c.BaseURL, contractId, page, limit)
+			var response TransfersResponse
+			if err := c.Client.GetJSON(ctx, url, &response); err != nil {
+				return paginate.Page[Transaction]{}, fmt.Errorf("failed to get transfers: %w", err)
+			}
+			return paginate.Page[Transaction]{Items: response.Data, Total: response.Page.TotalElements}, nil
+		},
+	}
 }
@@ -0,0 +1,236 @@
+package transport
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Cache stores the bodies of successful responses on disk, one JSON file per
+// request. The Client decides what to cache and for how long.
+type Cache struct {
+	Dir string
+	// Disabled bypasses the cache, neither reading nor storing responses.
+	Disabled bool
+}
+
+// NewCache returns a cache storing responses in dir.
+func NewCache(dir string) *Cache {
+	return &Cache{Dir: dir}
+}
+
+type cacheEntry struct {
+	Key      string    `json:"key"`
+	Host     string    `json:"host"`
+	StoredAt time.Time `json:"stored_at"`
+	// ExpiresAt is zero for immutable responses, which never expire.
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+	Body      []byte    `json:"body"`
+}
+
+func (e *cacheEntry) expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && now.After(e.ExpiresAt)
+}
+
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func readEntry(path string) (*cacheEntry, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (c *Cache) get(key string) ([]byte, bool) {
+	entry, err := readEntry(c.path(key))
+	if err != nil || entry.Key != key || entry.expired(time.Now()) {
+		return nil, false
+	}
+	return entry.Body, true
+}
+
+// put stores body under key. A zero ttl stores it forever.
+func (c *Cache) put(key, host string, body []byte, ttl time.Duration) error {
+	entry := cacheEntry{Key: key, Host: host, StoredAt: time.Now(), Body: body}
+	if ttl > 0 {
+		entry.ExpiresAt = entry.StoredAt.Add(ttl)
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return err
+	}
+	// Write then rename so concurrent readers never see a partial entry.
+	tmp, err := os.CreateTemp(c.Dir, ".entry-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+// CacheStats summarizes the entries cached for a host.
+type CacheStats struct {
+	Host      string
+	Entries   int
+	Size      int64
+	Expired   int
+	Immutable int
+	Oldest    time.Time
+	Newest    time.Time
+}
+
+// entries calls fn with the path and entry of every cache file.
+func (c *Cache) entries(fn func(path string, size int64, entry *cacheEntry) error) error {
+	files, err := os.ReadDir(c.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(c.Dir, f.Name())
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		entry, err := readEntry(path)
+		if err != nil {
+			// Unreadable entries are reported as belonging to no host so
+			// clearing removes them.
+			entry = &cacheEntry{ExpiresAt: time.Unix(0, 0)}
+		}
+		if err := fn(path, info.Size(), entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stats returns the statistics of each host, sorted by host.
+func (c *Cache) Stats() ([]CacheStats, error) {
+	now := time.Now()
+	byHost := make(map[string]*CacheStats)
+	err := c.entries(func(path string, size int64, entry *cacheEntry) error {
+		s, ok := byHost[entry.Host]
+		if !ok {
+			s = &CacheStats{Host: entry.Host, Oldest: entry.StoredAt, Newest: entry.StoredAt}
+			byHost[entry.Host] = s
+		}
+		s.Entries++
+		s.Size += size
+		switch {
+		case entry.ExpiresAt.IsZero():
+			s.Immutable++
+		case entry.expired(now):
+			s.Expired++
+		}
+		if entry.StoredAt.Before(s.Oldest) {
+			s.Oldest = entry.StoredAt
+		}
+		if entry.StoredAt.After(s.Newest) {
+			s.Newest = entry.StoredAt
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]CacheStats, 0, len(byHost))
+	for _, s := range byHost {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Host < stats[j].Host })
+	return stats, nil
+}
+
+// Clear removes the cached entries, or only the expired ones, and returns
+// how many were removed.
+func (c *Cache) Clear(expiredOnly bool) (int, error) {
+	now := time.Now()
+	removed := 0
+	err := c.entries(func(path string, size int64, entry *cacheEntry) error {
+		if expiredOnly && !entry.expired(now) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+		return nil
+	})
+	return removed, err
+}
+
+// cacheKey returns the key of req and the time to live of its response. It
+// returns false when the response should not be cached.
+func (c *Client) cacheKey(req *http.Request) (string, time.Duration, bool) {
+	cache := c.Options.Cache
+	if cache == nil || cache.Disabled {
+		return "", 0, false
+	}
+	cacheable, immutable := req.Method == http.MethodGet, false
+	if c.Options.Cacheable != nil {
+		cacheable, immutable = c.Options.Cacheable(req)
+	}
+	ttl := c.Options.CacheTTL
+	switch {
+	case !cacheable:
+		return "", 0, false
+	case immutable:
+		ttl = 0
+	case ttl <= 0:
+		return "", 0, false
+	}
+
+	key := req.Method + " " + req.URL.String()
+	if req.Body != nil {
+		// Requests with a body, such as read-only contract calls, are keyed
+		// by their body too.
+		if req.GetBody == nil {
+			return "", 0, false
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return "", 0, false
+		}
+		defer body.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, body); err != nil {
+			return "", 0, false
+		}
+		key += " " + hex.EncodeToString(h.Sum(nil))
+	}
+	return key, ttl, true
+}
@@ -0,0 +1,325 @@
+// Package transport is the HTTP layer shared by the API clients. It retries
+// throttled and failed requests with exponential backoff, honors
+// Retry-After up to MaxBackoff, rate limits requests per host and adds the
+// User-Agent and credential headers to every request.
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultTimeout           = 30 * time.Second
+	DefaultUserAgent         = "teller"
+	DefaultMaxRetries        = 5
+	DefaultMinBackoff        = 500 * time.Millisecond
+	DefaultMaxBackoff        = 30 * time.Second
+	DefaultRequestsPerSecond = 5
+	DefaultBurst             = 10
+
+	// APIKeyHeader carries API keys, as expected by Hiro.
+	APIKeyHeader = "X-Api-Key"
+)
+
+// Options configures a Client. Zero values select the defaults.
+type Options struct {
+	// Timeout bounds each attempt, including reading the response body.
+	Timeout   time.Duration
+	UserAgent string
+	// MaxRetries is the number of retries after the first attempt. Set it
+	// to a negative value to disable retries.
+	MaxRetries int
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay between attempts, including the delay a
+	// server asks for with Retry-After.
+	MaxBackoff time.Duration
+	// RequestsPerSecond and Burst size the token bucket of each host. Set
+	// RequestsPerSecond to a negative value to disable rate limiting.
+	RequestsPerSecond float64
+	Burst             int
+	// Headers are added to every request, typically to carry an API key.
+	Headers http.Header
+	// Credentials returns more headers to add to every request. It is
+	// called once, before the first request, so secrets that are slow or
+	// interactive to look up are only fetched by commands that need them.
+	Credentials func() (http.Header, error)
+	// Cache stores successful responses when set. Responses are reused for
+	// CacheTTL, or forever when immutable, such as queries at a fixed block
+	// height. A zero CacheTTL only caches immutable responses.
+	Cache    *Cache
+	CacheTTL time.Duration
+	// Cacheable reports whether the response to req may be cached and
+	// whether it never changes. When nil, GET responses are cached and none
+	// are immutable.
+	Cacheable func(req *http.Request) (cacheable, immutable bool)
+}
+
+func (o Options) withDefaults() Options {
+	if o.Timeout == 0 {
+		o.Timeout = DefaultTimeout
+	}
+	if o.UserAgent == "" {
+		o.UserAgent = DefaultUserAgent
+	}
+	if o.MaxRetries == 0 {
+		o.MaxRetries = DefaultMaxRetries
+	}
+	if o.MinBackoff == 0 {
+		o.MinBackoff = DefaultMinBackoff
+	}
+	if o.MaxBackoff == 0 {
+		o.MaxBackoff = DefaultMaxBackoff
+	}
+	if o.RequestsPerSecond == 0 {
+		o.RequestsPerSecond = DefaultRequestsPerSecond
+	}
+	if o.Burst <= 0 {
+		o.Burst = DefaultBurst
+	}
+	return o
+}
+
+// Client sends requests on behalf of an API client.
+type Client struct {
+	HTTP    *http.Client
+	Options Options
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+
+	credentials func() (http.Header, error)
+}
+
+// New returns a client using opts.
+func New(opts Options) *Client {
+	opts = opts.withDefaults()
+	c := &Client{
+		HTTP:    &http.Client{Timeout: opts.Timeout},
+		Options: opts,
+		buckets: make(map[string]*bucket),
+	}
+	if opts.Credentials != nil {
+		c.credentials = sync.OnceValues(opts.Credentials)
+	}
+	return c
+}
+
+// StatusError is returned for responses other than 200 OK.
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Body       []byte
+}
+
+func (e *StatusError) Error() string {
+	return e.Status
+}
+
+// IsStatus reports whether err is a StatusError with the status code.
+func IsStatus(err error, code int) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == code
+}
+
+func (c *Client) bucket(host string) *bucket {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b, ok := c.buckets[host]
+	if !ok {
+		b = newBucket(c.Options.RequestsPerSecond, c.Options.Burst)
+		c.buckets[host] = b
+	}
+	return b
+}
+
+// Do sends req, waiting for the rate limiter of its host and retrying
+// network errors, 429 and 5xx responses. The caller must close the body of
+// the returned response. Requests with a body are only retried when it can
+// be replayed, which is the case for bodies built from bytes or strings.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	req.Header.Set("User-Agent", c.Options.UserAgent)
+	for name, values := range c.Options.Headers {
+		req.Header[name] = values
+	}
+	if c.credentials != nil {
+		headers, err := c.credentials()
+		if err != nil {
+			return nil, fmt.Errorf("transport: credentials: %w", err)
+		}
+		for name, values := range headers {
+			req.Header[name] = values
+		}
+	}
+
+	limiter := c.bucket(req.URL.Host)
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.Body != nil {
+			if req.GetBody == nil {
+				return nil, errors.New("transport: cannot retry request with a streamed body")
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+		if c.Options.RequestsPerSecond > 0 {
+			if err := limiter.wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		res, err := c.HTTP.Do(req)
+		if ctx.Err() != nil {
+			if res != nil {
+				res.Body.Close()
+			}
+			return nil, ctx.Err()
+		}
+		if !retryable(res, err) || attempt >= c.Options.MaxRetries {
+			return res, err
+		}
+
+		delay := c.backoff(attempt)
+		if res != nil {
+			if after, ok := retryAfter(res.Header.Get("Retry-After")); ok {
+				delay = min(after, c.Options.MaxBackoff)
+			}
+			if res.StatusCode == http.StatusTooManyRequests {
+				// Hold back every request to the host, not only this one.
+				limiter.pause(delay)
+			}
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func retryable(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff returns the delay before retry attempt+1, doubling from
+// MinBackoff up to MaxBackoff with up to 50% jitter.
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.Options.MinBackoff << attempt
+	if delay <= 0 || delay > c.Options.MaxBackoff {
+		delay = c.Options.MaxBackoff
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// retryAfter parses a Retry-After header given in seconds or as a date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Fetch sends req and returns the body of a 200 response, from the cache if
+// it holds a fresh copy. Other statuses return a *StatusError.
+func (c *Client) Fetch(req *http.Request) ([]byte, error) {
+	key, ttl, cacheable := c.cacheKey(req)
+	if cacheable {
+		if body, ok := c.Options.Cache.get(key); ok {
+			return body, nil
+		}
+	}
+
+	res, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, &StatusError{StatusCode: res.StatusCode, Status: res.Status, Body: body}
+	}
+	if cacheable {
+		// A response that cannot be cached is still a valid response.
+		c.Options.Cache.put(key, req.URL.Host, body, ttl)
+	}
+	return body, nil
+}
+
+// DoJSON sends req and decodes the JSON body of a 200 response into v.
+func (c *Client) DoJSON(req *http.Request, v any) error {
+	req.Header.Set("Accept", "application/json")
+	body, err := c.Fetch(req)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// GetJSON fetches url and decodes its JSON response into v.
+func (c *Client) GetJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	return c.DoJSON(req, v)
+}
+
+// PostJSON posts payload encoded as JSON to url and decodes the JSON
+// response into v.
+func (c *Client) PostJSON(ctx context.Context, url string, payload, v any) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.DoJSON(req, v)
+}
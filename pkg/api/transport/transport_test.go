@@ -0,0 +1,271 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testOptions retry quickly and do not rate limit.
+func testOptions() Options {
+	return Options{
+		MinBackoff:        time.Millisecond,
+		MaxBackoff:        20 * time.Millisecond,
+		RequestsPerSecond: -1,
+	}
+}
+
+// flaky serves the statuses in order, then 200 with the body ok.
+func flaky(t *testing.T, header http.Header, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		if n <= len(statuses) {
+			for name, values := range header {
+				w.Header()[name] = values
+			}
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Write(append([]byte("ok"), body...))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func get(t *testing.T, c *Client, url string) ([]byte, error) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.Fetch(req)
+}
+
+func TestRetry(t *testing.T) {
+	srv, calls := flaky(t, nil, http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusBadGateway)
+	body, err := get(t, New(testOptions()), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "ok" || calls.Load() != 4 {
+		t.Errorf("Fetch = %q after %d attempts, want ok after 4", body, calls.Load())
+	}
+}
+
+func TestRetryLimit(t *testing.T) {
+	opts := testOptions()
+	opts.MaxRetries = 2
+	srv, calls := flaky(t, nil, 500, 500, 500, 500)
+	_, err := get(t, New(opts), srv.URL)
+	if !IsStatus(err, http.StatusInternalServerError) {
+		t.Errorf("Fetch = %v, want the last 500", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("%d attempts, want 3", calls.Load())
+	}
+
+	opts.MaxRetries = -1
+	srv, calls = flaky(t, nil, 503)
+	if _, err := get(t, New(opts), srv.URL); !IsStatus(err, http.StatusServiceUnavailable) {
+		t.Errorf("Fetch without retries = %v, want 503", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("%d attempts without retries, want 1", calls.Load())
+	}
+}
+
+func TestNoRetry(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusNotFound, http.StatusNotImplemented} {
+		srv, calls := flaky(t, nil, status)
+		if _, err := get(t, New(testOptions()), srv.URL); !IsStatus(err, status) {
+			t.Errorf("Fetch = %v, want status %d", err, status)
+		}
+		if calls.Load() != 1 {
+			t.Errorf("status %d retried %d times", status, calls.Load()-1)
+		}
+	}
+}
+
+func TestRetryBody(t *testing.T) {
+	srv, calls := flaky(t, nil, http.StatusBadGateway)
+	req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(" body"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := New(testOptions()).Fetch(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "ok body" || calls.Load() != 2 {
+		t.Errorf("Fetch = %q after %d attempts, want the body replayed", body, calls.Load())
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	for _, tt := range []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"0", 0, true},
+		{"120", 2 * time.Minute, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"Wed, 21 Oct 2015 07:28:00 GMT", 0, true},
+	} {
+		got, ok := retryAfter(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if got, ok := retryAfter(future); !ok || got < 59*time.Minute || got > time.Hour {
+		t.Errorf("retryAfter(%q) = %v, %v, want about an hour", future, got, ok)
+	}
+}
+
+// A server asking to wait an hour is retried after MaxBackoff, and the pause
+// applies to the other requests to the host.
+func TestRetryAfterCapped(t *testing.T) {
+	srv, calls := flaky(t, http.Header{"Retry-After": {"3600"}}, http.StatusTooManyRequests)
+	opts := testOptions()
+	opts.RequestsPerSecond = 1000
+	c := New(opts)
+
+	start := time.Now()
+	if _, err := get(t, c, srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	elapsed := time.Since(start)
+	if calls.Load() != 2 {
+		t.Errorf("%d attempts, want 2", calls.Load())
+	}
+	if elapsed < opts.MaxBackoff || elapsed > time.Second {
+		t.Errorf("retried after %v, want the %v cap", elapsed, opts.MaxBackoff)
+	}
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	if b := c.bucket(host); b.until.IsZero() {
+		t.Error("429 did not pause the host")
+	}
+}
+
+func TestRetryCanceled(t *testing.T) {
+	srv, _ := flaky(t, http.Header{"Retry-After": {"10"}}, 503, 503)
+	opts := testOptions()
+	opts.MaxBackoff = time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if _, err := New(opts).Fetch(req); err != context.DeadlineExceeded {
+		t.Errorf("Fetch = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("canceled request returned after %v", elapsed)
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer srv.Close()
+
+	var lookups atomic.Int32
+	opts := testOptions()
+	opts.UserAgent = "teller/test"
+	opts.Headers = http.Header{"X-Static": {"1"}}
+	opts.Credentials = func() (http.Header, error) {
+		lookups.Add(1)
+		return http.Header{APIKeyHeader: {"secret"}}, nil
+	}
+	c := New(opts)
+	for range 2 {
+		if _, err := get(t, c, srv.URL); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got.Get("User-Agent") != "teller/test" || got.Get("X-Static") != "1" || got.Get(APIKeyHeader) != "secret" {
+		t.Errorf("headers = %v", got)
+	}
+	if lookups.Load() != 1 {
+		t.Errorf("credentials looked up %d times, want once", lookups.Load())
+	}
+}
+
+func TestCache(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte{byte('0' + n)})
+	}))
+	defer srv.Close()
+
+	opts := testOptions()
+	opts.Cache = NewCache(t.TempDir())
+	opts.CacheTTL = time.Hour
+	opts.Cacheable = func(req *http.Request) (bool, bool) {
+		return req.URL.Path != "/live", req.URL.Path == "/pinned"
+	}
+	c := New(opts)
+
+	fetchTwice := func(path string) (string, string) {
+		first, _ := get(t, c, srv.URL+path)
+		second, _ := get(t, c, srv.URL+path)
+		return string(first), string(second)
+	}
+	if first, second := fetchTwice("/ttl"); first != second {
+		t.Errorf("response with a ttl fetched again: %s then %s", first, second)
+	}
+	if first, second := fetchTwice("/live"); first == second {
+		t.Errorf("uncacheable response reused: %s", first)
+	}
+	if first, second := fetchTwice("/pinned"); first != second {
+		t.Errorf("immutable response fetched again: %s then %s", first, second)
+	}
+	before := calls.Load()
+	fetchTwice("/missing")
+	if calls.Load()-before != 2 {
+		t.Error("error response was cached")
+	}
+
+	stats, err := opts.Cache.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 1 || stats[0].Entries != 2 || stats[0].Immutable != 1 {
+		t.Errorf("Stats = %+v, want 2 entries of which 1 immutable", stats)
+	}
+
+	// Without a ttl only immutable responses are cached.
+	opts.CacheTTL = 0
+	c = New(opts)
+	if first, second := fetchTwice("/other"); first == second {
+		t.Errorf("response cached without a ttl: %s", first)
+	}
+
+	opts.Cache.Disabled = true
+	c = New(opts)
+	if first, second := fetchTwice("/pinned"); first == second {
+		t.Errorf("disabled cache reused %s", first)
+	}
+}
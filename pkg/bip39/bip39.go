@@ -0,0 +1,114 @@
+// Package bip39 implements BIP39 mnemonic phrases with the English wordlist.
+package bip39
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+//go:embed english.txt
+var english string
+
+var (
+	wordlist = strings.Fields(english)
+	indexes  = func() map[string]int {
+		m := make(map[string]int, len(wordlist))
+		for i, w := range wordlist {
+			m[w] = i
+		}
+		return m
+	}()
+)
+
+var ErrInvalidChecksum = errors.New("bip39: invalid mnemonic checksum")
+
+// NewEntropy returns random entropy for a mnemonic of the given number of
+// words: 12, 15, 18, 21 or 24.
+func NewEntropy(words int) ([]byte, error) {
+	if words < 12 || words > 24 || words%3 != 0 {
+		return nil, fmt.Errorf("bip39: invalid word count %d", words)
+	}
+	entropy := make([]byte, words*4/3)
+	if _, err := rand.Read(entropy); err != nil {
+		return nil, err
+	}
+	return entropy, nil
+}
+
+// NewMnemonic encodes entropy of 16 to 32 bytes as a mnemonic phrase.
+func NewMnemonic(entropy []byte) (string, error) {
+	bits := len(entropy) * 8
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return "", fmt.Errorf("bip39: invalid entropy length %d", len(entropy))
+	}
+	checksumBits := bits / 32
+	checksum := sha256.Sum256(entropy)
+
+	// The phrase encodes the entropy followed by the first bits of its hash.
+	n := new(big.Int).SetBytes(entropy)
+	n.Lsh(n, uint(checksumBits))
+	n.Or(n, big.NewInt(int64(checksum[0]>>(8-checksumBits))))
+
+	count := (bits + checksumBits) / 11
+	words := make([]string, count)
+	mask := big.NewInt(2047)
+	for i := count - 1; i >= 0; i-- {
+		words[i] = wordlist[new(big.Int).And(n, mask).Int64()]
+		n.Rsh(n, 11)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// EntropyFromMnemonic decodes a phrase back to its entropy and verifies its
+// checksum.
+func EntropyFromMnemonic(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, fmt.Errorf("bip39: invalid word count %d", len(words))
+	}
+
+	n := new(big.Int)
+	for _, w := range words {
+		i, ok := indexes[strings.ToLower(w)]
+		if !ok {
+			return nil, fmt.Errorf("bip39: %q is not in the wordlist", w)
+		}
+		n.Lsh(n, 11)
+		n.Or(n, big.NewInt(int64(i)))
+	}
+
+	checksumBits := len(words) / 3
+	checksum := new(big.Int).And(n, big.NewInt(int64(1<<checksumBits-1))).Int64()
+	n.Rsh(n, uint(checksumBits))
+
+	entropy := make([]byte, len(words)*4/3)
+	n.FillBytes(entropy)
+	hash := sha256.Sum256(entropy)
+	if int64(hash[0]>>(8-checksumBits)) != checksum {
+		return nil, ErrInvalidChecksum
+	}
+	return entropy, nil
+}
+
+// IsValid reports whether the phrase uses wordlist words and has a valid
+// checksum.
+func IsValid(mnemonic string) bool {
+	_, err := EntropyFromMnemonic(mnemonic)
+	return err == nil
+}
+
+// NewSeed derives the 64 byte seed of a phrase and optional passphrase.
+// Both are used as given; BIP39 asks for NFKD normalization, which only
+// matters for non-ASCII passphrases.
+func NewSeed(mnemonic, passphrase string) []byte {
+	normalized := strings.Join(strings.Fields(strings.ToLower(mnemonic)), " ")
+	return pbkdf2.Key([]byte(normalized), []byte("mnemonic"+passphrase), 2048, 64, sha512.New)
+}
@@ -0,0 +1,530 @@
+package clarity
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TypeKind names a Clarity type as it appears in a contract ABI.
+type TypeKind string
+
+const (
+	KindInt            TypeKind = "int128"
+	KindUInt           TypeKind = "uint128"
+	KindBool           TypeKind = "bool"
+	KindPrincipal      TypeKind = "principal"
+	KindNone           TypeKind = "none"
+	KindTraitReference TypeKind = "trait_reference"
+	KindBuffer         TypeKind = "buffer"
+	KindStringASCII    TypeKind = "string-ascii"
+	KindStringUTF8     TypeKind = "string-utf8"
+	KindOptional       TypeKind = "optional"
+	KindResponse       TypeKind = "response"
+	KindList           TypeKind = "list"
+	KindTuple          TypeKind = "tuple"
+)
+
+// TypeSignature describes a Clarity type. Length applies to buffers,
+// strings and lists; Elem to optionals and lists; Ok and Err to responses
+// and Fields to tuples.
+type TypeSignature struct {
+	Kind   TypeKind
+	Length int
+	Elem   *TypeSignature
+	Ok     *TypeSignature
+	Err    *TypeSignature
+	Fields []TupleFieldType
+}
+
+type TupleFieldType struct {
+	Name string        `json:"name"`
+	Type TypeSignature `json:"type"`
+}
+
+// UnmarshalJSON decodes the ABI type representation used by the Stacks node,
+// which is either a bare string such as "uint128" or a single key object
+// such as {"buffer":{"length":32}}.
+func (t *TypeSignature) UnmarshalJSON(data []byte) error {
+	var simple string
+	if err := json.Unmarshal(data, &simple); err == nil {
+		switch kind := TypeKind(simple); kind {
+		case KindInt, KindUInt, KindBool, KindPrincipal, KindNone, KindTraitReference:
+			*t = TypeSignature{Kind: kind}
+			return nil
+		default:
+			return fmt.Errorf("clarity: unknown abi type %q", simple)
+		}
+	}
+
+	var compound map[string]json.RawMessage
+	if err := json.Unmarshal(data, &compound); err != nil {
+		return fmt.Errorf("clarity: invalid abi type %s: %w", data, err)
+	}
+	if len(compound) != 1 {
+		return fmt.Errorf("clarity: invalid abi type %s", data)
+	}
+	for key, raw := range compound {
+		kind := TypeKind(key)
+		switch kind {
+		case KindBuffer, KindStringASCII, KindStringUTF8:
+			var v struct {
+				Length int `json:"length"`
+			}
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return err
+			}
+			*t = TypeSignature{Kind: kind, Length: v.Length}
+		case KindOptional:
+			var elem TypeSignature
+			if err := json.Unmarshal(raw, &elem); err != nil {
+				return err
+			}
+			*t = TypeSignature{Kind: kind, Elem: &elem}
+		case KindResponse:
+			var v struct {
+				Ok    TypeSignature `json:"ok"`
+				Error TypeSignature `json:"error"`
+			}
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return err
+			}
+			*t = TypeSignature{Kind: kind, Ok: &v.Ok, Err: &v.Error}
+		case KindList:
+			var v struct {
+				Type   TypeSignature `json:"type"`
+				Length int           `json:"length"`
+			}
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return err
+			}
+			*t = TypeSignature{Kind: kind, Elem: &v.Type, Length: v.Length}
+		case KindTuple:
+			var fields []TupleFieldType
+			if err := json.Unmarshal(raw, &fields); err != nil {
+				return err
+			}
+			*t = TypeSignature{Kind: kind, Fields: fields}
+		default:
+			return fmt.Errorf("clarity: unknown abi type %q", key)
+		}
+	}
+	return nil
+}
+
+// MarshalJSON encodes the type back into the node ABI representation.
+func (t TypeSignature) MarshalJSON() ([]byte, error) {
+	switch t.Kind {
+	case KindBuffer, KindStringASCII, KindStringUTF8:
+		return json.Marshal(map[string]any{string(t.Kind): map[string]int{"length": t.Length}})
+	case KindOptional:
+		return json.Marshal(map[string]any{string(t.Kind): t.Elem})
+	case KindResponse:
+		return json.Marshal(map[string]any{string(t.Kind): map[string]any{"ok": t.Ok, "error": t.Err}})
+	case KindList:
+		return json.Marshal(map[string]any{string(t.Kind): map[string]any{"type": t.Elem, "length": t.Length}})
+	case KindTuple:
+		return json.Marshal(map[string]any{string(t.Kind): t.Fields})
+	default:
+		return json.Marshal(string(t.Kind))
+	}
+}
+
+// String renders the type using Clarity syntax, e.g. (response bool uint).
+func (t TypeSignature) String() string {
+	switch t.Kind {
+	case KindInt:
+		return "int"
+	case KindUInt:
+		return "uint"
+	case KindBuffer:
+		return fmt.Sprintf("(buff %d)", t.Length)
+	case KindStringASCII:
+		return fmt.Sprintf("(string-ascii %d)", t.Length)
+	case KindStringUTF8:
+		return fmt.Sprintf("(string-utf8 %d)", t.Length)
+	case KindOptional:
+		return fmt.Sprintf("(optional %s)", t.Elem)
+	case KindResponse:
+		return fmt.Sprintf("(response %s %s)", t.Ok, t.Err)
+	case KindList:
+		return fmt.Sprintf("(list %d %s)", t.Length, t.Elem)
+	case KindTuple:
+		fields := make([]string, 0, len(t.Fields))
+		for _, f := range t.Fields {
+			fields = append(fields, fmt.Sprintf("(%s %s)", f.Name, f.Type))
+		}
+		return "(tuple " + strings.Join(fields, " ") + ")"
+	case KindTraitReference:
+		return "<trait>"
+	default:
+		return string(t.Kind)
+	}
+}
+
+// Admits reports whether v is a valid value of type t.
+func (t TypeSignature) Admits(v Value) error {
+	mismatch := func() error { return fmt.Errorf("expected %s, got %s", t, v) }
+	switch t.Kind {
+	case KindInt:
+		if _, ok := v.(Int); !ok {
+			return mismatch()
+		}
+	case KindUInt:
+		if _, ok := v.(UInt); !ok {
+			return mismatch()
+		}
+	case KindBool:
+		if _, ok := v.(Bool); !ok {
+			return mismatch()
+		}
+	case KindPrincipal:
+		switch v.(type) {
+		case StandardPrincipal, ContractPrincipal:
+		default:
+			return mismatch()
+		}
+	case KindTraitReference:
+		if _, ok := v.(ContractPrincipal); !ok {
+			return mismatch()
+		}
+	case KindNone:
+		if _, ok := v.(None); !ok {
+			return mismatch()
+		}
+	case KindBuffer:
+		b, ok := v.(Buffer)
+		if !ok {
+			return mismatch()
+		}
+		if len(b) > t.Length {
+			return fmt.Errorf("buffer of %d bytes exceeds %s", len(b), t)
+		}
+	case KindStringASCII:
+		s, ok := v.(StringASCII)
+		if !ok {
+			return mismatch()
+		}
+		if len(s) > t.Length {
+			return fmt.Errorf("string of %d characters exceeds %s", len(s), t)
+		}
+		for i := 0; i < len(s); i++ {
+			if s[i] >= 0x80 {
+				return fmt.Errorf("%s contains non-ascii byte 0x%02x", t, s[i])
+			}
+		}
+	case KindStringUTF8:
+		s, ok := v.(StringUTF8)
+		if !ok {
+			return mismatch()
+		}
+		if n := len([]rune(string(s))); n > t.Length {
+			return fmt.Errorf("string of %d characters exceeds %s", n, t)
+		}
+	case KindOptional:
+		switch v := v.(type) {
+		case None:
+		case Some:
+			return t.Elem.Admits(v.Value)
+		default:
+			return mismatch()
+		}
+	case KindResponse:
+		switch v := v.(type) {
+		case ResponseOk:
+			return t.Ok.Admits(v.Value)
+		case ResponseErr:
+			return t.Err.Admits(v.Value)
+		default:
+			return mismatch()
+		}
+	case KindList:
+		list, ok := v.(List)
+		if !ok {
+			return mismatch()
+		}
+		if len(list) > t.Length {
+			return fmt.Errorf("list of %d items exceeds %s", len(list), t)
+		}
+		for i, item := range list {
+			if err := t.Elem.Admits(item); err != nil {
+				return fmt.Errorf("list item %d: %w", i, err)
+			}
+		}
+	case KindTuple:
+		tuple, ok := v.(Tuple)
+		if !ok {
+			return mismatch()
+		}
+		if len(tuple) != len(t.Fields) {
+			return mismatch()
+		}
+		for _, f := range t.Fields {
+			item, ok := tuple[f.Name]
+			if !ok {
+				return fmt.Errorf("tuple is missing field %q", f.Name)
+			}
+			if err := f.Type.Admits(item); err != nil {
+				return fmt.Errorf("tuple field %q: %w", f.Name, err)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown type %s", t.Kind)
+	}
+	return nil
+}
+
+// AdmitsType reports whether every value of type other is also a value of
+// type t, which is how Clarity checks a function against a trait. The none
+// kind stands for a type with no values, such as the error of a response
+// that never fails, and is admitted by any type.
+func (t TypeSignature) AdmitsType(other TypeSignature) bool {
+	if other.Kind == KindNone {
+		return true
+	}
+	if t.Kind != other.Kind {
+		return false
+	}
+	switch t.Kind {
+	case KindBuffer, KindStringASCII, KindStringUTF8:
+		return other.Length <= t.Length
+	case KindOptional:
+		return t.Elem.AdmitsType(*other.Elem)
+	case KindResponse:
+		return t.Ok.AdmitsType(*other.Ok) && t.Err.AdmitsType(*other.Err)
+	case KindList:
+		return other.Length <= t.Length && t.Elem.AdmitsType(*other.Elem)
+	case KindTuple:
+		if len(t.Fields) != len(other.Fields) {
+			return false
+		}
+		for _, f := range t.Fields {
+			found := false
+			for _, o := range other.Fields {
+				if o.Name == f.Name {
+					found = f.Type.AdmitsType(o.Type)
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ParseTyped parses a literal for a value of type t. Besides full Clarity
+// literals it accepts the obvious shorthands for simple types: 100 for a
+// uint, SP... without the tick for a principal, bare text for strings and
+// hex without 0x for buffers.
+func ParseTyped(literal string, t TypeSignature) (Value, error) {
+	v, err := Parse(literal)
+	if err == nil && t.Admits(v) == nil {
+		return v, nil
+	}
+
+	var coerced Value
+	var cerr error
+	switch t.Kind {
+	case KindUInt:
+		coerced, cerr = parseUInt(literal)
+	case KindPrincipal, KindTraitReference:
+		coerced, cerr = NewPrincipal(literal)
+	// Text that parses as another value, such as 42 or true, is still text
+	// for a string argument. A string literal that is too long is not.
+	case KindStringASCII:
+		if _, ok := v.(StringASCII); !ok {
+			coerced = StringASCII(literal)
+		}
+	case KindStringUTF8:
+		switch s := v.(type) {
+		case StringASCII:
+			coerced = StringUTF8(s)
+		case StringUTF8:
+		default:
+			coerced = StringUTF8(literal)
+		}
+	case KindBuffer:
+		coerced, cerr = Parse("0x" + literal)
+	}
+	if coerced != nil && cerr == nil {
+		if cerr = t.Admits(coerced); cerr == nil {
+			return coerced, nil
+		}
+	}
+
+	// The shorthand explains the error better when the type has one, e.g. a
+	// principal with a bad checksum or text too long for a string.
+	if cerr != nil {
+		return nil, cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, t.Admits(v)
+}
+
+// Access levels of contract functions.
+const (
+	AccessPublic   = "public"
+	AccessReadOnly = "read_only"
+	AccessPrivate  = "private"
+)
+
+// FunctionArg is a named function argument.
+type FunctionArg struct {
+	Name string        `json:"name"`
+	Type TypeSignature `json:"type"`
+}
+
+type FunctionOutput struct {
+	Type TypeSignature `json:"type"`
+}
+
+// Function is a function definition from the contract ABI.
+type Function struct {
+	Name    string         `json:"name"`
+	Access  string         `json:"access"`
+	Args    []FunctionArg  `json:"args"`
+	Outputs FunctionOutput `json:"outputs"`
+}
+
+// Signature renders the function as its Clarity define form, without body.
+func (f Function) Signature() string {
+	define := "define-private"
+	switch f.Access {
+	case AccessPublic:
+		define = "define-public"
+	case AccessReadOnly:
+		define = "define-read-only"
+	}
+	return fmt.Sprintf("(%s (%s) %s)", define, strings.TrimSpace(f.Name+" "+f.ArgsString()), f.Outputs.Type)
+}
+
+// ArgsString renders the arguments as (name type) pairs.
+func (f Function) ArgsString() string {
+	args := make([]string, 0, len(f.Args))
+	for _, a := range f.Args {
+		args = append(args, fmt.Sprintf("(%s %s)", a.Name, a.Type))
+	}
+	return strings.Join(args, " ")
+}
+
+// ParseArgs parses one literal per argument using ParseTyped.
+func (f Function) ParseArgs(literals []string) ([]Value, error) {
+	if len(literals) != len(f.Args) {
+		return nil, fmt.Errorf("%s expects %d arguments, got %d: %s", f.Name, len(f.Args), len(literals), f.ArgsString())
+	}
+	values := make([]Value, 0, len(literals))
+	for i, literal := range literals {
+		v, err := ParseTyped(literal, f.Args[i].Type)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %w", f.Args[i].Name, err)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+type Variable struct {
+	Name   string        `json:"name"`
+	Type   TypeSignature `json:"type"`
+	Access string        `json:"access"`
+}
+
+type Map struct {
+	Name  string        `json:"name"`
+	Key   TypeSignature `json:"key"`
+	Value TypeSignature `json:"value"`
+}
+
+type FungibleToken struct {
+	Name string `json:"name"`
+}
+
+type NonFungibleToken struct {
+	Name string        `json:"name"`
+	Type TypeSignature `json:"type"`
+}
+
+// ContractInterface is the ABI of a deployed contract. ImplementedTraits is
+// not part of the node ABI and is filled from the contract source.
+type ContractInterface struct {
+	Functions         []Function         `json:"functions"`
+	Variables         []Variable         `json:"variables"`
+	Maps              []Map              `json:"maps"`
+	FungibleTokens    []FungibleToken    `json:"fungible_tokens"`
+	NonFungibleTokens []NonFungibleToken `json:"non_fungible_tokens"`
+	Epoch             string             `json:"epoch,omitempty"`
+	ClarityVersion    string             `json:"clarity_version,omitempty"`
+	ImplementedTraits []string           `json:"implemented_traits,omitempty"`
+}
+
+// ParseContractInterface decodes the JSON ABI returned by the node.
+func ParseContractInterface(data []byte) (ContractInterface, error) {
+	var abi ContractInterface
+	if err := json.Unmarshal(data, &abi); err != nil {
+		return ContractInterface{}, fmt.Errorf("clarity: invalid contract interface: %w", err)
+	}
+	return abi, nil
+}
+
+// Function returns the function called name.
+func (c ContractInterface) Function(name string) (Function, bool) {
+	for _, f := range c.Functions {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Function{}, false
+}
+
+var accessOrder = map[string]int{AccessPublic: 0, AccessReadOnly: 1, AccessPrivate: 2}
+
+// SortedFunctions returns the functions grouped by access (public,
+// read-only, private) and sorted by name within each group.
+func (c ContractInterface) SortedFunctions() []Function {
+	functions := append([]Function{}, c.Functions...)
+	sort.SliceStable(functions, func(i, j int) bool {
+		if accessOrder[functions[i].Access] != accessOrder[functions[j].Access] {
+			return accessOrder[functions[i].Access] < accessOrder[functions[j].Access]
+		}
+		return functions[i].Name < functions[j].Name
+	})
+	return functions
+}
+
+var implTraitPattern = regexp.MustCompile(`\(\s*impl-trait\s+'?([A-Za-z0-9]*\.[A-Za-z0-9\-_]+\.[A-Za-z0-9\-_]+)\s*\)`)
+
+// ParseImplementedTraits returns the trait identifiers named by impl-trait
+// forms in a contract source, e.g. SP....sip-010-trait-ft-standard.sip-010-trait.
+// Traits defined by a contract from the same deployer keep their leading dot.
+func ParseImplementedTraits(source string) []string {
+	var traits []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(source, "\n") {
+		if i := strings.Index(line, ";;"); i >= 0 {
+			line = line[:i]
+		}
+		for _, m := range implTraitPattern.FindAllStringSubmatch(line, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				traits = append(traits, m[1])
+			}
+		}
+	}
+	return traits
+}
+
+// AccessLabel returns a human readable access level.
+func AccessLabel(access string) string {
+	switch access {
+	case AccessReadOnly:
+		return "read-only"
+	default:
+		return access
+	}
+}
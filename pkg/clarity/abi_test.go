@@ -0,0 +1,296 @@
+package clarity
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// loadABI reads the interface of a SIP-010 token as returned by the node.
+func loadABI(t *testing.T) ContractInterface {
+	t.Helper()
+	data, err := os.ReadFile("testdata/sip010-token.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	abi, err := ParseContractInterface(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return abi
+}
+
+func typeOf(t *testing.T, s string) TypeSignature {
+	t.Helper()
+	var ts TypeSignature
+	if err := json.Unmarshal([]byte(s), &ts); err != nil {
+		t.Fatal(err)
+	}
+	return ts
+}
+
+func TestParseContractInterface(t *testing.T) {
+	abi := loadABI(t)
+	if len(abi.Functions) != 11 || len(abi.Variables) != 3 || len(abi.Maps) != 1 || len(abi.FungibleTokens) != 1 {
+		t.Fatalf("decoded %d functions, %d variables, %d maps, %d tokens", len(abi.Functions), len(abi.Variables), len(abi.Maps), len(abi.FungibleTokens))
+	}
+	if abi.Epoch != "Epoch24" || abi.ClarityVersion != "Clarity2" {
+		t.Errorf("epoch %s, clarity %s", abi.Epoch, abi.ClarityVersion)
+	}
+
+	for _, tt := range []struct {
+		name, signature string
+	}{
+		{"transfer", "(define-public (transfer (amount uint) (sender principal) (recipient principal) (memo (optional (buff 34)))) (response bool uint))"},
+		{"transfer-many", "(define-public (transfer-many (recipients (list 200 (tuple (amount uint) (memo (optional (buff 34))) (to principal))))) (response bool uint))"},
+		{"get-name", "(define-read-only (get-name) (response (string-ascii 7) none))"},
+		{"get-token-uri", "(define-read-only (get-token-uri) (response (optional (string-utf8 256)) none))"},
+		{"mint-internal", "(define-private (mint-internal (amount uint) (recipient principal)) (response bool uint))"},
+	} {
+		f, ok := abi.Function(tt.name)
+		if !ok {
+			t.Errorf("function %s not found", tt.name)
+			continue
+		}
+		if got := f.Signature(); got != tt.signature {
+			t.Errorf("%s signature = %s, want %s", tt.name, got, tt.signature)
+		}
+	}
+	if got := abi.Maps[0].Value.String(); got != "(tuple (allowance uint) (enabled bool))" {
+		t.Errorf("map value = %s", got)
+	}
+
+	var names []string
+	for _, f := range abi.SortedFunctions() {
+		names = append(names, f.Name)
+	}
+	want := []string{"mint", "set-token-uri", "transfer", "transfer-many", "get-balance", "get-decimals", "get-name", "get-symbol", "get-token-uri", "get-total-supply", "mint-internal"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("SortedFunctions = %v, want %v", names, want)
+	}
+
+	// Types encode back to the node representation.
+	data, err := json.Marshal(abi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := ParseContractInterface(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again, abi) {
+		t.Error("interface changed after a JSON round trip")
+	}
+}
+
+func TestTypeSignatureInvalid(t *testing.T) {
+	for _, s := range []string{
+		`"int256"`,
+		`"buffer"`,
+		`{"string-ascii":{"length":1},"string-utf8":{"length":1}}`,
+		`{"map":{"length":1}}`,
+		`{"buffer":{"length":"32"}}`,
+		`{"tuple":{"name":"a"}}`,
+		`42`,
+	} {
+		var ts TypeSignature
+		if err := json.Unmarshal([]byte(s), &ts); err == nil {
+			t.Errorf("Unmarshal(%s) = %s, want an error", s, ts)
+		}
+	}
+}
+
+func TestAdmits(t *testing.T) {
+	principal := mustPrincipal(t, "SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7")
+	contract := mustPrincipal(t, "SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7.token")
+	for _, tt := range []struct {
+		typ   string
+		value Value
+		ok    bool
+	}{
+		{`"uint128"`, NewUInt(1), true},
+		{`"uint128"`, NewInt(1), false},
+		{`"int128"`, NewInt(-1), true},
+		{`"bool"`, Bool(true), true},
+		{`"principal"`, principal, true},
+		{`"principal"`, contract, true},
+		{`"trait_reference"`, contract, true},
+		{`"trait_reference"`, principal, false},
+		{`{"buffer":{"length":2}}`, Buffer{1, 2}, true},
+		{`{"buffer":{"length":2}}`, Buffer{1, 2, 3}, false},
+		{`{"string-ascii":{"length":5}}`, StringASCII("hello"), true},
+		{`{"string-ascii":{"length":4}}`, StringASCII("hello"), false},
+		{`{"string-ascii":{"length":5}}`, StringUTF8("hello"), false},
+		// The length of a utf8 string counts characters, not bytes.
+		{`{"string-utf8":{"length":2}}`, StringUTF8("é😀"), true},
+		{`{"string-utf8":{"length":1}}`, StringUTF8("é😀"), false},
+		{`{"optional":"uint128"}`, None{}, true},
+		{`{"optional":"uint128"}`, Some{Value: NewUInt(1)}, true},
+		{`{"optional":"uint128"}`, Some{Value: NewInt(1)}, false},
+		{`{"response":{"ok":"bool","error":"uint128"}}`, ResponseOk{Value: Bool(true)}, true},
+		{`{"response":{"ok":"bool","error":"uint128"}}`, ResponseErr{Value: NewUInt(1)}, true},
+		{`{"response":{"ok":"bool","error":"uint128"}}`, ResponseErr{Value: Bool(true)}, false},
+		{`{"list":{"type":"uint128","length":2}}`, List{NewUInt(1), NewUInt(2)}, true},
+		{`{"list":{"type":"uint128","length":2}}`, List{NewUInt(1), NewUInt(2), NewUInt(3)}, false},
+		{`{"list":{"type":"uint128","length":2}}`, List{NewUInt(1), NewInt(2)}, false},
+		{`{"tuple":[{"name":"a","type":"uint128"},{"name":"b","type":"bool"}]}`, Tuple{"a": NewUInt(1), "b": Bool(false)}, true},
+		{`{"tuple":[{"name":"a","type":"uint128"},{"name":"b","type":"bool"}]}`, Tuple{"a": NewUInt(1)}, false},
+		{`{"tuple":[{"name":"a","type":"uint128"},{"name":"b","type":"bool"}]}`, Tuple{"a": NewUInt(1), "c": Bool(false)}, false},
+		{`{"tuple":[{"name":"a","type":"uint128"},{"name":"b","type":"bool"}]}`, Tuple{"a": NewInt(1), "b": Bool(false)}, false},
+	} {
+		err := typeOf(t, tt.typ).Admits(tt.value)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s admits %s = %v, want %v", tt.typ, tt.value, err, tt.ok)
+		}
+	}
+}
+
+func TestAdmitsType(t *testing.T) {
+	for _, tt := range []struct {
+		t, other string
+		ok       bool
+	}{
+		{`"uint128"`, `"uint128"`, true},
+		{`"uint128"`, `"int128"`, false},
+		{`"uint128"`, `"none"`, true},
+		{`{"string-ascii":{"length":32}}`, `{"string-ascii":{"length":7}}`, true},
+		{`{"string-ascii":{"length":7}}`, `{"string-ascii":{"length":32}}`, false},
+		{`{"string-ascii":{"length":32}}`, `{"string-utf8":{"length":7}}`, false},
+		{`{"buffer":{"length":34}}`, `{"buffer":{"length":34}}`, true},
+		{`{"response":{"ok":{"string-ascii":{"length":32}},"error":"uint128"}}`, `{"response":{"ok":{"string-ascii":{"length":7}},"error":"none"}}`, true},
+		{`{"response":{"ok":"uint128","error":"uint128"}}`, `{"response":{"ok":"uint128","error":"int128"}}`, false},
+		{`{"optional":{"buffer":{"length":34}}}`, `{"optional":{"buffer":{"length":35}}}`, false},
+		{`{"list":{"type":"uint128","length":10}}`, `{"list":{"type":"uint128","length":5}}`, true},
+		{`{"list":{"type":"uint128","length":5}}`, `{"list":{"type":"uint128","length":10}}`, false},
+		{`{"tuple":[{"name":"a","type":"uint128"}]}`, `{"tuple":[{"name":"a","type":"uint128"}]}`, true},
+		{`{"tuple":[{"name":"a","type":"uint128"}]}`, `{"tuple":[{"name":"b","type":"uint128"}]}`, false},
+		{`{"tuple":[{"name":"a","type":"uint128"}]}`, `{"tuple":[{"name":"a","type":"uint128"},{"name":"b","type":"uint128"}]}`, false},
+	} {
+		if got := typeOf(t, tt.t).AdmitsType(typeOf(t, tt.other)); got != tt.ok {
+			t.Errorf("%s admits type %s = %v, want %v", tt.t, tt.other, got, tt.ok)
+		}
+	}
+}
+
+func TestParseTyped(t *testing.T) {
+	for _, tt := range []struct {
+		literal, typ, want string
+	}{
+		{"100", `"uint128"`, "u100"},
+		{"u100", `"uint128"`, "u100"},
+		{"-5", `"int128"`, "-5"},
+		{"true", `"bool"`, "true"},
+		{"SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7", `"principal"`, "'SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7"},
+		{"'SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7.token", `"principal"`, "'SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7.token"},
+		{"SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7.token", `"trait_reference"`, "'SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7.token"},
+		{"deadbeef", `{"buffer":{"length":4}}`, "0xdeadbeef"},
+		{"0xdeadbeef", `{"buffer":{"length":4}}`, "0xdeadbeef"},
+		{"hello world", `{"string-ascii":{"length":32}}`, `"hello world"`},
+		{`"hello"`, `{"string-ascii":{"length":32}}`, `"hello"`},
+		// Text that parses as another value is still text for a string.
+		{"42", `{"string-ascii":{"length":32}}`, `"42"`},
+		{"true", `{"string-ascii":{"length":32}}`, `"true"`},
+		{"u7", `{"string-ascii":{"length":32}}`, `"u7"`},
+		{"none", `{"string-ascii":{"length":32}}`, `"none"`},
+		{"42", `{"string-utf8":{"length":32}}`, `u"42"`},
+		{"false", `{"string-utf8":{"length":32}}`, `u"false"`},
+		{`"hello"`, `{"string-utf8":{"length":32}}`, `u"hello"`},
+		{"héllo", `{"string-utf8":{"length":32}}`, `u"h\u{e9}llo"`},
+		{"none", `{"optional":"uint128"}`, "none"},
+		{"(some u1)", `{"optional":"uint128"}`, "(some u1)"},
+		{"(list u1 u2)", `{"list":{"type":"uint128","length":2}}`, "(list u1 u2)"},
+	} {
+		v, err := ParseTyped(tt.literal, typeOf(t, tt.typ))
+		if err != nil {
+			t.Errorf("ParseTyped(%s, %s) = %v", tt.literal, tt.typ, err)
+			continue
+		}
+		if v.String() != tt.want {
+			t.Errorf("ParseTyped(%s, %s) = %s, want %s", tt.literal, tt.typ, v, tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		literal, typ string
+	}{
+		{"-5", `"uint128"`},
+		{"yes", `"bool"`},
+		{"SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ8", `"principal"`},
+		{"SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7", `"trait_reference"`},
+		{"deadbeef00", `{"buffer":{"length":4}}`},
+		{"xyz", `{"buffer":{"length":4}}`},
+		{"hello world", `{"string-ascii":{"length":5}}`},
+		{`"hello world"`, `{"string-ascii":{"length":5}}`},
+		{"12345678", `{"string-ascii":{"length":5}}`},
+		{"héllo", `{"string-ascii":{"length":32}}`},
+		{"(list u1 u2 u3)", `{"list":{"type":"uint128","length":2}}`},
+	} {
+		if v, err := ParseTyped(tt.literal, typeOf(t, tt.typ)); err == nil {
+			t.Errorf("ParseTyped(%s, %s) = %s, want an error", tt.literal, tt.typ, v)
+		}
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	transfer, _ := loadABI(t).Function("transfer")
+	args, err := transfer.ParseArgs([]string{"100", "SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7", "SP000000000000000000002Q6VF78", "none"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, a := range args {
+		got = append(got, a.String())
+	}
+	want := []string{"u100", "'SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7", "'SP000000000000000000002Q6VF78", "none"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseArgs = %v, want %v", got, want)
+	}
+
+	if _, err := transfer.ParseArgs([]string{"100"}); err == nil || !strings.Contains(err.Error(), "expects 4 arguments") {
+		t.Errorf("ParseArgs with one argument = %v", err)
+	}
+	if _, err := transfer.ParseArgs([]string{"-1", "SP000000000000000000002Q6VF78", "SP000000000000000000002Q6VF78", "none"}); err == nil || !strings.Contains(err.Error(), "argument amount") {
+		t.Errorf("ParseArgs with a negative amount = %v", err)
+	}
+}
+
+func TestParseImplementedTraits(t *testing.T) {
+	source := `(impl-trait 'SP3FBR2AGK5H9QBDH3EEN6DF8EK8JY7RX8QJ5SVTE.sip-010-trait-ft-standard.sip-010-trait)
+(impl-trait .extension-trait.extension-trait)
+;; (impl-trait 'SP2PABAF9FTAJYNFZH93XENAJ8FVY99RRM50D2JG9.nft-trait.nft-trait)
+(use-trait ft-trait 'SP3FBR2AGK5H9QBDH3EEN6DF8EK8JY7RX8QJ5SVTE.sip-010-trait-ft-standard.sip-010-trait)
+(define-fungible-token token) (impl-trait  'SP3FBR2AGK5H9QBDH3EEN6DF8EK8JY7RX8QJ5SVTE.sip-010-trait-ft-standard.sip-010-trait )
+`
+	want := []string{
+		"SP3FBR2AGK5H9QBDH3EEN6DF8EK8JY7RX8QJ5SVTE.sip-010-trait-ft-standard.sip-010-trait",
+		".extension-trait.extension-trait",
+	}
+	if got := ParseImplementedTraits(source); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseImplementedTraits = %v, want %v", got, want)
+	}
+	if got := ParseImplementedTraits("(define-public (f) (ok true))"); got != nil {
+		t.Errorf("ParseImplementedTraits without impl-trait = %v", got)
+	}
+}
+
+// Errors explain why the shorthand of the type does not apply.
+func TestParseTypedError(t *testing.T) {
+	for _, tt := range []struct {
+		literal string
+		typ     TypeSignature
+		want    string
+	}{
+		{"SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ8", TypeSignature{Kind: KindPrincipal}, "checksum"},
+		{"hello world", TypeSignature{Kind: KindStringASCII, Length: 5}, "exceeds (string-ascii 5)"},
+		{"12345678", TypeSignature{Kind: KindStringASCII, Length: 5}, "exceeds (string-ascii 5)"},
+		{"abc", TypeSignature{Kind: KindUInt}, "invalid uint literal"},
+		{"u1", TypeSignature{Kind: KindBool}, "expected bool, got u1"},
+	} {
+		_, err := ParseTyped(tt.literal, tt.typ)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseTyped(%s, %s) = %v, want %q", tt.literal, tt.typ, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,325 @@
+// Package clarity implements the SIP-005 wire format for Clarity values.
+package clarity
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/hashhavoc/teller/pkg/stacks/address"
+	"github.com/hashhavoc/teller/pkg/stacks/c32"
+	"github.com/hashhavoc/teller/pkg/utils/uint128"
+)
+
+// Type is the one byte prefix identifying a serialized Clarity value.
+type Type byte
+
+const (
+	TypeInt               Type = 0x00
+	TypeUInt              Type = 0x01
+	TypeBuffer            Type = 0x02
+	TypeTrue              Type = 0x03
+	TypeFalse             Type = 0x04
+	TypeStandardPrincipal Type = 0x05
+	TypeContractPrincipal Type = 0x06
+	TypeResponseOk        Type = 0x07
+	TypeResponseErr       Type = 0x08
+	TypeOptionalNone      Type = 0x09
+	TypeOptionalSome      Type = 0x0a
+	TypeList              Type = 0x0b
+	TypeTuple             Type = 0x0c
+	TypeStringASCII       Type = 0x0d
+	TypeStringUTF8        Type = 0x0e
+)
+
+var typeNames = map[Type]string{
+	TypeInt:               "int",
+	TypeUInt:              "uint",
+	TypeBuffer:            "buffer",
+	TypeTrue:              "true",
+	TypeFalse:             "false",
+	TypeStandardPrincipal: "standard-principal",
+	TypeContractPrincipal: "contract-principal",
+	TypeResponseOk:        "response-ok",
+	TypeResponseErr:       "response-err",
+	TypeOptionalNone:      "none",
+	TypeOptionalSome:      "some",
+	TypeList:              "list",
+	TypeTuple:             "tuple",
+	TypeStringASCII:       "string-ascii",
+	TypeStringUTF8:        "string-utf8",
+}
+
+func (t Type) String() string {
+	if name, ok := typeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(0x%02x)", byte(t))
+}
+
+// Value is a decoded Clarity value. String returns the Clarity repr of the
+// value, matching the repr field returned by the Hiro API.
+type Value interface {
+	Type() Type
+	String() string
+}
+
+// Int is a signed 128 bit integer.
+type Int struct {
+	Value *big.Int
+}
+
+// UInt is an unsigned 128 bit integer.
+type UInt struct {
+	Value uint128.Uint128
+}
+
+type Buffer []byte
+
+type Bool bool
+
+// StandardPrincipal is an address made of a version byte and a hash160.
+type StandardPrincipal struct {
+	Version byte
+	Hash160 [20]byte
+}
+
+// ContractPrincipal is a contract deployed by a standard principal.
+type ContractPrincipal struct {
+	Address StandardPrincipal
+	Name    string
+}
+
+type ResponseOk struct {
+	Value Value
+}
+
+type ResponseErr struct {
+	Value Value
+}
+
+type None struct{}
+
+type Some struct {
+	Value Value
+}
+
+type List []Value
+
+// Tuple maps field names to values. Fields are always serialized and
+// printed in lexicographic order.
+type Tuple map[string]Value
+
+type StringASCII string
+
+type StringUTF8 string
+
+func NewInt(v int64) Int {
+	return Int{Value: big.NewInt(v)}
+}
+
+func NewUInt(v uint64) UInt {
+	return UInt{Value: uint128.FromInts(0, v)}
+}
+
+func NewBool(v bool) Bool {
+	return Bool(v)
+}
+
+// NewStandardPrincipal parses a c32check encoded Stacks address.
+func NewStandardPrincipal(addr string) (StandardPrincipal, error) {
+	a, err := address.Decode(addr)
+	if err != nil {
+		return StandardPrincipal{}, fmt.Errorf("invalid principal: %w", err)
+	}
+	return StandardPrincipal{Version: a.Version, Hash160: a.Hash160}, nil
+}
+
+// NewContractPrincipal parses a contract ID of the form ADDRESS.NAME.
+func NewContractPrincipal(contractID string) (ContractPrincipal, error) {
+	p, err := address.ParseContract(contractID)
+	if err != nil {
+		return ContractPrincipal{}, fmt.Errorf("invalid contract principal: %w", err)
+	}
+	return ContractPrincipal{Address: StandardPrincipal{Version: p.Address.Version, Hash160: p.Address.Hash160}, Name: p.Name}, nil
+}
+
+// NewPrincipal parses either a standard or a contract principal.
+func NewPrincipal(s string) (Value, error) {
+	if strings.Contains(s, ".") {
+		return NewContractPrincipal(s)
+	}
+	return NewStandardPrincipal(s)
+}
+
+func (b Bool) Type() Type {
+	if b {
+		return TypeTrue
+	}
+	return TypeFalse
+}
+
+func (Int) Type() Type               { return TypeInt }
+func (UInt) Type() Type              { return TypeUInt }
+func (Buffer) Type() Type            { return TypeBuffer }
+func (StandardPrincipal) Type() Type { return TypeStandardPrincipal }
+func (ContractPrincipal) Type() Type { return TypeContractPrincipal }
+func (ResponseOk) Type() Type        { return TypeResponseOk }
+func (ResponseErr) Type() Type       { return TypeResponseErr }
+func (None) Type() Type              { return TypeOptionalNone }
+func (Some) Type() Type              { return TypeOptionalSome }
+func (List) Type() Type              { return TypeList }
+func (Tuple) Type() Type             { return TypeTuple }
+func (StringASCII) Type() Type       { return TypeStringASCII }
+func (StringUTF8) Type() Type        { return TypeStringUTF8 }
+
+func (v Int) String() string {
+	if v.Value == nil {
+		return "0"
+	}
+	return v.Value.String()
+}
+
+func (v UInt) String() string { return "u" + v.Value.String() }
+
+func (v Buffer) String() string { return "0x" + hex.EncodeToString(v) }
+
+func (v Bool) String() string {
+	if v {
+		return "true"
+	}
+	return "false"
+}
+
+// Address returns the c32check encoded address of the principal.
+func (v StandardPrincipal) Address() string {
+	address, err := c32.Address(v.Version, v.Hash160[:])
+	if err != nil {
+		return fmt.Sprintf("invalid-principal(%d,%x)", v.Version, v.Hash160)
+	}
+	return address
+}
+
+func (v StandardPrincipal) String() string { return "'" + v.Address() }
+
+// ContractID returns the ADDRESS.NAME form of the contract principal.
+func (v ContractPrincipal) ContractID() string { return v.Address.Address() + "." + v.Name }
+
+func (v ContractPrincipal) String() string { return "'" + v.ContractID() }
+
+func (v ResponseOk) String() string  { return "(ok " + v.Value.String() + ")" }
+func (v ResponseErr) String() string { return "(err " + v.Value.String() + ")" }
+func (None) String() string          { return "none" }
+func (v Some) String() string        { return "(some " + v.Value.String() + ")" }
+
+func (v List) String() string {
+	var b strings.Builder
+	b.WriteString("(list")
+	for _, item := range v {
+		b.WriteString(" ")
+		b.WriteString(item.String())
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+// Keys returns the tuple field names in serialization order.
+func (v Tuple) Keys() []string {
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (v Tuple) String() string {
+	var b strings.Builder
+	b.WriteString("(tuple")
+	for _, k := range v.Keys() {
+		fmt.Fprintf(&b, " (%s %s)", k, v[k].String())
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+func (v StringASCII) String() string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(v); i++ {
+		switch c := v[i]; c {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func (v StringUTF8) String() string {
+	var b strings.Builder
+	b.WriteString(`u"`)
+	for _, r := range string(v) {
+		switch {
+		case r == '"':
+			b.WriteString(`\"`)
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r < 0x80:
+			b.WriteRune(r)
+		default:
+			fmt.Fprintf(&b, `\u{%x}`, r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// Unwrap strips any number of (ok ...) and (some ...) wrappers from v.
+// Errors and none are returned unchanged.
+func Unwrap(v Value) Value {
+	for {
+		switch inner := v.(type) {
+		case ResponseOk:
+			v = inner.Value
+		case Some:
+			v = inner.Value
+		default:
+			return v
+		}
+	}
+}
+
+// Display returns a plain text rendering of v intended for tables: strings
+// lose their quotes, integers their u prefix and principals their tick.
+// Compound values fall back to their repr.
+func Display(v Value) string {
+	switch v := v.(type) {
+	case UInt:
+		return v.Value.String()
+	case StringASCII:
+		return string(v)
+	case StringUTF8:
+		return string(v)
+	case StandardPrincipal:
+		return v.Address()
+	case ContractPrincipal:
+		return v.ContractID()
+	default:
+		return v.String()
+	}
+}
@@ -0,0 +1,194 @@
+package clarity
+
+import (
+	"encoding/hex"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashhavoc/teller/pkg/utils/uint128"
+)
+
+func mustPrincipal(t *testing.T, s string) Value {
+	t.Helper()
+	v, err := NewPrincipal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func bigInt(s string) Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("invalid test integer " + s)
+	}
+	return Int{Value: n}
+}
+
+// vector is the SIP-005 serialization of a value with the repr printed by
+// the Stacks node and the Hiro API for it.
+type vector struct {
+	name  string
+	value Value
+	hex   string
+	repr  string
+}
+
+func vectors(t *testing.T) []vector {
+	return []vector{
+		{"int zero", NewInt(0), "0000000000000000000000000000000000", "0"},
+		{"int one", NewInt(1), "0000000000000000000000000000000001", "1"},
+		{"int minus one", NewInt(-1), "00ffffffffffffffffffffffffffffffff", "-1"},
+		{"int max", bigInt("170141183460469231731687303715884105727"), "007fffffffffffffffffffffffffffffff", "170141183460469231731687303715884105727"},
+		{"int min", bigInt("-170141183460469231731687303715884105728"), "0080000000000000000000000000000000", "-170141183460469231731687303715884105728"},
+		{"uint zero", NewUInt(0), "0100000000000000000000000000000000", "u0"},
+		{"uint 64 bit carry", UInt{Value: uint128.FromInts(1, 0)}, "0100000000000000010000000000000000", "u18446744073709551616"},
+		{"uint max", UInt{Value: uint128.FromInts(^uint64(0), ^uint64(0))}, "01ffffffffffffffffffffffffffffffff", "u340282366920938463463374607431768211455"},
+		{"buffer", Buffer{0xde, 0xad, 0xbe, 0xef}, "0200000004deadbeef", "0xdeadbeef"},
+		{"empty buffer", Buffer{}, "0200000000", "0x"},
+		{"true", Bool(true), "03", "true"},
+		{"false", Bool(false), "04", "false"},
+		{"standard principal", mustPrincipal(t, "SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7"), "0516a46ff88886c2ef9762d970b4d2c63678835bd39d", "'SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7"},
+		{"testnet principal", mustPrincipal(t, "ST2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKQYAC0RQ"), "051aa46ff88886c2ef9762d970b4d2c63678835bd39d", "'ST2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKQYAC0RQ"},
+		{"contract principal", mustPrincipal(t, "SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7.abcd"), "0616a46ff88886c2ef9762d970b4d2c63678835bd39d0461626364", "'SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7.abcd"},
+		{"ok", ResponseOk{Value: Bool(true)}, "0703", "(ok true)"},
+		{"err", ResponseErr{Value: NewUInt(1)}, "080100000000000000000000000000000001", "(err u1)"},
+		{"none", None{}, "09", "none"},
+		{"some", Some{Value: Bool(false)}, "0a04", "(some false)"},
+		{"list", List{NewInt(1), NewInt(2)}, "0b00000002" + "0000000000000000000000000000000001" + "0000000000000000000000000000000002", "(list 1 2)"},
+		{"empty list", List{}, "0b00000000", "(list)"},
+		{"tuple", Tuple{"b": NewUInt(2), "a": NewUInt(1)}, "0c00000002" + "0161" + "0100000000000000000000000000000001" + "0162" + "0100000000000000000000000000000002", "(tuple (a u1) (b u2))"},
+		{"string-ascii", StringASCII("hello world"), "0d0000000b68656c6c6f20776f726c64", `"hello world"`},
+		{"string-ascii escapes", StringASCII("a\"b\\c\n"), "0d00000006612262" + "5c630a", `"a\"b\\c\n"`},
+		{"string-utf8", StringUTF8("hello world"), "0e0000000b68656c6c6f20776f726c64", `u"hello world"`},
+		{"string-utf8 non-ascii", StringUTF8("é😀"), "0e00000006c3a9f09f9880", `u"\u{e9}\u{1f600}"`},
+		{"nested", ResponseOk{Value: Some{Value: Tuple{"n": List{NewUInt(7)}}}}, "070a0c00000001016e0b000000010100000000000000000000000000000007", "(ok (some (tuple (n (list u7)))))"},
+	}
+}
+
+func TestEncode(t *testing.T) {
+	for _, tt := range vectors(t) {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Encode(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hex.EncodeToString(got) != tt.hex {
+				t.Errorf("Encode = %x, want %s", got, tt.hex)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for _, tt := range vectors(t) {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeHex("0x" + tt.hex)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.repr {
+				t.Errorf("repr = %s, want %s", got.String(), tt.repr)
+			}
+			again, err := Encode(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hex.EncodeToString(again) != tt.hex {
+				t.Errorf("round trip = %x, want %s", again, tt.hex)
+			}
+		})
+	}
+}
+
+func TestParseRepr(t *testing.T) {
+	for _, tt := range vectors(t) {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.repr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := Encode(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hex.EncodeToString(b) != tt.hex {
+				t.Errorf("Parse(%s) encodes to %x, want %s", tt.repr, b, tt.hex)
+			}
+		})
+	}
+}
+
+func TestTupleOrder(t *testing.T) {
+	tuple := Tuple{"zeta": NewInt(1), "alpha": NewInt(2), "mid": NewInt(3), "a": NewInt(4)}
+	if got, want := tuple.Keys(), []string{"a", "alpha", "mid", "zeta"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys = %v, want %v", got, want)
+	}
+
+	// A tuple serialized out of order still decodes, and is written back in
+	// the canonical order.
+	unsorted := "0c00000002" + "0162" + "0100000000000000000000000000000002" + "0161" + "0100000000000000000000000000000001"
+	v, err := DecodeHex(unsorted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Encode(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "0c00000002" + "0161" + "0100000000000000000000000000000001" + "0162" + "0100000000000000000000000000000002"
+	if hex.EncodeToString(b) != want {
+		t.Errorf("Encode = %x, want %s", b, want)
+	}
+}
+
+func TestEncodeIntRange(t *testing.T) {
+	for _, s := range []string{
+		"170141183460469231731687303715884105728",
+		"-170141183460469231731687303715884105729",
+	} {
+		if _, err := Encode(bigInt(s)); err == nil {
+			t.Errorf("Encode(%s) succeeded, want an out of range error", s)
+		}
+	}
+	if _, err := Parse("u340282366920938463463374607431768211456"); err == nil {
+		t.Error("Parse of 2^128 as a uint succeeded")
+	}
+	if _, err := Parse("170141183460469231731687303715884105728"); err == nil {
+		t.Error("Parse of 2^127 as an int succeeded")
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for name, s := range map[string]string{
+		"empty":           "",
+		"unknown prefix":  "ff",
+		"short int":       "0000",
+		"trailing bytes":  "0304",
+		"short buffer":    "0200000004dead",
+		"huge length":     "0bffffffff",
+		"bad utf8":        "0e00000001ff",
+		"short principal": "0516a46f",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if v, err := DecodeHex(s); err == nil {
+				t.Errorf("DecodeHex(%q) = %v, want an error", s, v)
+			}
+		})
+	}
+}
+
+func TestDecodeDepth(t *testing.T) {
+	s := strings.Repeat("0a", maxDepth+2) + "03"
+	if _, err := DecodeHex(s); err == nil {
+		t.Error("deeply nested value decoded, want a depth error")
+	}
+}
+
+func TestEncodeNonASCII(t *testing.T) {
+	if _, err := Encode(StringASCII("é")); err == nil {
+		t.Error("string-ascii with a non-ascii byte encoded")
+	}
+}
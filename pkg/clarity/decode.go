@@ -0,0 +1,238 @@
+package clarity
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashhavoc/teller/pkg/utils/uint128"
+)
+
+// maxDepth bounds the nesting of decoded values. Clarity itself limits type
+// depth to 32, so anything deeper is malformed input.
+const maxDepth = 64
+
+var ErrUnexpectedEOF = errors.New("clarity: unexpected end of input")
+
+// Decode deserializes a single value from b. It is an error for b to contain
+// trailing bytes.
+func Decode(b []byte) (Value, error) {
+	d := decoder{buf: b}
+	v, err := d.value(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.buf) {
+		return nil, fmt.Errorf("clarity: %d trailing bytes after value", len(d.buf)-d.pos)
+	}
+	return v, nil
+}
+
+// DecodePrefix deserializes the value at the start of b and returns it with
+// the number of bytes it used, for values embedded in larger structures.
+func DecodePrefix(b []byte) (Value, int, error) {
+	d := decoder{buf: b}
+	v, err := d.value(0)
+	if err != nil {
+		return nil, 0, err
+	}
+	return v, d.pos, nil
+}
+
+// DecodeHex deserializes a hex encoded value, with or without a 0x prefix.
+func DecodeHex(s string) (Value, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("clarity: invalid hex: %w", err)
+	}
+	return Decode(b)
+}
+
+type decoder struct {
+	buf []byte
+	pos int
+}
+
+func (d *decoder) read(n int) ([]byte, error) {
+	if n < 0 || len(d.buf)-d.pos < n {
+		return nil, ErrUnexpectedEOF
+	}
+	b := d.buf[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *decoder) byte() (byte, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (d *decoder) length() (int, error) {
+	b, err := d.read(4)
+	if err != nil {
+		return 0, err
+	}
+	n := binary.BigEndian.Uint32(b)
+	if int(n) > len(d.buf)-d.pos {
+		// Every element takes at least one byte, so a length larger than
+		// the remaining input can never be satisfied.
+		return 0, ErrUnexpectedEOF
+	}
+	return int(n), nil
+}
+
+func (d *decoder) name() (string, error) {
+	n, err := d.byte()
+	if err != nil {
+		return "", err
+	}
+	b, err := d.read(int(n))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (d *decoder) principal() (StandardPrincipal, error) {
+	b, err := d.read(21)
+	if err != nil {
+		return StandardPrincipal{}, err
+	}
+	p := StandardPrincipal{Version: b[0]}
+	copy(p.Hash160[:], b[1:])
+	return p, nil
+}
+
+func (d *decoder) value(depth int) (Value, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("clarity: value nested deeper than %d", maxDepth)
+	}
+	prefix, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := Type(prefix); t {
+	case TypeInt:
+		b, err := d.read(16)
+		if err != nil {
+			return nil, err
+		}
+		n := new(big.Int).SetBytes(b)
+		if b[0]&0x80 != 0 {
+			n.Sub(n, two128)
+		}
+		return Int{Value: n}, nil
+	case TypeUInt:
+		b, err := d.read(16)
+		if err != nil {
+			return nil, err
+		}
+		return UInt{Value: uint128.FromBytes(b)}, nil
+	case TypeBuffer:
+		n, err := d.length()
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.read(n)
+		if err != nil {
+			return nil, err
+		}
+		return Buffer(append([]byte{}, b...)), nil
+	case TypeTrue:
+		return Bool(true), nil
+	case TypeFalse:
+		return Bool(false), nil
+	case TypeStandardPrincipal:
+		return d.principal()
+	case TypeContractPrincipal:
+		address, err := d.principal()
+		if err != nil {
+			return nil, err
+		}
+		name, err := d.name()
+		if err != nil {
+			return nil, err
+		}
+		return ContractPrincipal{Address: address, Name: name}, nil
+	case TypeResponseOk, TypeResponseErr, TypeOptionalSome:
+		inner, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		switch t {
+		case TypeResponseOk:
+			return ResponseOk{Value: inner}, nil
+		case TypeResponseErr:
+			return ResponseErr{Value: inner}, nil
+		default:
+			return Some{Value: inner}, nil
+		}
+	case TypeOptionalNone:
+		return None{}, nil
+	case TypeList:
+		n, err := d.length()
+		if err != nil {
+			return nil, err
+		}
+		list := make(List, 0, n)
+		for i := 0; i < n; i++ {
+			item, err := d.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		return list, nil
+	case TypeTuple:
+		n, err := d.length()
+		if err != nil {
+			return nil, err
+		}
+		tuple := make(Tuple, n)
+		for i := 0; i < n; i++ {
+			name, err := d.name()
+			if err != nil {
+				return nil, err
+			}
+			item, err := d.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			tuple[name] = item
+		}
+		return tuple, nil
+	case TypeStringASCII:
+		n, err := d.length()
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.read(n)
+		if err != nil {
+			return nil, err
+		}
+		return StringASCII(b), nil
+	case TypeStringUTF8:
+		n, err := d.length()
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.read(n)
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(b) {
+			return nil, errors.New("clarity: string-utf8 is not valid utf-8")
+		}
+		return StringUTF8(b), nil
+	default:
+		return nil, fmt.Errorf("clarity: unknown type prefix 0x%02x", prefix)
+	}
+}
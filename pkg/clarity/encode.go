@@ -0,0 +1,128 @@
+package clarity
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+)
+
+var (
+	minInt128 = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 127))
+	maxInt128 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1))
+	two128    = new(big.Int).Lsh(big.NewInt(1), 128)
+)
+
+// Encode serializes v using the SIP-005 consensus format.
+func Encode(v Value) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encode(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// EncodeHex serializes v and returns it as a 0x prefixed hex string, the
+// form expected by the Stacks node RPC endpoints.
+func EncodeHex(v Value) (string, error) {
+	b, err := Encode(v)
+	if err != nil {
+		return "", err
+	}
+	return "0x" + hex.EncodeToString(b), nil
+}
+
+func encode(buf *bytes.Buffer, v Value) error {
+	if v == nil {
+		return fmt.Errorf("cannot encode nil clarity value")
+	}
+	buf.WriteByte(byte(v.Type()))
+
+	switch v := v.(type) {
+	case Int:
+		n := v.Value
+		if n == nil {
+			n = new(big.Int)
+		}
+		if n.Cmp(minInt128) < 0 || n.Cmp(maxInt128) > 0 {
+			return fmt.Errorf("int %s does not fit in 128 bits", n)
+		}
+		if n.Sign() < 0 {
+			n = new(big.Int).Add(n, two128)
+		}
+		b := make([]byte, 16)
+		n.FillBytes(b)
+		buf.Write(b)
+	case UInt:
+		buf.Write(v.Value.GetBytes())
+	case Buffer:
+		writeLength(buf, len(v))
+		buf.Write(v)
+	case Bool, None:
+	case StandardPrincipal:
+		encodePrincipal(buf, v)
+	case ContractPrincipal:
+		encodePrincipal(buf, v.Address)
+		if err := writeName(buf, v.Name); err != nil {
+			return err
+		}
+	case ResponseOk:
+		return encode(buf, v.Value)
+	case ResponseErr:
+		return encode(buf, v.Value)
+	case Some:
+		return encode(buf, v.Value)
+	case List:
+		writeLength(buf, len(v))
+		for _, item := range v {
+			if err := encode(buf, item); err != nil {
+				return err
+			}
+		}
+	case Tuple:
+		writeLength(buf, len(v))
+		for _, k := range v.Keys() {
+			if err := writeName(buf, k); err != nil {
+				return err
+			}
+			if err := encode(buf, v[k]); err != nil {
+				return err
+			}
+		}
+	case StringASCII:
+		for i := 0; i < len(v); i++ {
+			if v[i] >= 0x80 {
+				return fmt.Errorf("string-ascii contains non-ascii byte 0x%02x", v[i])
+			}
+		}
+		writeLength(buf, len(v))
+		buf.WriteString(string(v))
+	case StringUTF8:
+		writeLength(buf, len(v))
+		buf.WriteString(string(v))
+	default:
+		return fmt.Errorf("unsupported clarity value %T", v)
+	}
+	return nil
+}
+
+func encodePrincipal(buf *bytes.Buffer, p StandardPrincipal) {
+	buf.WriteByte(p.Version)
+	buf.Write(p.Hash160[:])
+}
+
+func writeLength(buf *bytes.Buffer, n int) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(n))
+	buf.Write(b[:])
+}
+
+func writeName(buf *bytes.Buffer, name string) error {
+	if len(name) == 0 || len(name) > 128 {
+		return fmt.Errorf("invalid clarity name %q", name)
+	}
+	buf.WriteByte(byte(len(name)))
+	buf.WriteString(name)
+	return nil
+}
@@ -0,0 +1,317 @@
+package clarity
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+
+	"github.com/hashhavoc/teller/pkg/utils/uint128"
+)
+
+// Parse reads a Clarity literal such as u100, -5, true, 'SP..., 'SP....name,
+// 0xbeef, "text", u"text", none, (some u1), (ok u1), (err u1), (list u1 u2),
+// {a: u1, b: "x"} or (tuple (a u1) (b "x")).
+func Parse(s string) (Value, error) {
+	p := parser{src: s}
+	v, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos != len(p.src) {
+		return nil, p.errorf("unexpected %q after value", p.src[p.pos:])
+	}
+	return v, nil
+}
+
+type parser struct {
+	src string
+	pos int
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("clarity: parse %q at offset %d: %s", p.src, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *parser) peek() byte {
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *parser) expect(c byte) error {
+	p.skipSpace()
+	if p.peek() != c {
+		return p.errorf("expected %q", c)
+	}
+	p.pos++
+	return nil
+}
+
+func isDelimiter(c byte) bool {
+	return c == 0 || c == '(' || c == ')' || c == '{' || c == '}' || c == ',' || c == ':' || unicode.IsSpace(rune(c))
+}
+
+// atom returns the next run of characters up to a delimiter.
+func (p *parser) atom() string {
+	start := p.pos
+	for p.pos < len(p.src) && !isDelimiter(p.src[p.pos]) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *parser) value() (Value, error) {
+	p.skipSpace()
+	switch c := p.peek(); {
+	case c == 0:
+		return nil, p.errorf("unexpected end of input")
+	case c == '(':
+		return p.form()
+	case c == '{':
+		return p.tupleBraces()
+	case c == '"':
+		s, err := p.quoted()
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < len(s); i++ {
+			if s[i] >= 0x80 {
+				return nil, p.errorf("string-ascii literal contains non-ascii characters, use u\"...\"")
+			}
+		}
+		return StringASCII(s), nil
+	case c == 'u' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '"':
+		p.pos++
+		s, err := p.quoted()
+		if err != nil {
+			return nil, err
+		}
+		return StringUTF8(s), nil
+	case c == '\'':
+		p.pos++
+		atom := p.atom()
+		v, err := NewPrincipal(atom)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		return v, nil
+	}
+
+	atom := p.atom()
+	switch {
+	case atom == "true":
+		return Bool(true), nil
+	case atom == "false":
+		return Bool(false), nil
+	case atom == "none":
+		return None{}, nil
+	case strings.HasPrefix(atom, "0x"):
+		b, err := hex.DecodeString(atom[2:])
+		if err != nil {
+			return nil, p.errorf("invalid buffer literal %q", atom)
+		}
+		return Buffer(b), nil
+	case strings.HasPrefix(atom, "u"):
+		return parseUInt(atom[1:])
+	default:
+		n, ok := new(big.Int).SetString(atom, 10)
+		if !ok {
+			return nil, p.errorf("unrecognized literal %q", atom)
+		}
+		if n.Cmp(minInt128) < 0 || n.Cmp(maxInt128) > 0 {
+			return nil, p.errorf("int %s does not fit in 128 bits", atom)
+		}
+		return Int{Value: n}, nil
+	}
+}
+
+// ParseUInt parses a base 10 string into a UInt.
+func ParseUInt(s string) (UInt, error) {
+	v, err := parseUInt(s)
+	if err != nil {
+		return UInt{}, err
+	}
+	return v.(UInt), nil
+}
+
+func parseUInt(s string) (Value, error) {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok || n.Sign() < 0 {
+		return nil, fmt.Errorf("clarity: invalid uint literal %q", s)
+	}
+	if n.BitLen() > 128 {
+		return nil, fmt.Errorf("clarity: uint %s does not fit in 128 bits", s)
+	}
+	b := make([]byte, 16)
+	n.FillBytes(b)
+	return UInt{Value: uint128.FromBytes(b)}, nil
+}
+
+func (p *parser) quoted() (string, error) {
+	if err := p.expect('"'); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for {
+		if p.pos >= len(p.src) {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.src[p.pos]
+		p.pos++
+		switch c {
+		case '"':
+			return b.String(), nil
+		case '\\':
+			if p.pos >= len(p.src) {
+				return "", p.errorf("unterminated escape")
+			}
+			e := p.src[p.pos]
+			p.pos++
+			switch e {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case 'u':
+				r, err := p.unicodeEscape()
+				if err != nil {
+					return "", err
+				}
+				b.WriteRune(r)
+			default:
+				b.WriteByte(e)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+// unicodeEscape reads the {hex} part of a \u{hex} escape.
+func (p *parser) unicodeEscape() (rune, error) {
+	end := strings.IndexByte(p.src[p.pos:], '}')
+	if p.peek() != '{' || end < 0 {
+		return 0, p.errorf("invalid unicode escape")
+	}
+	var r rune
+	if _, err := fmt.Sscanf(p.src[p.pos+1:p.pos+end], "%x", &r); err != nil {
+		return 0, p.errorf("invalid unicode escape")
+	}
+	p.pos += end + 1
+	return r, nil
+}
+
+// form parses a parenthesised expression: some, ok, err, list or tuple.
+func (p *parser) form() (Value, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	keyword := p.atom()
+
+	var v Value
+	switch keyword {
+	case "some", "ok", "err":
+		inner, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		switch keyword {
+		case "some":
+			v = Some{Value: inner}
+		case "ok":
+			v = ResponseOk{Value: inner}
+		default:
+			v = ResponseErr{Value: inner}
+		}
+	case "list":
+		list := List{}
+		for {
+			p.skipSpace()
+			if p.peek() == ')' {
+				break
+			}
+			item, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		v = list
+	case "tuple":
+		tuple := Tuple{}
+		for {
+			p.skipSpace()
+			if p.peek() == ')' {
+				break
+			}
+			if err := p.expect('('); err != nil {
+				return nil, err
+			}
+			p.skipSpace()
+			name := p.atom()
+			if name == "" {
+				return nil, p.errorf("expected tuple field name")
+			}
+			item, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(')'); err != nil {
+				return nil, err
+			}
+			tuple[name] = item
+		}
+		v = tuple
+	default:
+		return nil, p.errorf("unknown form %q", keyword)
+	}
+
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// tupleBraces parses the {key: value, ...} tuple shorthand.
+func (p *parser) tupleBraces() (Value, error) {
+	if err := p.expect('{'); err != nil {
+		return nil, err
+	}
+	tuple := Tuple{}
+	for {
+		p.skipSpace()
+		if p.peek() == '}' {
+			p.pos++
+			return tuple, nil
+		}
+		name := p.atom()
+		if name == "" {
+			return nil, p.errorf("expected tuple field name")
+		}
+		if err := p.expect(':'); err != nil {
+			return nil, err
+		}
+		item, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		tuple[name] = item
+		p.skipSpace()
+		if p.peek() == ',' {
+			p.pos++
+		}
+	}
+}
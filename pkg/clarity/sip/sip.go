@@ -0,0 +1,206 @@
+// Package sip describes the standard token traits (SIP-009, SIP-010 and
+// SIP-013) and checks contract interfaces against them.
+package sip
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashhavoc/teller/pkg/clarity"
+)
+
+// Trait is a standard trait and the functions it requires.
+type Trait struct {
+	// Name is the short name of the standard, e.g. sip-010.
+	Name        string
+	Description string
+	// ID is the identifier of the canonical mainnet trait definition. The
+	// same contract and trait names are deployed by other addresses on
+	// testnet and devnet.
+	ID        string
+	Functions []clarity.Function
+}
+
+var (
+	uintType      = clarity.TypeSignature{Kind: clarity.KindUInt}
+	boolType      = clarity.TypeSignature{Kind: clarity.KindBool}
+	principalType = clarity.TypeSignature{Kind: clarity.KindPrincipal}
+)
+
+func buff(n int) clarity.TypeSignature {
+	return clarity.TypeSignature{Kind: clarity.KindBuffer, Length: n}
+}
+
+func stringASCII(n int) clarity.TypeSignature {
+	return clarity.TypeSignature{Kind: clarity.KindStringASCII, Length: n}
+}
+
+func stringUTF8(n int) clarity.TypeSignature {
+	return clarity.TypeSignature{Kind: clarity.KindStringUTF8, Length: n}
+}
+
+func optional(t clarity.TypeSignature) clarity.TypeSignature {
+	return clarity.TypeSignature{Kind: clarity.KindOptional, Elem: &t}
+}
+
+func response(ok, err clarity.TypeSignature) clarity.TypeSignature {
+	return clarity.TypeSignature{Kind: clarity.KindResponse, Ok: &ok, Err: &err}
+}
+
+func fn(name string, output clarity.TypeSignature, args ...clarity.TypeSignature) clarity.Function {
+	f := clarity.Function{Name: name, Access: clarity.AccessPublic, Outputs: clarity.FunctionOutput{Type: output}}
+	for i, a := range args {
+		f.Args = append(f.Args, clarity.FunctionArg{Name: fmt.Sprintf("arg%d", i+1), Type: a})
+	}
+	return f
+}
+
+var SIP009 = Trait{
+	Name:        "sip-009",
+	Description: "non-fungible token",
+	ID:          "SP2PABAF9FTAJYNFZH93XENAJ8FVY99RRM50D2JG9.nft-trait.nft-trait",
+	Functions: []clarity.Function{
+		fn("get-last-token-id", response(uintType, uintType)),
+		fn("get-token-uri", response(optional(stringASCII(256)), uintType), uintType),
+		fn("get-owner", response(optional(principalType), uintType), uintType),
+		fn("transfer", response(boolType, uintType), uintType, principalType, principalType),
+	},
+}
+
+var SIP010 = Trait{
+	Name:        "sip-010",
+	Description: "fungible token",
+	ID:          "SP3FBR2AGK5H9QBDH3EEN6DF8EK8JY7RX8QJ5SVTE.sip-010-trait-ft-standard.sip-010-trait",
+	Functions: []clarity.Function{
+		fn("transfer", response(boolType, uintType), uintType, principalType, principalType, optional(buff(34))),
+		fn("get-name", response(stringASCII(32), uintType)),
+		fn("get-symbol", response(stringASCII(32), uintType)),
+		fn("get-decimals", response(uintType, uintType)),
+		fn("get-balance", response(uintType, uintType), principalType),
+		fn("get-total-supply", response(uintType, uintType)),
+		fn("get-token-uri", response(optional(stringUTF8(256)), uintType)),
+	},
+}
+
+var SIP013 = Trait{
+	Name:        "sip-013",
+	Description: "semi-fungible token",
+	ID:          "SPDBEG5X8XD50SPM1JJH0E5CTXGDV5NJTKAKKR5V.sip013-semi-fungible-token-trait.sip013-semi-fungible-token-trait",
+	Functions: []clarity.Function{
+		fn("get-balance", response(uintType, uintType), uintType, principalType),
+		fn("get-overall-balance", response(uintType, uintType), principalType),
+		fn("get-total-supply", response(uintType, uintType), uintType),
+		fn("get-overall-supply", response(uintType, uintType)),
+		fn("get-decimals", response(uintType, uintType), uintType),
+		fn("get-token-uri", response(optional(stringASCII(256)), uintType), uintType),
+		fn("transfer", response(boolType, uintType), uintType, uintType, principalType, principalType),
+		fn("transfer-memo", response(boolType, uintType), uintType, uintType, principalType, principalType, buff(34)),
+	},
+}
+
+// Traits lists every standard known to the checker.
+var Traits = []Trait{SIP009, SIP010, SIP013}
+
+// Lookup finds a trait by its short name.
+func Lookup(name string) (Trait, bool) {
+	for _, t := range Traits {
+		if strings.EqualFold(t.Name, name) {
+			return t, true
+		}
+	}
+	return Trait{}, false
+}
+
+// Status is the outcome of checking a single trait function.
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusMissing  Status = "missing"
+	StatusPrivate  Status = "private"
+	StatusMistyped Status = "mistyped"
+)
+
+// FunctionResult is the conformance of one function required by a trait.
+type FunctionResult struct {
+	Expected clarity.Function
+	Actual   *clarity.Function
+	Status   Status
+	Detail   string
+}
+
+// Report is the conformance of a contract interface against one trait.
+type Report struct {
+	Trait     Trait
+	Declared  bool
+	Functions []FunctionResult
+}
+
+// Conforms reports whether every function required by the trait is present
+// with a compatible signature.
+func (r Report) Conforms() bool {
+	for _, f := range r.Functions {
+		if f.Status != StatusOK {
+			return false
+		}
+	}
+	return true
+}
+
+// Check compares the interface against the trait. Argument types of the
+// contract must accept the trait's argument types and the contract's return
+// type must fit in the trait's return type, mirroring Clarity's own check.
+// The trait is declared when the contract implements a trait with the same
+// contract and trait name, deployed by any address.
+func Check(abi clarity.ContractInterface, trait Trait) Report {
+	report := Report{Trait: trait}
+	for _, implemented := range abi.ImplementedTraits {
+		if traitName(implemented) == traitName(trait.ID) {
+			report.Declared = true
+		}
+	}
+
+	for _, expected := range trait.Functions {
+		result := FunctionResult{Expected: expected, Status: StatusOK}
+		actual, ok := abi.Function(expected.Name)
+		switch {
+		case !ok:
+			result.Status = StatusMissing
+			result.Detail = "function is not defined"
+		case actual.Access == clarity.AccessPrivate:
+			result.Actual = &actual
+			result.Status = StatusPrivate
+			result.Detail = "function is private"
+		default:
+			result.Actual = &actual
+			if detail := compare(expected, actual); detail != "" {
+				result.Status = StatusMistyped
+				result.Detail = detail
+			}
+		}
+		report.Functions = append(report.Functions, result)
+	}
+	return report
+}
+
+// traitName returns the contract.trait part of a trait identifier, which
+// is the same whichever address deployed the trait.
+func traitName(id string) string {
+	_, name, _ := strings.Cut(id, ".")
+	return name
+}
+
+func compare(expected, actual clarity.Function) string {
+	if len(expected.Args) != len(actual.Args) {
+		return fmt.Sprintf("expected %d arguments, found %d", len(expected.Args), len(actual.Args))
+	}
+	for i, arg := range expected.Args {
+		if !actual.Args[i].Type.AdmitsType(arg.Type) {
+			return fmt.Sprintf("argument %d (%s) is %s, expected %s", i+1, actual.Args[i].Name, actual.Args[i].Type, arg.Type)
+		}
+	}
+	if !expected.Outputs.Type.AdmitsType(actual.Outputs.Type) {
+		return fmt.Sprintf("returns %s, expected %s", actual.Outputs.Type, expected.Outputs.Type)
+	}
+	return ""
+}
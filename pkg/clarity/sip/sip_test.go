@@ -0,0 +1,127 @@
+package sip
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hashhavoc/teller/pkg/clarity"
+)
+
+// token returns the interface of a SIP-010 token as returned by the node,
+// with its functions passed through edit.
+func token(t *testing.T, edit func(functions []clarity.Function) []clarity.Function) clarity.ContractInterface {
+	t.Helper()
+	data, err := os.ReadFile("../testdata/sip010-token.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	abi, err := clarity.ParseContractInterface(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edit != nil {
+		abi.Functions = edit(abi.Functions)
+	}
+	return abi
+}
+
+// replace swaps the function of the same name.
+func replace(f clarity.Function) func([]clarity.Function) []clarity.Function {
+	return func(functions []clarity.Function) []clarity.Function {
+		for i := range functions {
+			if functions[i].Name == f.Name {
+				functions[i] = f
+			}
+		}
+		return functions
+	}
+}
+
+func private(f clarity.Function) clarity.Function {
+	f.Access = clarity.AccessPrivate
+	return f
+}
+
+func TestCheck(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		edit     func([]clarity.Function) []clarity.Function
+		function string
+		status   Status
+	}{
+		{"conforming", nil, "", StatusOK},
+		{"missing function", func(functions []clarity.Function) []clarity.Function {
+			var kept []clarity.Function
+			for _, f := range functions {
+				if f.Name != "get-decimals" {
+					kept = append(kept, f)
+				}
+			}
+			return kept
+		}, "get-decimals", StatusMissing},
+		{"private function", replace(private(fn("get-balance", response(uintType, uintType), principalType))), "get-balance", StatusPrivate},
+		{"widened return type", replace(fn("get-name", response(stringASCII(64), uintType))), "get-name", StatusMistyped},
+		{"wrong return type", replace(fn("get-decimals", response(uintType, boolType))), "get-decimals", StatusMistyped},
+		{"narrowed argument", replace(fn("transfer", response(boolType, uintType), uintType, principalType, principalType, optional(buff(16)))), "transfer", StatusMistyped},
+		{"missing argument", replace(fn("transfer", response(boolType, uintType), uintType, principalType, principalType)), "transfer", StatusMistyped},
+		// A wider argument accepts everything the trait passes.
+		{"widened argument", replace(fn("transfer", response(boolType, uintType), uintType, principalType, principalType, optional(buff(64)))), "", StatusOK},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Check(token(t, tt.edit), SIP010)
+			if len(report.Functions) != len(SIP010.Functions) {
+				t.Fatalf("%d results, want one per function of the trait", len(report.Functions))
+			}
+			if report.Conforms() != (tt.status == StatusOK) {
+				t.Errorf("Conforms = %v", report.Conforms())
+			}
+			for _, f := range report.Functions {
+				want := StatusOK
+				if f.Expected.Name == tt.function {
+					want = tt.status
+				}
+				if f.Status != want {
+					t.Errorf("%s: %s (%s), want %s", f.Expected.Name, f.Status, f.Detail, want)
+				}
+			}
+		})
+	}
+
+	// The token has none of the functions of an NFT, and its get-token-uri
+	// takes no token id.
+	report := Check(token(t, nil), SIP009)
+	if report.Conforms() {
+		t.Error("SIP-010 token conforms to SIP-009")
+	}
+}
+
+func TestCheckDetail(t *testing.T) {
+	report := Check(token(t, replace(fn("get-name", response(stringASCII(64), uintType)))), SIP010)
+	for _, f := range report.Functions {
+		if f.Expected.Name == "get-name" && f.Detail != "returns (response (string-ascii 64) uint), expected (response (string-ascii 32) uint)" {
+			t.Errorf("detail = %q", f.Detail)
+		}
+	}
+}
+
+func TestCheckDeclared(t *testing.T) {
+	for _, tt := range []struct {
+		trait    string
+		declared bool
+	}{
+		{"SP3FBR2AGK5H9QBDH3EEN6DF8EK8JY7RX8QJ5SVTE.sip-010-trait-ft-standard.sip-010-trait", true},
+		{"ST1NXBK3K5YYMD6FD41MVNP3JS1GABZ8TRVX023PT.sip-010-trait-ft-standard.sip-010-trait", true},
+		{".sip-010-trait-ft-standard.sip-010-trait", true},
+		{"SP3FBR2AGK5H9QBDH3EEN6DF8EK8JY7RX8QJ5SVTE.sip-010-trait-ft-standard.other-trait", false},
+		{"SP2PABAF9FTAJYNFZH93XENAJ8FVY99RRM50D2JG9.nft-trait.nft-trait", false},
+	} {
+		abi := token(t, nil)
+		abi.ImplementedTraits = []string{tt.trait}
+		if got := Check(abi, SIP010).Declared; got != tt.declared {
+			t.Errorf("Declared with %s = %v, want %v", tt.trait, got, tt.declared)
+		}
+	}
+	if Check(token(t, nil), SIP010).Declared {
+		t.Error("Declared without impl-trait")
+	}
+}
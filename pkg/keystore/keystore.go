@@ -0,0 +1,311 @@
+// Package keystore stores secrets encrypted with a passphrase, one JSON file
+// per key in a directory.
+//
+// Each file has the following format:
+//
+//	{
+//	  "version": 1,
+//	  "name": "main",
+//	  "address": "SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7",
+//	  "created_at": "2024-01-02T15:04:05Z",
+//	  "crypto": {
+//	    "cipher": "aes-256-gcm",
+//	    "ciphertext": "<hex>",
+//	    "nonce": "<hex, 12 bytes>",
+//	    "kdf": "scrypt",
+//	    "kdfparams": {"salt": "<hex, 32 bytes>", "n": 131072, "r": 8, "p": 1, "dklen": 32}
+//	  }
+//	}
+//
+// The AES-256-GCM key is derived from the passphrase with the kdf, either
+// "scrypt" (params salt, n, r, p, dklen) or "argon2id" (params salt, time,
+// memory in KiB, threads, dklen). The name and address are authenticated as
+// additional data, so they cannot be swapped between files.
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	Version = 1
+
+	KDFScrypt   = "scrypt"
+	KDFArgon2id = "argon2id"
+
+	cipherAES256GCM = "aes-256-gcm"
+)
+
+var (
+	ErrNotFound          = errors.New("keystore: key not found")
+	ErrExists            = errors.New("keystore: a key with this name already exists")
+	ErrWrongPassphrase   = errors.New("keystore: wrong passphrase or corrupted key")
+	errUnsupportedCipher = errors.New("keystore: unsupported cipher")
+)
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Key is an encrypted secret as stored on disk.
+type Key struct {
+	Version   int       `json:"version"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	CreatedAt time.Time `json:"created_at"`
+	Crypto    Crypto    `json:"crypto"`
+}
+
+type Crypto struct {
+	Cipher     string    `json:"cipher"`
+	CipherText string    `json:"ciphertext"`
+	Nonce      string    `json:"nonce"`
+	KDF        string    `json:"kdf"`
+	KDFParams  KDFParams `json:"kdfparams"`
+}
+
+// KDFParams holds the parameters of either kdf, unused fields are omitted.
+type KDFParams struct {
+	Salt    string `json:"salt"`
+	N       int    `json:"n,omitempty"`
+	R       int    `json:"r,omitempty"`
+	P       int    `json:"p,omitempty"`
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory,omitempty"`
+	Threads uint8  `json:"threads,omitempty"`
+	DKLen   int    `json:"dklen"`
+}
+
+func defaultParams(kdf string) (KDFParams, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return KDFParams{}, err
+	}
+	switch kdf {
+	case KDFScrypt:
+		return KDFParams{Salt: hex.EncodeToString(salt), N: 1 << 17, R: 8, P: 1, DKLen: 32}, nil
+	case KDFArgon2id:
+		return KDFParams{Salt: hex.EncodeToString(salt), Time: 3, Memory: 64 * 1024, Threads: 4, DKLen: 32}, nil
+	default:
+		return KDFParams{}, fmt.Errorf("keystore: unsupported kdf %q, expected %s or %s", kdf, KDFScrypt, KDFArgon2id)
+	}
+}
+
+func deriveKey(passphrase []byte, kdf string, params KDFParams) ([]byte, error) {
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: invalid salt: %w", err)
+	}
+	if params.DKLen != 32 {
+		return nil, fmt.Errorf("keystore: unsupported key length %d", params.DKLen)
+	}
+	switch kdf {
+	case KDFScrypt:
+		return scrypt.Key(passphrase, salt, params.N, params.R, params.P, params.DKLen)
+	case KDFArgon2id:
+		if params.Time == 0 || params.Memory == 0 || params.Threads == 0 {
+			return nil, errors.New("keystore: invalid argon2id parameters")
+		}
+		return argon2.IDKey(passphrase, salt, params.Time, params.Memory, params.Threads, uint32(params.DKLen)), nil
+	default:
+		return nil, fmt.Errorf("keystore: unsupported kdf %q", kdf)
+	}
+}
+
+func (k *Key) additionalData() []byte {
+	return []byte(k.Name + "\x00" + k.Address)
+}
+
+// Encrypt seals secret under a key derived from passphrase with kdf.
+func Encrypt(name, address string, secret, passphrase []byte, kdf string) (*Key, error) {
+	if !validName.MatchString(name) {
+		return nil, fmt.Errorf("keystore: invalid name %q, use letters, digits, '.', '_' and '-'", name)
+	}
+	params, err := defaultParams(kdf)
+	if err != nil {
+		return nil, err
+	}
+	derived, err := deriveKey(passphrase, kdf, params)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(derived)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	k := &Key{
+		Version:   Version,
+		Name:      name,
+		Address:   address,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	k.Crypto = Crypto{
+		Cipher:     cipherAES256GCM,
+		CipherText: hex.EncodeToString(aead.Seal(nil, nonce, secret, k.additionalData())),
+		Nonce:      hex.EncodeToString(nonce),
+		KDF:        kdf,
+		KDFParams:  params,
+	}
+	return k, nil
+}
+
+// Decrypt returns the secret sealed in the key.
+func (k *Key) Decrypt(passphrase []byte) ([]byte, error) {
+	if k.Crypto.Cipher != cipherAES256GCM {
+		return nil, errUnsupportedCipher
+	}
+	derived, err := deriveKey(passphrase, k.Crypto.KDF, k.Crypto.KDFParams)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(derived)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hex.DecodeString(k.Crypto.Nonce)
+	if err != nil || len(nonce) != aead.NonceSize() {
+		return nil, errors.New("keystore: invalid nonce")
+	}
+	ciphertext, err := hex.DecodeString(k.Crypto.CipherText)
+	if err != nil {
+		return nil, errors.New("keystore: invalid ciphertext")
+	}
+	secret, err := aead.Open(nil, nonce, ciphertext, k.additionalData())
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return secret, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Keystore is a directory of key files.
+type Keystore struct {
+	Dir string
+}
+
+func New(dir string) *Keystore {
+	return &Keystore{Dir: dir}
+}
+
+func (s *Keystore) path(name string) (string, error) {
+	if !validName.MatchString(name) {
+		return "", fmt.Errorf("keystore: invalid name %q", name)
+	}
+	return filepath.Join(s.Dir, name+".json"), nil
+}
+
+// Save writes a new key file readable only by the current user. It does not
+// overwrite an existing key.
+func (s *Keystore) Save(k *Key) error {
+	path, err := s.path(k.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0700); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(k, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, os.ErrExist) {
+		return ErrExists
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load reads the key with the given name.
+func (s *Keystore) Load(name string) (*Key, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var k Key
+	if err := json.Unmarshal(b, &k); err != nil {
+		return nil, fmt.Errorf("keystore: %s: %w", path, err)
+	}
+	if k.Version != Version {
+		return nil, fmt.Errorf("keystore: %s: unsupported version %d", path, k.Version)
+	}
+	return &k, nil
+}
+
+// List returns every key in the keystore sorted by name. Files that cannot
+// be read as a key, because they are corrupt or were not written by the
+// keystore, are skipped and reported in skipped, one error per file.
+func (s *Keystore) List() (keys []*Key, skipped []error, err error) {
+	entries, err := os.ReadDir(s.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".json")
+		if e.IsDir() || !ok {
+			continue
+		}
+		k, err := s.Load(name)
+		if err != nil {
+			skipped = append(skipped, err)
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Name < keys[j].Name })
+	return keys, skipped, nil
+}
+
+// Delete removes the key with the given name.
+func (s *Keystore) Delete(name string) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,159 @@
+package keystore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	testAddress = "SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7"
+	testSecret  = "edf9aee84d9b7abc145504dde6726c64f369d37ee34ded868fabd876c26570bc01"
+)
+
+func mustEncrypt(t *testing.T, name, kdf string) *Key {
+	t.Helper()
+	k, err := Encrypt(name, testAddress, []byte(testSecret), []byte("correct horse"), kdf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	for _, kdf := range []string{KDFScrypt, KDFArgon2id} {
+		t.Run(kdf, func(t *testing.T) {
+			k := mustEncrypt(t, "main", kdf)
+			if k.Crypto.KDF != kdf || k.Crypto.Cipher != cipherAES256GCM {
+				t.Errorf("crypto = %s/%s, want %s/%s", k.Crypto.Cipher, k.Crypto.KDF, cipherAES256GCM, kdf)
+			}
+
+			secret, err := k.Decrypt([]byte("correct horse"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(secret) != testSecret {
+				t.Errorf("Decrypt = %s, want %s", secret, testSecret)
+			}
+
+			if _, err := k.Decrypt([]byte("correct horse ")); !errors.Is(err, ErrWrongPassphrase) {
+				t.Errorf("Decrypt with a wrong passphrase = %v, want %v", err, ErrWrongPassphrase)
+			}
+		})
+	}
+}
+
+func TestEncryptInvalid(t *testing.T) {
+	if _, err := Encrypt("main", testAddress, []byte(testSecret), nil, "pbkdf2"); err == nil {
+		t.Error("Encrypt with an unsupported kdf succeeded")
+	}
+	if _, err := Encrypt("../main", testAddress, []byte(testSecret), nil, KDFScrypt); err == nil {
+		t.Error("Encrypt with a path as name succeeded")
+	}
+}
+
+// The name and address are authenticated, so a file whose label was edited
+// or whose ciphertext was moved into another file does not decrypt.
+func TestAdditionalData(t *testing.T) {
+	k := mustEncrypt(t, "main", KDFScrypt)
+	for name, tamper := range map[string]func(k *Key){
+		"name":    func(k *Key) { k.Name = "other" },
+		"address": func(k *Key) { k.Address = "SP3FGQ8Z7JY9BWYZ5WM53E0M9NK7WHJF0691NZ159" },
+		"ciphertext": func(k *Key) {
+			b := []byte(k.Crypto.CipherText)
+			if b[0] == '0' {
+				b[0] = '1'
+			} else {
+				b[0] = '0'
+			}
+			k.Crypto.CipherText = string(b)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tampered := *k
+			tamper(&tampered)
+			if _, err := tampered.Decrypt([]byte("correct horse")); !errors.Is(err, ErrWrongPassphrase) {
+				t.Errorf("Decrypt = %v, want %v", err, ErrWrongPassphrase)
+			}
+		})
+	}
+}
+
+func TestKeystore(t *testing.T) {
+	ks := New(filepath.Join(t.TempDir(), "keystore"))
+
+	stored, skipped, err := ks.List()
+	if err != nil || len(stored) != 0 || len(skipped) != 0 {
+		t.Fatalf("List of a missing directory = %v, %v, %v", stored, skipped, err)
+	}
+
+	k := mustEncrypt(t, "main", KDFScrypt)
+	if err := ks.Save(k); err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.Save(k); !errors.Is(err, ErrExists) {
+		t.Errorf("Save of an existing key = %v, want %v", err, ErrExists)
+	}
+	info, err := os.Stat(filepath.Join(ks.Dir, "main.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("key file mode = %o, want 600", perm)
+	}
+
+	loaded, err := ks.Load("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := loaded.Decrypt([]byte("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(secret) != testSecret {
+		t.Errorf("Decrypt of the loaded key = %s, want %s", secret, testSecret)
+	}
+	if _, err := ks.Load("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load of a missing key = %v, want %v", err, ErrNotFound)
+	}
+
+	if err := ks.Delete("main"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.Delete("main"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete of a deleted key = %v, want %v", err, ErrNotFound)
+	}
+}
+
+// A corrupt or foreign file in the directory is reported on its own and does
+// not hide the other keys.
+func TestListSkipsUnreadable(t *testing.T) {
+	ks := New(t.TempDir())
+	for _, name := range []string{"b", "a"} {
+		k := mustEncrypt(t, name, KDFScrypt)
+		if err := ks.Save(k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range map[string]string{
+		"corrupt.json": `{"version": 1, "name": `,
+		"foreign.json": `{"version": 3, "id": "3198bc9c-6672-5ab3-d995-4942343ae5b6"}`,
+		"notes.txt":    "not a key",
+	} {
+		if err := os.WriteFile(filepath.Join(ks.Dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stored, skipped, err := ks.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 2 || stored[0].Name != "a" || stored[1].Name != "b" {
+		t.Errorf("List = %v, want the keys a and b", stored)
+	}
+	if len(skipped) != 2 {
+		t.Errorf("skipped = %v, want the corrupt and foreign files", skipped)
+	}
+}
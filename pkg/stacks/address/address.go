@@ -0,0 +1,246 @@
+// Package address parses and validates Stacks addresses and principals. It
+// checks the c32check version byte against a network and converts
+// addresses to and from their Bitcoin base58check form, which shares the
+// hash160.
+package address
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashhavoc/teller/pkg/stacks/c32"
+)
+
+// Version bytes of Stacks addresses.
+const (
+	MainnetSingleSig byte = 22 // SP
+	MainnetMultiSig  byte = 20 // SM
+	TestnetSingleSig byte = 26 // ST
+	TestnetMultiSig  byte = 21 // SN
+)
+
+// Version bytes of the Bitcoin addresses sharing the hash160 of a Stacks
+// address.
+const (
+	bitcoinMainnetP2PKH byte = 0x00
+	bitcoinMainnetP2SH  byte = 0x05
+	bitcoinTestnetP2PKH byte = 0x6f
+	bitcoinTestnetP2SH  byte = 0xc4
+)
+
+var (
+	ErrInvalidAddress  = errors.New("invalid address")
+	ErrUnknownVersion  = errors.New("unknown address version")
+	ErrWrongNetwork    = errors.New("address is for another network")
+	ErrInvalidContract = errors.New("invalid contract name")
+)
+
+// Network is the chain an address belongs to. Devnets use the testnet
+// versions.
+type Network int
+
+const (
+	Mainnet Network = iota
+	Testnet
+)
+
+// NetworkByName returns the network of mainnet, testnet or devnet.
+func NetworkByName(name string) (Network, error) {
+	switch name {
+	case "mainnet":
+		return Mainnet, nil
+	case "testnet", "devnet":
+		return Testnet, nil
+	default:
+		return 0, fmt.Errorf("invalid network %q, expected mainnet, testnet or devnet", name)
+	}
+}
+
+func (n Network) String() string {
+	if n == Testnet {
+		return "testnet"
+	}
+	return "mainnet"
+}
+
+// SingleSig returns the version of single signature addresses of n.
+func (n Network) SingleSig() byte {
+	if n == Testnet {
+		return TestnetSingleSig
+	}
+	return MainnetSingleSig
+}
+
+// MultiSig returns the version of multisig addresses of n.
+func (n Network) MultiSig() byte {
+	if n == Testnet {
+		return TestnetMultiSig
+	}
+	return MainnetMultiSig
+}
+
+// Address is a standard Stacks address.
+type Address struct {
+	Version byte
+	Hash160 [20]byte
+}
+
+// New returns the address of version and hash160.
+func New(version byte, hash160 []byte) (Address, error) {
+	if _, err := networkOf(version); err != nil {
+		return Address{}, err
+	}
+	if len(hash160) != 20 {
+		return Address{}, fmt.Errorf("%w: hash160 must be 20 bytes, got %d", ErrInvalidAddress, len(hash160))
+	}
+	a := Address{Version: version}
+	copy(a.Hash160[:], hash160)
+	return a, nil
+}
+
+// Decode parses a c32check encoded address such as SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7.
+func Decode(s string) (Address, error) {
+	version, hash160, err := c32.DecodeAddress(s)
+	if err != nil {
+		return Address{}, fmt.Errorf("%w %q: %w", ErrInvalidAddress, s, err)
+	}
+	a, err := New(version, hash160)
+	if err != nil {
+		return Address{}, fmt.Errorf("%q: %w", s, err)
+	}
+	return a, nil
+}
+
+func (a Address) String() string {
+	s, _ := c32.Address(a.Version, a.Hash160[:])
+	return s
+}
+
+// Network returns the network of the version of a.
+func (a Address) Network() Network {
+	n, _ := networkOf(a.Version)
+	return n
+}
+
+// MultiSig reports whether a is the address of a multisig.
+func (a Address) MultiSig() bool {
+	return a.Version == MainnetMultiSig || a.Version == TestnetMultiSig
+}
+
+// Check returns ErrWrongNetwork unless a belongs to network.
+func (a Address) Check(network Network) error {
+	if a.Network() != network {
+		return fmt.Errorf("%w: %s is a %s address, expected a %s one", ErrWrongNetwork, a, a.Network(), network)
+	}
+	return nil
+}
+
+func networkOf(version byte) (Network, error) {
+	switch version {
+	case MainnetSingleSig, MainnetMultiSig:
+		return Mainnet, nil
+	case TestnetSingleSig, TestnetMultiSig:
+		return Testnet, nil
+	default:
+		return 0, fmt.Errorf("%w %d", ErrUnknownVersion, version)
+	}
+}
+
+// BTC returns the Bitcoin address with the hash160 of a: P2PKH for single
+// signature and P2SH for multisig addresses.
+func (a Address) BTC() string {
+	var version byte
+	switch a.Version {
+	case MainnetSingleSig:
+		version = bitcoinMainnetP2PKH
+	case MainnetMultiSig:
+		version = bitcoinMainnetP2SH
+	case TestnetSingleSig:
+		version = bitcoinTestnetP2PKH
+	case TestnetMultiSig:
+		version = bitcoinTestnetP2SH
+	}
+	return base58CheckEncode(version, a.Hash160[:])
+}
+
+// FromBTC returns the Stacks address with the hash160 of a base58check
+// encoded P2PKH or P2SH Bitcoin address.
+func FromBTC(s string) (Address, error) {
+	version, hash160, err := base58CheckDecode(s)
+	if err != nil {
+		return Address{}, fmt.Errorf("%w %q: %w", ErrInvalidAddress, s, err)
+	}
+	var stacksVersion byte
+	switch version {
+	case bitcoinMainnetP2PKH:
+		stacksVersion = MainnetSingleSig
+	case bitcoinMainnetP2SH:
+		stacksVersion = MainnetMultiSig
+	case bitcoinTestnetP2PKH:
+		stacksVersion = TestnetSingleSig
+	case bitcoinTestnetP2SH:
+		stacksVersion = TestnetMultiSig
+	default:
+		return Address{}, fmt.Errorf("%q: %w 0x%02x", s, ErrUnknownVersion, version)
+	}
+	return New(stacksVersion, hash160)
+}
+
+// Principal is a standard principal, or a contract principal when Name is
+// set.
+type Principal struct {
+	Address Address
+	Name    string
+}
+
+var contractName = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]*$`)
+
+// ParsePrincipal parses a standard principal or a contract principal of the
+// form ADDRESS.NAME.
+func ParsePrincipal(s string) (Principal, error) {
+	addr, name, isContract := strings.Cut(s, ".")
+	a, err := Decode(addr)
+	if err != nil {
+		return Principal{}, err
+	}
+	if isContract {
+		if len(name) > 128 {
+			return Principal{}, fmt.Errorf("%w %q: longer than 128 characters", ErrInvalidContract, name)
+		}
+		if !contractName.MatchString(name) {
+			return Principal{}, fmt.Errorf("%w %q", ErrInvalidContract, name)
+		}
+	}
+	return Principal{Address: a, Name: name}, nil
+}
+
+// ParseContract parses a contract principal.
+func ParseContract(s string) (Principal, error) {
+	p, err := ParsePrincipal(s)
+	if err != nil {
+		return Principal{}, err
+	}
+	if !p.Contract() {
+		return Principal{}, fmt.Errorf("%q is not a contract principal, expected ADDRESS.NAME", s)
+	}
+	return p, nil
+}
+
+// Contract reports whether p is a contract principal.
+func (p Principal) Contract() bool {
+	return p.Name != ""
+}
+
+func (p Principal) String() string {
+	if p.Contract() {
+		return p.Address.String() + "." + p.Name
+	}
+	return p.Address.String()
+}
+
+// Check returns ErrWrongNetwork unless p belongs to network.
+func (p Principal) Check(network Network) error {
+	return p.Address.Check(network)
+}
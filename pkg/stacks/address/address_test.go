@@ -0,0 +1,158 @@
+package address
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// The four encodings of the hash160 a46ff88886c2ef9762d970b4d2c63678835bd39d.
+var vectors = []struct {
+	address, btc string
+	version      byte
+	network      Network
+	multiSig     bool
+}{
+	{"SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7", "1FzTxL9Mxnm2fdmnQEArfhzJHevwbvcH6d", MainnetSingleSig, Mainnet, false},
+	{"SM2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKQVX8X0G", "3GgUssdoWh5QkoUDXKqT6LMESBDf8aqp2y", MainnetMultiSig, Mainnet, true},
+	{"ST2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKQYAC0RQ", "mvWRFPELmpCHSkFQ7o9EVdCd9eXeUTa9T8", TestnetSingleSig, Testnet, false},
+	{"SN2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKP6D2ZK9", "2N8EgwcZq89akxb6mCTTKiHLVeXRpxjuy98", TestnetMultiSig, Testnet, true},
+}
+
+const hash160 = "a46ff88886c2ef9762d970b4d2c63678835bd39d"
+
+func TestDecode(t *testing.T) {
+	for _, tt := range vectors {
+		a, err := Decode(tt.address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Version != tt.version || hex.EncodeToString(a.Hash160[:]) != hash160 {
+			t.Errorf("Decode(%s) = %d, %x", tt.address, a.Version, a.Hash160)
+		}
+		if a.String() != tt.address || a.Network() != tt.network || a.MultiSig() != tt.multiSig {
+			t.Errorf("%s: String = %s, Network = %s, MultiSig = %v", tt.address, a, a.Network(), a.MultiSig())
+		}
+	}
+
+	burn, err := Decode("SP000000000000000000002Q6VF78")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if burn.Hash160 != [20]byte{} || burn.Version != MainnetSingleSig {
+		t.Errorf("burn address = %d, %x", burn.Version, burn.Hash160)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		address string
+		err     error
+	}{
+		{"SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ8", ErrInvalidAddress},
+		{"SN2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKQVX8X0G", ErrInvalidAddress},
+		{"SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ", ErrInvalidAddress},
+		{"1FzTxL9Mxnm2fdmnQEArfhzJHevwbvcH6d", ErrInvalidAddress},
+		{"", ErrInvalidAddress},
+		// Valid c32check with versions that are not Stacks address versions.
+		{"S02J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKPVKG2CE", ErrUnknownVersion},
+		{"SZ2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKQ9H6DPR", ErrUnknownVersion},
+	} {
+		if _, err := Decode(tt.address); !errors.Is(err, tt.err) {
+			t.Errorf("Decode(%q) = %v, want %v", tt.address, err, tt.err)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	for _, tt := range vectors {
+		a, err := Decode(tt.address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := a.Check(tt.network); err != nil {
+			t.Errorf("%s.Check(%s) = %v", tt.address, tt.network, err)
+		}
+		other := Testnet
+		if tt.network == Testnet {
+			other = Mainnet
+		}
+		if err := a.Check(other); !errors.Is(err, ErrWrongNetwork) {
+			t.Errorf("%s.Check(%s) = %v, want %v", tt.address, other, err, ErrWrongNetwork)
+		}
+	}
+}
+
+func TestBTC(t *testing.T) {
+	for _, tt := range vectors {
+		a, err := Decode(tt.address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := a.BTC(); got != tt.btc {
+			t.Errorf("%s.BTC() = %s, want %s", tt.address, got, tt.btc)
+		}
+		b, err := FromBTC(tt.btc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b != a {
+			t.Errorf("FromBTC(%s) = %s, want %s", tt.btc, b, tt.address)
+		}
+	}
+
+	if got := base58CheckEncode(bitcoinMainnetP2PKH, make([]byte, 20)); got != "1111111111111111111114oLvT2" {
+		t.Errorf("zero P2PKH = %s", got)
+	}
+}
+
+func TestFromBTCInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		address string
+		err     error
+	}{
+		// The last character changed.
+		{"1FzTxL9Mxnm2fdmnQEArfhzJHevwbvcH6e", ErrInvalidAddress},
+		{"1FzTxL9Mxnm2fdmnQEArfhzJHevwbvcH0d", ErrInvalidAddress},
+		{"1", ErrInvalidAddress},
+		// Version 0x80 is a WIF private key, not an address.
+		{"tmFfzEJEqt5BM7csXvoejkpzrDugXirapq", ErrUnknownVersion},
+		// A valid checksum over a 19 byte hash160.
+		{"14PzJySunGMQ2xTTbiVy7WDyw54b42cRr", ErrInvalidAddress},
+	} {
+		if _, err := FromBTC(tt.address); !errors.Is(err, tt.err) {
+			t.Errorf("FromBTC(%q) = %v, want %v", tt.address, err, tt.err)
+		}
+	}
+}
+
+func TestParsePrincipal(t *testing.T) {
+	const addr = "SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7"
+	for _, s := range []string{addr, addr + ".pox", addr + ".token-v2_1", addr + "." + strings.Repeat("a", 128)} {
+		p, err := ParsePrincipal(s)
+		if err != nil {
+			t.Errorf("ParsePrincipal(%s) = %v", s, err)
+			continue
+		}
+		if p.String() != s {
+			t.Errorf("ParsePrincipal(%s) = %s", s, p)
+		}
+	}
+	for _, name := range []string{"", "1pox", "-pox", "pox.v2", "pox token", strings.Repeat("a", 129)} {
+		if _, err := ParsePrincipal(addr + "." + name); !errors.Is(err, ErrInvalidContract) {
+			t.Errorf("ParsePrincipal with contract name %q = %v, want %v", name, err, ErrInvalidContract)
+		}
+	}
+
+	if _, err := ParseContract(addr); err == nil {
+		t.Error("ParseContract of a standard principal succeeded")
+	}
+	p, err := ParseContract("ST2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKQYAC0RQ.pox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Check(Mainnet); !errors.Is(err, ErrWrongNetwork) {
+		t.Errorf("testnet contract Check(Mainnet) = %v", err)
+	}
+}
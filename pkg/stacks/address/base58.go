@@ -0,0 +1,78 @@
+package address
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var bigRadix = big.NewInt(58)
+
+func base58Encode(data []byte) string {
+	n := new(big.Int).SetBytes(data)
+	var result []byte
+	mod := new(big.Int)
+	for n.Sign() > 0 {
+		n.DivMod(n, bigRadix, mod)
+		result = append(result, base58Alphabet[mod.Int64()])
+	}
+	// Each leading zero byte is a leading '1'
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		result = append(result, base58Alphabet[0])
+	}
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return string(result)
+}
+
+func base58Decode(s string) ([]byte, error) {
+	n := new(big.Int)
+	for i := 0; i < len(s); i++ {
+		digit := strings.IndexByte(base58Alphabet, s[i])
+		if digit < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", s[i])
+		}
+		n.Mul(n, bigRadix)
+		n.Add(n, big.NewInt(int64(digit)))
+	}
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
+
+func base58CheckEncode(version byte, data []byte) string {
+	payload := append([]byte{version}, data...)
+	return base58Encode(append(payload, base58Checksum(payload)...))
+}
+
+func base58CheckDecode(s string) (byte, []byte, error) {
+	decoded, err := base58Decode(s)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(decoded) < 5 {
+		return 0, nil, errors.New("base58check payload too short")
+	}
+	payload := decoded[:len(decoded)-4]
+	if !bytes.Equal(decoded[len(decoded)-4:], base58Checksum(payload)) {
+		return 0, nil, errors.New("invalid base58check checksum")
+	}
+	return payload[0], payload[1:], nil
+}
+
+func base58Checksum(payload []byte) []byte {
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	return second[:4]
+}
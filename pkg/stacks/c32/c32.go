@@ -0,0 +1,187 @@
+// Package c32 implements the Crockford base32 variant and the c32check
+// address encoding used by Stacks principals.
+package c32
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var (
+	ErrInvalidCharacter = errors.New("c32: invalid character")
+	ErrInvalidChecksum  = errors.New("c32: invalid checksum")
+	ErrInvalidAddress   = errors.New("c32: invalid address")
+)
+
+// Encode encodes the bytes as a c32 string. Leading zero bytes are kept as
+// leading '0' characters so that the encoding round-trips.
+func Encode(data []byte) string {
+	var result []byte
+	carry := byte(0)
+	carryBits := 0
+
+	for i := len(data) - 1; i >= 0; i-- {
+		current := data[i]
+		lowBitsToTake := 5 - carryBits
+		lowBits := current & ((1 << lowBitsToTake) - 1)
+		result = append(result, alphabet[(lowBits<<carryBits)+carry])
+		carryBits = (8 + carryBits) - 5
+		carry = current >> (8 - carryBits)
+		if carryBits >= 5 {
+			result = append(result, alphabet[carry&0x1f])
+			carryBits -= 5
+			carry >>= 5
+		}
+	}
+	if carryBits > 0 {
+		result = append(result, alphabet[carry])
+	}
+
+	// Remove the leading zeros produced by the encoding itself
+	for len(result) > 0 && result[len(result)-1] == alphabet[0] {
+		result = result[:len(result)-1]
+	}
+	// Add one leading zero per leading zero byte of the input
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		result = append(result, alphabet[0])
+	}
+
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return string(result)
+}
+
+// Decode decodes a c32 string. Lowercase input and the usual Crockford
+// substitutions (O for 0, I and L for 1) are accepted.
+func Decode(s string) ([]byte, error) {
+	digits := make([]byte, len(s))
+	for i := 0; i < len(s); i++ {
+		d, err := digit(s[len(s)-1-i])
+		if err != nil {
+			return nil, err
+		}
+		digits[i] = d
+	}
+
+	var result []byte
+	carry := uint16(0)
+	carryBits := 0
+	for _, d := range digits {
+		carry += uint16(d) << carryBits
+		carryBits += 5
+		if carryBits >= 8 {
+			result = append(result, byte(carry&0xff))
+			carryBits -= 8
+			carry >>= 8
+		}
+	}
+	if carryBits > 0 {
+		result = append(result, byte(carry))
+	}
+
+	for len(result) > 0 && result[len(result)-1] == 0 {
+		result = result[:len(result)-1]
+	}
+	for i := len(digits) - 1; i >= 0; i-- {
+		if digits[i] != 0 {
+			break
+		}
+		result = append(result, 0)
+	}
+
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result, nil
+}
+
+func digit(c byte) (byte, error) {
+	if c >= 'a' && c <= 'z' {
+		c -= 'a' - 'A'
+	}
+	switch c {
+	case 'O':
+		c = '0'
+	case 'I', 'L':
+		c = '1'
+	}
+	i := strings.IndexByte(alphabet, c)
+	if i < 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidCharacter, c)
+	}
+	return byte(i), nil
+}
+
+func checksum(version byte, data []byte) []byte {
+	first := sha256.Sum256(append([]byte{version}, data...))
+	second := sha256.Sum256(first[:])
+	return second[:4]
+}
+
+// CheckEncode encodes the version and data with a trailing checksum.
+func CheckEncode(version byte, data []byte) (string, error) {
+	if version >= 32 {
+		return "", fmt.Errorf("c32: invalid version %d", version)
+	}
+	payload := append(append([]byte{}, data...), checksum(version, data)...)
+	return string(alphabet[version]) + Encode(payload), nil
+}
+
+// CheckDecode reverses CheckEncode and verifies the checksum.
+func CheckDecode(s string) (byte, []byte, error) {
+	if len(s) < 2 {
+		return 0, nil, ErrInvalidAddress
+	}
+	version, err := digit(s[0])
+	if err != nil {
+		return 0, nil, err
+	}
+	payload, err := Decode(s[1:])
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(payload) < 4 {
+		return 0, nil, ErrInvalidChecksum
+	}
+	data := payload[:len(payload)-4]
+	if !bytes.Equal(payload[len(payload)-4:], checksum(version, data)) {
+		return 0, nil, ErrInvalidChecksum
+	}
+	return version, data, nil
+}
+
+// Address returns the Stacks address for the given version and hash160.
+func Address(version byte, hash160 []byte) (string, error) {
+	if len(hash160) != 20 {
+		return "", fmt.Errorf("c32: hash160 must be 20 bytes, got %d", len(hash160))
+	}
+	encoded, err := CheckEncode(version, hash160)
+	if err != nil {
+		return "", err
+	}
+	return "S" + encoded, nil
+}
+
+// DecodeAddress parses a Stacks address into its version and hash160.
+func DecodeAddress(address string) (byte, []byte, error) {
+	if len(address) < 3 || address[0] != 'S' {
+		return 0, nil, ErrInvalidAddress
+	}
+	version, hash160, err := CheckDecode(address[1:])
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(hash160) != 20 {
+		return 0, nil, ErrInvalidAddress
+	}
+	return version, hash160, nil
+}
@@ -0,0 +1,122 @@
+package c32
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+const hash160 = "a46ff88886c2ef9762d970b4d2c63678835bd39d"
+
+func TestEncode(t *testing.T) {
+	for _, tt := range []struct {
+		hex, c32 string
+	}{
+		{"", ""},
+		{"00", "0"},
+		{"01", "1"},
+		{"0001", "01"},
+		{"ff", "7Z"},
+		{"0000ff", "007Z"},
+		{"000000000000000000000000000000000000000001", "000000000000000000001"},
+		{hash160, "MHQZH246RBQSERPSE2TD5HHPF21NQMWX"},
+	} {
+		data, _ := hex.DecodeString(tt.hex)
+		if got := Encode(data); got != tt.c32 {
+			t.Errorf("Encode(%s) = %s, want %s", tt.hex, got, tt.c32)
+		}
+		got, err := Decode(tt.c32)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("Decode(%s) = %x, want %s", tt.c32, got, tt.hex)
+		}
+	}
+}
+
+func TestDecodeSubstitutions(t *testing.T) {
+	for _, tt := range []struct {
+		s, want string
+	}{
+		{"mhqzh246rbqserpse2td5hhpf21nqmwx", hash160},
+		{"OL", "0001"},
+		{"oI", "0001"},
+		{"l", "01"},
+	} {
+		got, err := Decode(tt.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(got) != tt.want {
+			t.Errorf("Decode(%s) = %x, want %s", tt.s, got, tt.want)
+		}
+	}
+	for _, s := range []string{"U", "7z!", "MHQZ H246"} {
+		if _, err := Decode(s); !errors.Is(err, ErrInvalidCharacter) {
+			t.Errorf("Decode(%q) = %v, want %v", s, err, ErrInvalidCharacter)
+		}
+	}
+}
+
+func TestAddress(t *testing.T) {
+	data, _ := hex.DecodeString(hash160)
+	zero := make([]byte, 20)
+	for _, tt := range []struct {
+		version byte
+		hash160 []byte
+		address string
+	}{
+		{22, data, "SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7"},
+		{20, data, "SM2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKQVX8X0G"},
+		{26, data, "ST2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKQYAC0RQ"},
+		{21, data, "SN2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKP6D2ZK9"},
+		{22, zero, "SP000000000000000000002Q6VF78"},
+		{26, zero, "ST000000000000000000002AMW42H"},
+	} {
+		got, err := Address(tt.version, tt.hash160)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.address {
+			t.Errorf("Address(%d, %x) = %s, want %s", tt.version, tt.hash160, got, tt.address)
+		}
+		version, hash, err := DecodeAddress(tt.address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version != tt.version || !bytes.Equal(hash, tt.hash160) {
+			t.Errorf("DecodeAddress(%s) = %d, %x", tt.address, version, hash)
+		}
+	}
+
+	if _, err := Address(22, data[:19]); err == nil {
+		t.Error("Address with a 19 byte hash160 succeeded")
+	}
+	if _, err := CheckEncode(32, data); err == nil {
+		t.Error("CheckEncode with version 32 succeeded")
+	}
+}
+
+func TestDecodeAddressInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		address string
+		err     error
+	}{
+		// The last character changed.
+		{"SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ8", ErrInvalidChecksum},
+		// The version changed from P to T.
+		{"ST2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7", ErrInvalidChecksum},
+		{"SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJU", ErrInvalidCharacter},
+		// A valid checksum over a 19 byte hash160.
+		{"SPA8VZRH23C5VWQCBCQ1D6JRRV7H0TVTFENS7H4", ErrInvalidAddress},
+		{"XP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7", ErrInvalidAddress},
+		{"SP", ErrInvalidAddress},
+		{"SP2J6", ErrInvalidChecksum},
+	} {
+		if _, _, err := DecodeAddress(tt.address); !errors.Is(err, tt.err) {
+			t.Errorf("DecodeAddress(%s) = %v, want %v", tt.address, err, tt.err)
+		}
+	}
+}
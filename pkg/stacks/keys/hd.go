@@ -0,0 +1,111 @@
+package keys
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+// StacksPath is the BIP44 derivation path of Stacks accounts, as used by
+// Leather and Xverse. The account index fills the last element.
+const StacksPath = "m/44'/5757'/0'/0/%d"
+
+const hardenedOffset = 0x80000000
+
+var errInvalidChild = errors.New("derived key is invalid, use the next index")
+
+// DeriveStacksAccount derives the private key of account index from a BIP39
+// seed.
+func DeriveStacksAccount(seed []byte, index uint32) (*PrivateKey, error) {
+	return DerivePath(seed, fmt.Sprintf(StacksPath, index))
+}
+
+// DerivePath derives the BIP32 private key at path from a seed. Hardened
+// elements are marked with ' or h.
+func DerivePath(seed []byte, path string) (*PrivateKey, error) {
+	indexes, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode := sum[:32], sum[32:]
+	if !validScalar(key) {
+		return nil, errInvalidChild
+	}
+
+	for _, index := range indexes {
+		key, chainCode, err = deriveChild(key, chainCode, index)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	priv, _ := btcec.PrivKeyFromBytes(key)
+	return NewPrivateKey(priv), nil
+}
+
+func parsePath(path string) ([]uint32, error) {
+	elements := strings.Split(path, "/")
+	if len(elements) == 0 || elements[0] != "m" {
+		return nil, fmt.Errorf("invalid derivation path %q", path)
+	}
+	indexes := make([]uint32, 0, len(elements)-1)
+	for _, e := range elements[1:] {
+		hardened := strings.HasSuffix(e, "'") || strings.HasSuffix(e, "h")
+		n, err := strconv.ParseUint(strings.TrimRight(e, "'h"), 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("invalid derivation path %q: %w", path, err)
+		}
+		index := uint32(n)
+		if hardened {
+			index += hardenedOffset
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+// deriveChild implements BIP32 CKDpriv.
+func deriveChild(key, chainCode []byte, index uint32) ([]byte, []byte, error) {
+	var data []byte
+	if index >= hardenedOffset {
+		data = append([]byte{0x00}, key...)
+	} else {
+		_, pub := btcec.PrivKeyFromBytes(key)
+		data = pub.SerializeCompressed()
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	mac := hmac.New(sha512.New, chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	if !validScalar(sum[:32]) {
+		return nil, nil, errInvalidChild
+	}
+
+	var tweak, parent btcec.ModNScalar
+	tweak.SetByteSlice(sum[:32])
+	parent.SetByteSlice(key)
+	tweak.Add(&parent)
+	if tweak.IsZero() {
+		return nil, nil, errInvalidChild
+	}
+	child := tweak.Bytes()
+	return child[:], sum[32:], nil
+}
+
+// validScalar reports whether b is a non-zero integer below the curve order.
+func validScalar(b []byte) bool {
+	var s btcec.ModNScalar
+	overflow := s.SetByteSlice(b)
+	return !overflow && !s.IsZero()
+}
@@ -0,0 +1,114 @@
+package keys
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/hashhavoc/teller/pkg/bip39"
+)
+
+// TestBIP32Vector checks test vector 1 of the BIP32 specification.
+func TestBIP32Vector(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	for _, tt := range []struct {
+		path string
+		key  string
+	}{
+		{"m", "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"},
+		{"m/0'", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{"m/0h/1", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{"m/0'/1/2'/2/1000000000", "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+	} {
+		key, err := DerivePath(seed, tt.path)
+		if err != nil {
+			t.Fatalf("DerivePath(%s): %v", tt.path, err)
+		}
+		if got := hex.EncodeToString(key.Key.Serialize()); got != tt.key {
+			t.Errorf("DerivePath(%s) = %s, want %s", tt.path, got, tt.key)
+		}
+	}
+}
+
+// TestDeriveStacksAccount checks m/44'/5757'/0'/0/i against the accounts
+// stacks.js derives from the same mnemonics.
+func TestDeriveStacksAccount(t *testing.T) {
+	for _, tt := range []struct {
+		mnemonic string
+		index    uint32
+		key      string
+		mainnet  string
+		testnet  string
+	}{
+		{
+			"sound idle panel often situate develop unit text design antenna vendor screen opinion balcony share trigger accuse scatter visa uniform brass update opinion media",
+			0,
+			"8721c6a5237f5e8d361161a7855aa56885a3e19e2ea6ee268fb14eabc5e2ed9001",
+			"SP384CVPNDTYA0E92TKJZQTYXQHNZSWGCAG7SAPVB",
+			"ST384CVPNDTYA0E92TKJZQTYXQHNZSWGCAH0ER64E",
+		},
+		{
+			"sound idle panel often situate develop unit text design antenna vendor screen opinion balcony share trigger accuse scatter visa uniform brass update opinion media",
+			1,
+			"7d9c34b4dc2eb32123a76925c4550d71a9bea2120fb852d15147b62a459c612e01",
+			"SP23K7K2V45JFZVBMQBE8R0PP8SQG7HZF9473KBD",
+			"ST23K7K2V45JFZVBMQBE8R0PP8SQG7HZFA6Z68VE",
+		},
+		{
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			0,
+			"47382d0211f3bbb11812b5e60b696a93d7ad0a91cdeb2162f7d69d4adef48b5d01",
+			"SPC5KHM41H6WHAST7MWWDD807YSPRQKJ69FSH54J",
+			"",
+		},
+		{
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			1,
+			"789db648a1d9d181f1e7d3f05ed0aa939db9c7e5cbe12e35cf6e38547497026a01",
+			"SP3XHES5990FYDV5BHBZCJRFYFD2Z4X3FMD2N3MGH",
+			"",
+		},
+	} {
+		key, err := DeriveStacksAccount(bip39.NewSeed(tt.mnemonic, ""), tt.index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := key.Hex(); got != tt.key {
+			t.Errorf("account %d key = %s, want %s", tt.index, got, tt.key)
+		}
+		for version, want := range map[byte]string{22: tt.mainnet, 26: tt.testnet} {
+			if want == "" {
+				continue
+			}
+			got, err := key.Address(version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("account %d address %d = %s, want %s", tt.index, version, got, want)
+			}
+		}
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	got, err := parsePath("m/44'/5757h/0'/0/3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []uint32{44 + hardenedOffset, 5757 + hardenedOffset, hardenedOffset, 0, 3}
+	if len(got) != len(want) {
+		t.Fatalf("parsePath = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("parsePath = %v, want %v", got, want)
+			break
+		}
+	}
+
+	for _, path := range []string{"", "44'/0", "m/x", "m/2147483648", "m/-1", "m//0"} {
+		if _, err := parsePath(path); err == nil {
+			t.Errorf("parsePath(%q) succeeded", path)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Package keys handles secp256k1 keys for Stacks accounts: parsing private
+// keys, hashing public keys and deriving single signature addresses.
+package keys
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/hashhavoc/teller/pkg/stacks/c32"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// PrivateKey is a secp256k1 private key along with whether its public key is
+// serialized compressed, which changes the derived address.
+type PrivateKey struct {
+	Key        *btcec.PrivateKey
+	Compressed bool
+}
+
+// ParsePrivateKey parses a hex private key. Stacks wallets append 01 to the
+// 32 byte key to mark it as compressed. A bare 32 byte key, as printed by
+// wallet gen, is also treated as compressed.
+func ParsePrivateKey(s string) (*PrivateKey, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(s), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	switch {
+	case len(b) == 33 && b[32] == 0x01:
+		b = b[:32]
+	case len(b) != 32:
+		return nil, errors.New("invalid private key: expected 32 bytes, or 33 ending in 01")
+	}
+	if !validScalar(b) {
+		return nil, errors.New("invalid private key: must be between 1 and the curve order")
+	}
+	key, _ := btcec.PrivKeyFromBytes(b)
+	return &PrivateKey{Key: key, Compressed: true}, nil
+}
+
+// NewPrivateKey wraps an existing key as a compressed Stacks key.
+func NewPrivateKey(key *btcec.PrivateKey) *PrivateKey {
+	return &PrivateKey{Key: key, Compressed: true}
+}
+
+// Hex returns the key in the format read by ParsePrivateKey.
+func (k *PrivateKey) Hex() string {
+	s := hex.EncodeToString(k.Key.Serialize())
+	if k.Compressed {
+		s += "01"
+	}
+	return s
+}
+
+// PublicKey returns the serialized public key.
+func (k *PrivateKey) PublicKey() []byte {
+	if k.Compressed {
+		return k.Key.PubKey().SerializeCompressed()
+	}
+	return k.Key.PubKey().SerializeUncompressed()
+}
+
+// Address returns the single signature address of the key for the version.
+func (k *PrivateKey) Address(version byte) (string, error) {
+	return c32.Address(version, Hash160(k.PublicKey()))
+}
+
+// SignRecoverable signs a 32 byte digest and returns the 65 byte signature
+// in the VRS layout Stacks uses: recovery id, then r and s.
+func (k *PrivateKey) SignRecoverable(digest []byte) []byte {
+	compact := ecdsa.SignCompact(k.Key, digest, k.Compressed)
+	// SignCompact prefixes the recovery id with 27, plus 4 for compressed
+	// keys, following the bitcoin message signing convention.
+	compact[0] = (compact[0] - 27) & 0x03
+	return compact
+}
+
+// RecoverPublicKey recovers the public key from a VRS signature over the
+// digest. The key is returned in the encoding given by compressed.
+func RecoverPublicKey(digest, signature []byte, compressed bool) ([]byte, error) {
+	if len(signature) != 65 {
+		return nil, errors.New("signature must be 65 bytes")
+	}
+	compact := make([]byte, 65)
+	copy(compact, signature)
+	compact[0] = signature[0] + 27
+	if compressed {
+		compact[0] += 4
+	}
+	pub, _, err := ecdsa.RecoverCompact(compact, digest)
+	if err != nil {
+		return nil, err
+	}
+	if compressed {
+		return pub.SerializeCompressed(), nil
+	}
+	return pub.SerializeUncompressed(), nil
+}
+
+// Hash160 returns RIPEMD160(SHA256(b)).
+func Hash160(b []byte) []byte {
+	sha := sha256.Sum256(b)
+	h := ripemd160.New()
+	h.Write(sha[:])
+	return h.Sum(nil)
+}
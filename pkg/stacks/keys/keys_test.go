@@ -0,0 +1,65 @@
+package keys
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePrivateKey(t *testing.T) {
+	for _, s := range []string{
+		"edf9aee84d9b7abc145504dde6726c64f369d37ee34ded868fabd876c26570bc01",
+		"0xedf9aee84d9b7abc145504dde6726c64f369d37ee34ded868fabd876c26570bc",
+		"0000000000000000000000000000000000000000000000000000000000000001",
+		// The curve order minus one.
+		"fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364140",
+	} {
+		key, err := ParsePrivateKey(s)
+		if err != nil {
+			t.Errorf("ParsePrivateKey(%s): %v", s, err)
+			continue
+		}
+		if !key.Compressed {
+			t.Errorf("ParsePrivateKey(%s) is not compressed", s)
+		}
+		if got, want := key.Hex(), strings.TrimPrefix(s, "0x")[:64]+"01"; got != want {
+			t.Errorf("Hex = %s, want %s", got, want)
+		}
+	}
+
+	for name, s := range map[string]string{
+		"zero":         "0000000000000000000000000000000000000000000000000000000000000000",
+		"zero with 01": "000000000000000000000000000000000000000000000000000000000000000001",
+		"curve order":  "fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141",
+		"above order":  "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		"short":        "edf9aee84d9b7abc",
+		"bad suffix":   "edf9aee84d9b7abc145504dde6726c64f369d37ee34ded868fabd876c26570bc02",
+		"not hex":      "zz",
+	} {
+		if _, err := ParsePrivateKey(s); err == nil {
+			t.Errorf("%s: ParsePrivateKey(%s) succeeded", name, s)
+		}
+	}
+}
+
+func TestRecoverPublicKey(t *testing.T) {
+	key, err := ParsePrivateKey("edf9aee84d9b7abc145504dde6726c64f369d37ee34ded868fabd876c26570bc01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := make([]byte, 32)
+	digest[31] = 1
+	for _, compressed := range []bool{true, false} {
+		key.Compressed = compressed
+		sig := key.SignRecoverable(digest)
+		got, err := RecoverPublicKey(digest, sig, compressed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(key.PublicKey()) {
+			t.Errorf("compressed %t: recovered %x, want %x", compressed, got, key.PublicKey())
+		}
+	}
+	if _, err := RecoverPublicKey(digest, make([]byte, 64), true); err == nil {
+		t.Error("RecoverPublicKey accepted a 64 byte signature")
+	}
+}
@@ -0,0 +1,412 @@
+package transaction
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashhavoc/teller/pkg/clarity"
+)
+
+var ErrUnexpectedEOF = errors.New("transaction: unexpected end of input")
+
+// Decode parses a serialized transaction. It is an error for b to contain
+// trailing bytes.
+func Decode(b []byte) (*Transaction, error) {
+	d := decoder{buf: b}
+	t, err := d.transaction()
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.buf) {
+		return nil, fmt.Errorf("transaction: %d trailing bytes", len(d.buf)-d.pos)
+	}
+	return t, nil
+}
+
+// DecodeHex parses a hex encoded transaction, with or without a 0x prefix.
+func DecodeHex(s string) (*Transaction, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(s), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("transaction: invalid hex: %w", err)
+	}
+	return Decode(b)
+}
+
+type decoder struct {
+	buf []byte
+	pos int
+}
+
+func (d *decoder) read(n int) ([]byte, error) {
+	if n < 0 || len(d.buf)-d.pos < n {
+		return nil, ErrUnexpectedEOF
+	}
+	b := d.buf[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *decoder) byte() (byte, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (d *decoder) uint32() (uint32, error) {
+	b, err := d.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(b), nil
+}
+
+func (d *decoder) uint64() (uint64, error) {
+	b, err := d.read(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// clarityValue decodes one Clarity value from the current position.
+func (d *decoder) clarityValue() (clarity.Value, error) {
+	v, n, err := clarity.DecodePrefix(d.buf[d.pos:])
+	if err != nil {
+		return nil, err
+	}
+	d.pos += n
+	return v, nil
+}
+
+func (d *decoder) transaction() (*Transaction, error) {
+	t := &Transaction{}
+	var err error
+	if t.Version, err = d.byte(); err != nil {
+		return nil, err
+	}
+	if t.ChainID, err = d.uint32(); err != nil {
+		return nil, err
+	}
+
+	authType, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	t.Auth.Type = AuthType(authType)
+	switch t.Auth.Type {
+	case AuthStandard, AuthSponsored:
+	default:
+		return nil, fmt.Errorf("transaction: unknown auth type 0x%02x", authType)
+	}
+	if t.Auth.Origin, err = d.spendingCondition(); err != nil {
+		return nil, err
+	}
+	if t.Auth.Type == AuthSponsored {
+		sponsor, err := d.spendingCondition()
+		if err != nil {
+			return nil, err
+		}
+		t.Auth.Sponsor = &sponsor
+	}
+
+	anchorMode, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	t.AnchorMode = AnchorMode(anchorMode)
+	postConditionMode, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	t.PostConditionMode = PostConditionMode(postConditionMode)
+	count, err := d.uint32()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint32(0); i < count; i++ {
+		pc, err := d.postCondition()
+		if err != nil {
+			return nil, err
+		}
+		t.PostConditions = append(t.PostConditions, pc)
+	}
+
+	if t.Payload, err = d.payload(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (d *decoder) name() (string, error) {
+	n, err := d.byte()
+	if err != nil {
+		return "", err
+	}
+	b, err := d.read(int(n))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (d *decoder) standardPrincipal() (clarity.StandardPrincipal, error) {
+	b, err := d.read(21)
+	if err != nil {
+		return clarity.StandardPrincipal{}, err
+	}
+	p := clarity.StandardPrincipal{Version: b[0]}
+	copy(p.Hash160[:], b[1:])
+	return p, nil
+}
+
+func (d *decoder) postConditionPrincipal() (PostConditionPrincipal, error) {
+	kind, err := d.byte()
+	if err != nil {
+		return PostConditionPrincipal{}, err
+	}
+	switch postConditionPrincipalType(kind) {
+	case principalOrigin:
+		return PostConditionPrincipal{Origin: true}, nil
+	case principalStandard:
+		address, err := d.standardPrincipal()
+		if err != nil {
+			return PostConditionPrincipal{}, err
+		}
+		return PostConditionPrincipal{Principal: address}, nil
+	case principalContract:
+		address, err := d.standardPrincipal()
+		if err != nil {
+			return PostConditionPrincipal{}, err
+		}
+		name, err := d.name()
+		if err != nil {
+			return PostConditionPrincipal{}, err
+		}
+		return PostConditionPrincipal{Principal: clarity.ContractPrincipal{Address: address, Name: name}}, nil
+	default:
+		return PostConditionPrincipal{}, fmt.Errorf("transaction: unknown post condition principal 0x%02x", kind)
+	}
+}
+
+func (d *decoder) assetInfo() (AssetInfo, error) {
+	var a AssetInfo
+	var err error
+	if a.Address, err = d.standardPrincipal(); err != nil {
+		return a, err
+	}
+	if a.ContractName, err = d.name(); err != nil {
+		return a, err
+	}
+	if a.AssetName, err = d.name(); err != nil {
+		return a, err
+	}
+	return a, nil
+}
+
+func (d *decoder) postCondition() (PostCondition, error) {
+	kind, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	principal, err := d.postConditionPrincipal()
+	if err != nil {
+		return nil, err
+	}
+
+	switch assetInfoType(kind) {
+	case assetInfoSTX:
+		code, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		amount, err := d.uint64()
+		if err != nil {
+			return nil, err
+		}
+		return &STXPostCondition{Principal: principal, Code: FungibleConditionCode(code), Amount: amount}, nil
+	case assetInfoFungible:
+		asset, err := d.assetInfo()
+		if err != nil {
+			return nil, err
+		}
+		code, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		amount, err := d.uint64()
+		if err != nil {
+			return nil, err
+		}
+		return &FungiblePostCondition{Principal: principal, Asset: asset, Code: FungibleConditionCode(code), Amount: amount}, nil
+	case assetInfoNonFungible:
+		asset, err := d.assetInfo()
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.clarityValue()
+		if err != nil {
+			return nil, err
+		}
+		code, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		return &NonFungiblePostCondition{Principal: principal, Asset: asset, Value: value, Code: NonFungibleConditionCode(code)}, nil
+	default:
+		return nil, fmt.Errorf("transaction: unknown post condition type 0x%02x", kind)
+	}
+}
+
+func (d *decoder) spendingCondition() (SpendingCondition, error) {
+	var sc SpendingCondition
+	hashMode, err := d.byte()
+	if err != nil {
+		return sc, err
+	}
+	sc.HashMode = HashMode(hashMode)
+	if !sc.HashMode.singleSig() && !sc.HashMode.multiSig() {
+		return sc, fmt.Errorf("transaction: unsupported hash mode %s", sc.HashMode)
+	}
+	signer, err := d.read(20)
+	if err != nil {
+		return sc, err
+	}
+	copy(sc.Signer[:], signer)
+	if sc.Nonce, err = d.uint64(); err != nil {
+		return sc, err
+	}
+	if sc.Fee, err = d.uint64(); err != nil {
+		return sc, err
+	}
+	if sc.HashMode.multiSig() {
+		count, err := d.uint32()
+		if err != nil {
+			return sc, err
+		}
+		for i := uint32(0); i < count; i++ {
+			f, err := d.authField()
+			if err != nil {
+				return sc, err
+			}
+			sc.Fields = append(sc.Fields, f)
+		}
+		required, err := d.read(2)
+		if err != nil {
+			return sc, err
+		}
+		sc.SignaturesRequired = binary.BigEndian.Uint16(required)
+		return sc, nil
+	}
+	keyEncoding, err := d.byte()
+	if err != nil {
+		return sc, err
+	}
+	sc.KeyEncoding = KeyEncoding(keyEncoding)
+	signature, err := d.read(65)
+	if err != nil {
+		return sc, err
+	}
+	copy(sc.Signature[:], signature)
+	return sc, nil
+}
+
+func (d *decoder) authField() (AuthField, error) {
+	kind, err := d.byte()
+	if err != nil {
+		return AuthField{}, err
+	}
+	f := AuthField{Type: AuthFieldType(kind)}
+	switch f.Type {
+	case AuthFieldPublicKeyCompressed, AuthFieldPublicKeyUncompressed:
+		b, err := d.read(33)
+		if err != nil {
+			return f, err
+		}
+		f.PublicKey = append([]byte(nil), b...)
+	case AuthFieldSignatureCompressed, AuthFieldSignatureUncompressed:
+		b, err := d.read(65)
+		if err != nil {
+			return f, err
+		}
+		copy(f.Signature[:], b)
+	default:
+		return f, fmt.Errorf("transaction: unknown auth field type 0x%02x", kind)
+	}
+	return f, nil
+}
+
+func (d *decoder) payload() (Payload, error) {
+	payloadType, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	switch PayloadType(payloadType) {
+	case PayloadTokenTransfer:
+		p := &TokenTransferPayload{}
+		if p.Recipient, err = d.clarityValue(); err != nil {
+			return nil, err
+		}
+		if p.Amount, err = d.uint64(); err != nil {
+			return nil, err
+		}
+		memo, err := d.read(MemoLength)
+		if err != nil {
+			return nil, err
+		}
+		copy(p.Memo[:], memo)
+		return p, nil
+	case PayloadSmartContract, PayloadVersionedSmartContract:
+		p := &SmartContractPayload{}
+		if PayloadType(payloadType) == PayloadVersionedSmartContract {
+			if p.ClarityVersion, err = d.byte(); err != nil {
+				return nil, err
+			}
+		}
+		if p.Name, err = d.name(); err != nil {
+			return nil, err
+		}
+		n, err := d.uint32()
+		if err != nil {
+			return nil, err
+		}
+		code, err := d.read(int(n))
+		if err != nil {
+			return nil, err
+		}
+		p.Code = string(code)
+		return p, nil
+	case PayloadContractCall:
+		p := &ContractCallPayload{}
+		address, err := d.standardPrincipal()
+		if err != nil {
+			return nil, err
+		}
+		name, err := d.name()
+		if err != nil {
+			return nil, err
+		}
+		p.Contract = clarity.ContractPrincipal{Address: address, Name: name}
+		if p.Function, err = d.name(); err != nil {
+			return nil, err
+		}
+		count, err := d.uint32()
+		if err != nil {
+			return nil, err
+		}
+		for i := uint32(0); i < count; i++ {
+			arg, err := d.clarityValue()
+			if err != nil {
+				return nil, err
+			}
+			p.Args = append(p.Args, arg)
+		}
+		return p, nil
+	default:
+		return nil, fmt.Errorf("transaction: unsupported payload type %s", PayloadType(payloadType))
+	}
+}
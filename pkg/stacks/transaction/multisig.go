@@ -0,0 +1,305 @@
+package transaction
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/hashhavoc/teller/pkg/stacks/keys"
+)
+
+type AuthFieldType byte
+
+const (
+	AuthFieldPublicKeyCompressed   AuthFieldType = 0x00
+	AuthFieldPublicKeyUncompressed AuthFieldType = 0x01
+	AuthFieldSignatureCompressed   AuthFieldType = 0x02
+	AuthFieldSignatureUncompressed AuthFieldType = 0x03
+)
+
+// AuthField is one key of a multisig spending condition, either its public
+// key or, once the key has signed, its signature.
+type AuthField struct {
+	Type AuthFieldType
+	// PublicKey is the 33 byte compressed key of a public key field, the
+	// type tells which encoding the redeem script uses.
+	PublicKey []byte
+	Signature [65]byte
+}
+
+// IsSignature reports whether the key of the field has signed.
+func (f AuthField) IsSignature() bool {
+	return f.Type == AuthFieldSignatureCompressed || f.Type == AuthFieldSignatureUncompressed
+}
+
+func (f AuthField) compressed() bool {
+	return f.Type == AuthFieldPublicKeyCompressed || f.Type == AuthFieldSignatureCompressed
+}
+
+func (f AuthField) encode(buf *bytes.Buffer) {
+	buf.WriteByte(byte(f.Type))
+	if f.IsSignature() {
+		buf.Write(f.Signature[:])
+		return
+	}
+	buf.Write(f.PublicKey)
+}
+
+// maxMultiSigKeys is the most keys a redeem script can list with the small
+// integer opcodes.
+const maxMultiSigKeys = 16
+
+// redeemScript returns the bitcoin script OP_m <keys> OP_n OP_CHECKMULTISIG
+// whose hash is the multisig address.
+func redeemScript(required int, publicKeys [][]byte) ([]byte, error) {
+	if len(publicKeys) == 0 || len(publicKeys) > maxMultiSigKeys {
+		return nil, fmt.Errorf("a multisig needs 1 to %d public keys, got %d", maxMultiSigKeys, len(publicKeys))
+	}
+	if required < 1 || required > len(publicKeys) {
+		return nil, fmt.Errorf("required signatures must be between 1 and %d, got %d", len(publicKeys), required)
+	}
+	var buf bytes.Buffer
+	buf.WriteByte(0x50 + byte(required))
+	for _, pk := range publicKeys {
+		buf.WriteByte(byte(len(pk)))
+		buf.Write(pk)
+	}
+	buf.WriteByte(0x50 + byte(len(publicKeys)))
+	buf.WriteByte(0xae)
+	return buf.Bytes(), nil
+}
+
+// multiSigSigner hashes the redeem script the way the hash mode commits to
+// it.
+func multiSigSigner(hashMode HashMode, script []byte) []byte {
+	switch hashMode {
+	case HashModeP2WSH, HashModeP2WSHNonSequential:
+		sum := sha256.Sum256(script)
+		return keys.Hash160(append([]byte{0x00, 0x20}, sum[:]...))
+	default:
+		return keys.Hash160(script)
+	}
+}
+
+// parsePublicKeys validates the keys and returns their auth fields.
+func parsePublicKeys(publicKeys [][]byte) ([]AuthField, error) {
+	fields := make([]AuthField, len(publicKeys))
+	for i, pk := range publicKeys {
+		parsed, err := btcec.ParsePubKey(pk)
+		if err != nil {
+			return nil, fmt.Errorf("public key %d: %w", i+1, err)
+		}
+		fields[i] = AuthField{Type: AuthFieldPublicKeyCompressed, PublicKey: parsed.SerializeCompressed()}
+		if len(pk) != 33 {
+			fields[i].Type = AuthFieldPublicKeyUncompressed
+		}
+	}
+	return fields, nil
+}
+
+// MultiSigAddress returns the address of the P2SH multisig of publicKeys
+// needing required signatures. The order of the keys matters.
+func MultiSigAddress(network Network, required int, publicKeys [][]byte) (string, error) {
+	sc, err := NewMultiSigSpendingCondition(required, publicKeys, false, 0, 0)
+	if err != nil {
+		return "", err
+	}
+	return sc.Address(network), nil
+}
+
+// NewMultiSigSpendingCondition returns an unsigned P2SH multisig spending
+// condition. Unless sequential is set it uses the non-sequential hash mode,
+// which lets the keys sign independently in any order.
+func NewMultiSigSpendingCondition(required int, publicKeys [][]byte, sequential bool, nonce, fee uint64) (SpendingCondition, error) {
+	fields, err := parsePublicKeys(publicKeys)
+	if err != nil {
+		return SpendingCondition{}, err
+	}
+	script, err := redeemScript(required, publicKeys)
+	if err != nil {
+		return SpendingCondition{}, err
+	}
+	sc := SpendingCondition{
+		HashMode:           HashModeP2SHNonSequential,
+		Nonce:              nonce,
+		Fee:                fee,
+		Fields:             fields,
+		SignaturesRequired: uint16(required),
+	}
+	if sequential {
+		sc.HashMode = HashModeP2SH
+	}
+	copy(sc.Signer[:], multiSigSigner(sc.HashMode, script))
+	return sc, nil
+}
+
+// SignatureCount returns the number of keys of a multisig condition that
+// have signed.
+func (sc SpendingCondition) SignatureCount() int {
+	n := 0
+	for _, f := range sc.Fields {
+		if f.IsSignature() {
+			n++
+		}
+	}
+	return n
+}
+
+// multiSigPresigns returns the hash the key of each field signs. Every key
+// signs the same hash in the non-sequential modes. In the sequential modes
+// each signature is chained into the hash signed by the following keys.
+func (t *Transaction) multiSigPresigns() ([][]byte, error) {
+	origin := t.Auth.Origin
+	sigHash, err := t.initialSigHash()
+	if err != nil {
+		return nil, err
+	}
+	presigns := make([][]byte, len(origin.Fields))
+	for i, f := range origin.Fields {
+		presigns[i] = presignSigHash(sigHash, AuthStandard, origin.Fee, origin.Nonce)
+		if origin.HashMode.sequential() && f.IsSignature() {
+			encoding := KeyEncodingUncompressed
+			if f.compressed() {
+				encoding = KeyEncodingCompressed
+			}
+			postsign := append(append(append([]byte{}, presigns[i]...), byte(encoding)), f.Signature[:]...)
+			sum := sha512.Sum512_256(postsign)
+			sigHash = sum[:]
+		}
+	}
+	return presigns, nil
+}
+
+// SignMultiSig adds the signature of key to the origin multisig condition.
+// In the sequential modes the keys must sign in the order they are listed.
+func (t *Transaction) SignMultiSig(key *keys.PrivateKey) error {
+	origin := &t.Auth.Origin
+	if !origin.HashMode.multiSig() {
+		return fmt.Errorf("cannot add a multisig signature to a %s spending condition", origin.HashMode)
+	}
+	if origin.SignatureCount() >= int(origin.SignaturesRequired) {
+		return fmt.Errorf("transaction already has the %d required signatures", origin.SignaturesRequired)
+	}
+
+	publicKey := key.Key.PubKey().SerializeCompressed()
+	index := -1
+	for i, f := range origin.Fields {
+		if !f.IsSignature() && bytes.Equal(f.PublicKey, publicKey) {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return errors.New("key is not one of the multisig keys or has already signed")
+	}
+	if origin.HashMode.sequential() {
+		for i := index + 1; i < len(origin.Fields); i++ {
+			if origin.Fields[i].IsSignature() {
+				return fmt.Errorf("key %d has already signed, sequential multisig keys must sign in order", i+1)
+			}
+		}
+	}
+
+	presigns, err := t.multiSigPresigns()
+	if err != nil {
+		return err
+	}
+	field := AuthField{Type: AuthFieldSignatureCompressed}
+	if !origin.Fields[index].compressed() {
+		field.Type = AuthFieldSignatureUncompressed
+	}
+	copy(field.Signature[:], key.SignRecoverable(presigns[index]))
+	origin.Fields[index] = field
+	return nil
+}
+
+// verifyMultiSig checks that the origin has exactly the required number of
+// valid signatures and that its keys hash to the signer.
+func (t *Transaction) verifyMultiSig() error {
+	origin := t.Auth.Origin
+	presigns, err := t.multiSigPresigns()
+	if err != nil {
+		return err
+	}
+	publicKeys := make([][]byte, len(origin.Fields))
+	for i, f := range origin.Fields {
+		if !f.IsSignature() {
+			parsed, err := btcec.ParsePubKey(f.PublicKey)
+			if err != nil {
+				return fmt.Errorf("public key %d: %w", i+1, err)
+			}
+			publicKeys[i] = parsed.SerializeCompressed()
+			if !f.compressed() {
+				publicKeys[i] = parsed.SerializeUncompressed()
+			}
+			continue
+		}
+		publicKeys[i], err = keys.RecoverPublicKey(presigns[i], f.Signature[:], f.compressed())
+		if err != nil {
+			return fmt.Errorf("signature %d: %w", i+1, err)
+		}
+	}
+
+	if count := origin.SignatureCount(); count != int(origin.SignaturesRequired) {
+		return fmt.Errorf("transaction has %d of %d required signatures", count, origin.SignaturesRequired)
+	}
+	script, err := redeemScript(int(origin.SignaturesRequired), publicKeys)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(multiSigSigner(origin.HashMode, script), origin.Signer[:]) {
+		return errors.New("signatures do not match the multisig keys")
+	}
+	return nil
+}
+
+// CombineMultiSig merges the signatures of copies of the same multisig
+// transaction signed by different keys. Copies of a sequential multisig
+// only combine into a valid transaction if each key signed after the keys
+// listed before it.
+func CombineMultiSig(txs ...*Transaction) (*Transaction, error) {
+	if len(txs) == 0 {
+		return nil, errors.New("no transactions to combine")
+	}
+	base := txs[0]
+	if !base.Auth.Origin.HashMode.multiSig() {
+		return nil, fmt.Errorf("cannot combine %s transactions", base.Auth.Origin.HashMode)
+	}
+	baseHash, err := base.initialSigHash()
+	if err != nil {
+		return nil, err
+	}
+
+	combined := *base
+	combined.Auth.Origin.Fields = append([]AuthField(nil), base.Auth.Origin.Fields...)
+	fields := combined.Auth.Origin.Fields
+	for n, tx := range txs[1:] {
+		origin := tx.Auth.Origin
+		sigHash, err := tx.initialSigHash()
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(sigHash, baseHash) || origin.Signer != base.Auth.Origin.Signer ||
+			origin.Nonce != base.Auth.Origin.Nonce || origin.Fee != base.Auth.Origin.Fee ||
+			len(origin.Fields) != len(fields) {
+			return nil, fmt.Errorf("transaction %d is not a copy of the first transaction", n+2)
+		}
+		for i, f := range origin.Fields {
+			switch {
+			case !f.IsSignature():
+			case !fields[i].IsSignature():
+				fields[i] = f
+			case fields[i].Type != f.Type || fields[i].Signature != f.Signature:
+				return nil, fmt.Errorf("transactions have different signatures for key %d", i+1)
+			}
+		}
+	}
+
+	if count := combined.Auth.Origin.SignatureCount(); count > int(combined.Auth.Origin.SignaturesRequired) {
+		return nil, fmt.Errorf("combined transaction has %d signatures, only %d are allowed", count, combined.Auth.Origin.SignaturesRequired)
+	}
+	return &combined, nil
+}
@@ -0,0 +1,284 @@
+package transaction
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// multiSigKeys are the three keys of the 2-of-3 multisig vectors, listed in
+// the order of the redeem script.
+var multiSigKeys = []string{
+	"6d430bb91222408e7706c9001cfaeb91b08c2be6d5ac95779ab52c6b431950e001",
+	"2a584d899fed1d24e26b524f202763c8ab30260167429f157f1c119f550fa6af01",
+	"d5200dee706ee53ae98a03fba6cf4fdcc5084c30cfa9e1b3462dcdeaa3e0f1d201",
+}
+
+func multiSigPublicKeys(t *testing.T) [][]byte {
+	t.Helper()
+	publicKeys := make([][]byte, len(multiSigKeys))
+	for i, k := range multiSigKeys {
+		publicKeys[i] = mustKey(t, k).PublicKey()
+	}
+	return publicKeys
+}
+
+// multiSigTransfer returns the unsigned 2-of-3 transfer of the vectors with
+// the origin in hashMode.
+func multiSigTransfer(t *testing.T, hashMode HashMode) *Transaction {
+	t.Helper()
+	publicKeys := multiSigPublicKeys(t)
+	sequential := hashMode == HashModeP2SH || hashMode == HashModeP2WSH
+	sc, err := NewMultiSigSpendingCondition(2, publicKeys, sequential, 7, 400)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashMode == HashModeP2WSH || hashMode == HashModeP2WSHNonSequential {
+		script, err := redeemScript(2, publicKeys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sc.HashMode = hashMode
+		copy(sc.Signer[:], multiSigSigner(hashMode, script))
+	}
+	payload, err := NewTokenTransfer("SP3FGQ8Z7JY9BWYZ5WM53E0M9NK7WHJF0691NZ159", 2500000, "multisig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(Mainnet, sc, payload)
+}
+
+func signMultiSig(t *testing.T, tx *Transaction, signers ...int) {
+	t.Helper()
+	for _, i := range signers {
+		if err := tx.SignMultiSig(mustKey(t, multiSigKeys[i])); err != nil {
+			t.Fatalf("key %d: %v", i+1, err)
+		}
+	}
+}
+
+func TestMultiSigAddress(t *testing.T) {
+	publicKeys := multiSigPublicKeys(t)
+	for _, tt := range []struct {
+		network Network
+		want    string
+	}{
+		{Mainnet, "SM2H3XA4XCMMTRJ5CESQQ43J81FQCFWCJEEQWBT6P"},
+		{Testnet, "SN2H3XA4XCMMTRJ5CESQQ43J81FQCFWCJECSDY9GF"},
+	} {
+		got, err := MultiSigAddress(tt.network, 2, publicKeys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("MultiSigAddress(%s) = %s, want %s", tt.network.Name, got, tt.want)
+		}
+	}
+
+	// Both P2SH modes commit to the same script hash, as do both P2WSH modes.
+	for _, tt := range []struct {
+		hashMode HashMode
+		want     string
+	}{
+		{HashModeP2SH, "SM2H3XA4XCMMTRJ5CESQQ43J81FQCFWCJEEQWBT6P"},
+		{HashModeP2SHNonSequential, "SM2H3XA4XCMMTRJ5CESQQ43J81FQCFWCJEEQWBT6P"},
+		{HashModeP2WSH, "SM3TWZDGT0ZXM38S1JZD03KG3724F1ZMMMW4C99G8"},
+		{HashModeP2WSHNonSequential, "SM3TWZDGT0ZXM38S1JZD03KG3724F1ZMMMW4C99G8"},
+	} {
+		if got := multiSigTransfer(t, tt.hashMode).Auth.Origin.Address(Mainnet); got != tt.want {
+			t.Errorf("address in %s = %s, want %s", tt.hashMode, got, tt.want)
+		}
+	}
+
+	// The order of the keys is part of the script.
+	reordered := [][]byte{publicKeys[1], publicKeys[0], publicKeys[2]}
+	if got, _ := MultiSigAddress(Mainnet, 2, reordered); got == "SM2H3XA4XCMMTRJ5CESQQ43J81FQCFWCJEEQWBT6P" {
+		t.Error("reordering the keys kept the address")
+	}
+
+	for _, required := range []int{0, 4} {
+		if _, err := MultiSigAddress(Mainnet, required, publicKeys); err == nil {
+			t.Errorf("MultiSigAddress with %d of 3 signatures succeeded", required)
+		}
+	}
+	if _, err := MultiSigAddress(Mainnet, 1, [][]byte{publicKeys[0][:32]}); err == nil {
+		t.Error("MultiSigAddress with a truncated public key succeeded")
+	}
+}
+
+// The vectors sign the same transfer in each multisig hash mode, the
+// sequential modes of SIP-005 and the non-sequential modes of SIP-027.
+func TestMultiSigVectors(t *testing.T) {
+	for _, tt := range []struct {
+		hashMode HashMode
+		signers  []int
+		sigHash  string
+		hex      string
+		txid     string
+	}{
+		{
+			hashMode: HashModeP2SH,
+			signers:  []int{0, 1},
+			sigHash:  "35828ff186088a98f2ce293fccf5be588c0e1c9cb2a624cec0376c2ed0e17d29",
+			hex:      "00000000010401a23ea89d6529ac48ac766f720e480beec7f192730000000000000007000000000000019000000003020163565a054a62398e5614cd31ff52161dbab02e7139417284e063af9cbe025e573b78398bdd1b10571104453a49cccbb2da1bfd04abf8c7b0d615d8c6ac2736170200279b5721b46cacf401bd6261ff500ac54b4fff4c95378e4e411a9c02d80e58ca33a90bb4d537442b7780793d17eeea36ca872d32ac1382351781134878f8e42b0003661ec7479330bf1ef7a4c9d1816f089666a112e72d671048e5424fc528ca51530002030200000000000516df0ba3e79792be7be5e50a370289accfc8c9e03200000000002625a06d756c74697369670000000000000000000000000000000000000000000000000000",
+			txid:     "40b8aed3cbd2cd4e7c5b0d750059245aa1de28585f32720d46cd22bbbae1bc3a",
+		},
+		{
+			hashMode: HashModeP2WSH,
+			signers:  []int{1, 2},
+			sigHash:  "963efcd965faf4d349c8339a7b1ebc33399aa93596fb808d26f53f1d1e08e8ce",
+			hex:      "00000000010403f5cfb61a07fb41a32197da01ce033888f0fe94a700000000000000070000000000000190000000030002db3ee269c096bb07fad458f7b1cfada089a72dba6404c2d1a516efd59f2699e502012c9b86ca571fa80819d53e9d00fffef8b74ec1f5cefc5e35e9c38691fa06c98825ba67722550f298d2ced4f9d6bd6516928fa3359044c0751f7e1c96028fd3680200442ae3adb6a38bc29701ea28bfc141069b47c2a806e33be366053a06453aa79b6d4c115339c1946bb8f925a3db8e932f2042f83a3a20a1073e90f646ae74f3e80002030200000000000516df0ba3e79792be7be5e50a370289accfc8c9e03200000000002625a06d756c74697369670000000000000000000000000000000000000000000000000000",
+			txid:     "d452c79274e2d3980f76822fee8cd9b519462827c85f1ad2bf320d3935346c7d",
+		},
+		{
+			hashMode: HashModeP2SHNonSequential,
+			signers:  []int{2, 0},
+			sigHash:  "2fc6af5fff2826651b2d11198c5cfe51170cf5e5356793efe9ed7d3550b22151",
+			hex:      "00000000010405a23ea89d6529ac48ac766f720e480beec7f1927300000000000000070000000000000190000000030201775870f88a42a5cfcc3bc96bdc3eeb165302eb8b1538a0bbf14d7a0abf964a0136aa2e82b23dbfdc48a532c30c76bcc86c57d46d1fed71e5e44001ad984753230002fcd53041c79bc2756e3aadc510a9b3c1f098b9465fc841d10aad4f75badb638b02010823fa16d15efde5e58a00919d3bfea73a4c294e40e74df559167cc9932a2c02051c9d836ec56a02c0fd1cfe650648af24db1f5f8a2d1d93c52004adac9277250002030200000000000516df0ba3e79792be7be5e50a370289accfc8c9e03200000000002625a06d756c74697369670000000000000000000000000000000000000000000000000000",
+			txid:     "20ffd767bd330ab4af6c92ca395b64ebb0e4c909dd7a4a14155694eb3e4e9b6f",
+		},
+		{
+			hashMode: HashModeP2WSHNonSequential,
+			signers:  []int{0, 2},
+			sigHash:  "e0e212d9ac906fd7c07bb79a7cee5f108678303f11f1f477bf9cfd8cdc03f4b4",
+			hex:      "00000000010407f5cfb61a07fb41a32197da01ce033888f0fe94a700000000000000070000000000000190000000030200b768909060cb67e3ac80790fd34e50d057d4a726c2bb1866236e74e22ead351b0cbd4b19eccd932db7d612d42fd8f11a887fc045d69f0e3ac7f1bc2247a04a520002fcd53041c79bc2756e3aadc510a9b3c1f098b9465fc841d10aad4f75badb638b02016741597897799108f04721cc3b1c8fda8dbc35ce55557d37f4cadd0b8ff5e12e2c9dce384c8c799bd60d031d1a0ec3727355adaeb0406b5fa65b8f0dfe33c2eb0002030200000000000516df0ba3e79792be7be5e50a370289accfc8c9e03200000000002625a06d756c74697369670000000000000000000000000000000000000000000000000000",
+			txid:     "2d3859cfe49deda293faa4fc0ef711bddd37a5e0d72ea8489dd483f74f8751d8",
+		},
+	} {
+		t.Run(tt.hashMode.String(), func(t *testing.T) {
+			tx := multiSigTransfer(t, tt.hashMode)
+			sigHash, err := tx.initialSigHash()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(sigHash); got != tt.sigHash {
+				t.Errorf("initial sighash = %s, want %s", got, tt.sigHash)
+			}
+
+			// Each signature goes through the wire format before the next
+			// key signs, as when the transaction is passed between signers.
+			for _, i := range tt.signers {
+				if err := tx.VerifyOrigin(); err == nil {
+					t.Errorf("transaction verifies with %d of 2 signatures", tx.Auth.Origin.SignatureCount())
+				}
+				signMultiSig(t, tx, i)
+				partial, err := tx.Hex()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if tx, err = DecodeHex(partial); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := tx.Hex()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.hex {
+				t.Errorf("Hex = %s, want %s", got, tt.hex)
+			}
+			txid, err := tx.TxID()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if txid != tt.txid {
+				t.Errorf("TxID = %s, want %s", txid, tt.txid)
+			}
+			if err := tx.VerifyOrigin(); err != nil {
+				t.Errorf("VerifyOrigin: %v", err)
+			}
+		})
+	}
+}
+
+func TestCombineMultiSig(t *testing.T) {
+	// Non-sequential keys sign copies independently.
+	first := multiSigTransfer(t, HashModeP2SHNonSequential)
+	signMultiSig(t, first, 2)
+	second := multiSigTransfer(t, HashModeP2SHNonSequential)
+	signMultiSig(t, second, 0)
+	combined, err := CombineMultiSig(first, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := combined.TxID(); got != "20ffd767bd330ab4af6c92ca395b64ebb0e4c909dd7a4a14155694eb3e4e9b6f" {
+		t.Errorf("combined TxID = %s", got)
+	}
+	if err := combined.VerifyOrigin(); err != nil {
+		t.Errorf("VerifyOrigin of the combined transaction: %v", err)
+	}
+	if first.Auth.Origin.SignatureCount() != 1 {
+		t.Error("CombineMultiSig modified its first transaction")
+	}
+
+	// Combining a copy with itself adds nothing, combining a third signature
+	// exceeds the required count.
+	if again, err := CombineMultiSig(combined, first); err != nil || again.Auth.Origin.SignatureCount() != 2 {
+		t.Errorf("CombineMultiSig with a signature already present = %v", err)
+	}
+	third := multiSigTransfer(t, HashModeP2SHNonSequential)
+	signMultiSig(t, third, 1)
+	if _, err := CombineMultiSig(combined, third); err == nil {
+		t.Error("CombineMultiSig into 3 of 2 signatures succeeded")
+	}
+
+	other := multiSigTransfer(t, HashModeP2SHNonSequential)
+	other.Auth.Origin.Fee++
+	signMultiSig(t, other, 0)
+	if _, err := CombineMultiSig(first, other); err == nil {
+		t.Error("CombineMultiSig of transactions with different fees succeeded")
+	}
+	if _, err := CombineMultiSig(transfer(t, Mainnet, "SP3FGQ8Z7JY9BWYZ5WM53E0M9NK7WHJF0691NZ159", 1, 0, 0, "")); err == nil {
+		t.Error("CombineMultiSig of a single-sig transaction succeeded")
+	}
+
+	// A sequential key that signs a copy before the keys listed above it
+	// commits to the wrong hash, the combination does not verify.
+	first = multiSigTransfer(t, HashModeP2SH)
+	signMultiSig(t, first, 0)
+	second = multiSigTransfer(t, HashModeP2SH)
+	signMultiSig(t, second, 1)
+	combined, err = CombineMultiSig(first, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := combined.VerifyOrigin(); err == nil {
+		t.Error("sequential signatures made independently verify")
+	}
+}
+
+func TestSignMultiSigRejects(t *testing.T) {
+	tx := multiSigTransfer(t, HashModeP2SH)
+	signMultiSig(t, tx, 1)
+	if err := tx.SignMultiSig(mustKey(t, multiSigKeys[0])); err == nil {
+		t.Error("sequential key 1 signed after key 2")
+	}
+
+	tx = multiSigTransfer(t, HashModeP2SHNonSequential)
+	if err := tx.SignMultiSig(mustKey(t, testKey)); err == nil {
+		t.Error("a key outside the multisig signed")
+	}
+	signMultiSig(t, tx, 0)
+	if err := tx.SignMultiSig(mustKey(t, multiSigKeys[0])); err == nil {
+		t.Error("a key signed twice")
+	}
+	signMultiSig(t, tx, 1)
+	if err := tx.SignMultiSig(mustKey(t, multiSigKeys[2])); err == nil {
+		t.Error("a third key signed a 2 of 3 multisig")
+	}
+
+	tx.Auth.Origin.Nonce++
+	if err := tx.VerifyOrigin(); err == nil {
+		t.Error("changing the nonce after signing still verifies")
+	}
+	tx.Auth.Origin.Nonce--
+	tx.Auth.Origin.SignaturesRequired = 1
+	if err := tx.VerifyOrigin(); err == nil {
+		t.Error("lowering the required signatures still verifies")
+	}
+
+	single := transfer(t, Mainnet, "SP3FGQ8Z7JY9BWYZ5WM53E0M9NK7WHJF0691NZ159", 1, 0, 0, "")
+	if err := single.SignMultiSig(mustKey(t, testKey)); err == nil {
+		t.Error("SignMultiSig of a single-sig transaction succeeded")
+	}
+}
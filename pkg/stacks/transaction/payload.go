@@ -0,0 +1,169 @@
+package transaction
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/hashhavoc/teller/pkg/clarity"
+)
+
+type PayloadType byte
+
+const (
+	PayloadTokenTransfer          PayloadType = 0x00
+	PayloadSmartContract          PayloadType = 0x01
+	PayloadContractCall           PayloadType = 0x02
+	PayloadVersionedSmartContract PayloadType = 0x06
+)
+
+func (p PayloadType) String() string {
+	switch p {
+	case PayloadTokenTransfer:
+		return "token_transfer"
+	case PayloadSmartContract:
+		return "smart_contract"
+	case PayloadContractCall:
+		return "contract_call"
+	case PayloadVersionedSmartContract:
+		return "versioned_smart_contract"
+	default:
+		return fmt.Sprintf("unknown(0x%02x)", byte(p))
+	}
+}
+
+// Payload is the action a transaction performs.
+type Payload interface {
+	PayloadType() PayloadType
+	encode(buf *bytes.Buffer) error
+}
+
+// MemoLength is the fixed size of a token transfer memo.
+const MemoLength = 34
+
+// TokenTransferPayload sends micro-STX to a principal.
+type TokenTransferPayload struct {
+	// Recipient is a clarity.StandardPrincipal or clarity.ContractPrincipal.
+	Recipient clarity.Value
+	Amount    uint64
+	Memo      [MemoLength]byte
+}
+
+// NewTokenTransfer returns a transfer of amount micro-STX to recipient.
+func NewTokenTransfer(recipient string, amount uint64, memo string) (*TokenTransferPayload, error) {
+	principal, err := clarity.NewPrincipal(recipient)
+	if err != nil {
+		return nil, err
+	}
+	if len(memo) > MemoLength {
+		return nil, fmt.Errorf("memo is %d bytes, the maximum is %d", len(memo), MemoLength)
+	}
+	p := &TokenTransferPayload{Recipient: principal, Amount: amount}
+	copy(p.Memo[:], memo)
+	return p, nil
+}
+
+func (p *TokenTransferPayload) PayloadType() PayloadType {
+	return PayloadTokenTransfer
+}
+
+// MemoString returns the memo with its zero padding removed.
+func (p *TokenTransferPayload) MemoString() string {
+	return string(bytes.TrimRight(p.Memo[:], "\x00"))
+}
+
+func (p *TokenTransferPayload) encode(buf *bytes.Buffer) error {
+	switch p.Recipient.(type) {
+	case clarity.StandardPrincipal, clarity.ContractPrincipal:
+	default:
+		return fmt.Errorf("token transfer recipient must be a principal, got %s", p.Recipient.Type())
+	}
+	recipient, err := clarity.Encode(p.Recipient)
+	if err != nil {
+		return err
+	}
+	buf.Write(recipient)
+	binary.Write(buf, binary.BigEndian, p.Amount)
+	buf.Write(p.Memo[:])
+	return nil
+}
+
+// ContractCallPayload calls a public function of a contract.
+type ContractCallPayload struct {
+	Contract clarity.ContractPrincipal
+	Function string
+	Args     []clarity.Value
+}
+
+// NewContractCall returns a call of function on the contract with args.
+func NewContractCall(contractID, function string, args []clarity.Value) (*ContractCallPayload, error) {
+	contract, err := clarity.NewContractPrincipal(contractID)
+	if err != nil {
+		return nil, err
+	}
+	return &ContractCallPayload{Contract: contract, Function: function, Args: args}, nil
+}
+
+func (p *ContractCallPayload) PayloadType() PayloadType {
+	return PayloadContractCall
+}
+
+func (p *ContractCallPayload) encode(buf *bytes.Buffer) error {
+	buf.WriteByte(p.Contract.Address.Version)
+	buf.Write(p.Contract.Address.Hash160[:])
+	if err := writeName(buf, p.Contract.Name); err != nil {
+		return err
+	}
+	if err := writeName(buf, p.Function); err != nil {
+		return err
+	}
+	binary.Write(buf, binary.BigEndian, uint32(len(p.Args)))
+	for _, arg := range p.Args {
+		b, err := clarity.Encode(arg)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	}
+	return nil
+}
+
+// SmartContractPayload deploys a contract. A ClarityVersion of zero
+// produces a smart-contract payload, which the node runs with the default
+// Clarity version of the current epoch, anything else a
+// versioned-smart-contract payload.
+type SmartContractPayload struct {
+	ClarityVersion byte
+	Name           string
+	Code           string
+}
+
+// NewSmartContract returns a deployment of code as a contract called name.
+func NewSmartContract(name, code string, clarityVersion byte) (*SmartContractPayload, error) {
+	if name == "" || len(name) > 128 {
+		return nil, fmt.Errorf("invalid contract name %q", name)
+	}
+	if code == "" {
+		return nil, fmt.Errorf("contract %s has no code", name)
+	}
+	return &SmartContractPayload{ClarityVersion: clarityVersion, Name: name, Code: code}, nil
+}
+
+func (p *SmartContractPayload) PayloadType() PayloadType {
+	if p.ClarityVersion == 0 {
+		return PayloadSmartContract
+	}
+	return PayloadVersionedSmartContract
+}
+
+func (p *SmartContractPayload) encode(buf *bytes.Buffer) error {
+	if p.ClarityVersion != 0 {
+		buf.WriteByte(p.ClarityVersion)
+	}
+	if err := writeName(buf, p.Name); err != nil {
+		return err
+	}
+	binary.Write(buf, binary.BigEndian, uint32(len(p.Code)))
+	buf.WriteString(p.Code)
+	return nil
+}
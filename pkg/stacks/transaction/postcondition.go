@@ -0,0 +1,352 @@
+package transaction
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hashhavoc/teller/pkg/clarity"
+)
+
+type assetInfoType byte
+
+const (
+	assetInfoSTX         assetInfoType = 0x00
+	assetInfoFungible    assetInfoType = 0x01
+	assetInfoNonFungible assetInfoType = 0x02
+)
+
+type postConditionPrincipalType byte
+
+const (
+	principalOrigin   postConditionPrincipalType = 0x01
+	principalStandard postConditionPrincipalType = 0x02
+	principalContract postConditionPrincipalType = 0x03
+)
+
+// FungibleConditionCode compares the amount of STX or fungible tokens sent
+// by a principal against a post condition amount.
+type FungibleConditionCode byte
+
+const (
+	SentEqual          FungibleConditionCode = 0x01
+	SentGreater        FungibleConditionCode = 0x02
+	SentGreaterOrEqual FungibleConditionCode = 0x03
+	SentLess           FungibleConditionCode = 0x04
+	SentLessOrEqual    FungibleConditionCode = 0x05
+)
+
+var fungibleConditionCodes = map[string]FungibleConditionCode{
+	"eq":  SentEqual,
+	"gt":  SentGreater,
+	"gte": SentGreaterOrEqual,
+	"lt":  SentLess,
+	"lte": SentLessOrEqual,
+	"=":   SentEqual,
+	">":   SentGreater,
+	">=":  SentGreaterOrEqual,
+	"<":   SentLess,
+	"<=":  SentLessOrEqual,
+}
+
+func (c FungibleConditionCode) String() string {
+	switch c {
+	case SentEqual:
+		return "eq"
+	case SentGreater:
+		return "gt"
+	case SentGreaterOrEqual:
+		return "gte"
+	case SentLess:
+		return "lt"
+	case SentLessOrEqual:
+		return "lte"
+	default:
+		return fmt.Sprintf("unknown(0x%02x)", byte(c))
+	}
+}
+
+// NonFungibleConditionCode states whether a principal gives up an NFT.
+type NonFungibleConditionCode byte
+
+const (
+	Sent    NonFungibleConditionCode = 0x10
+	NotSent NonFungibleConditionCode = 0x11
+)
+
+func (c NonFungibleConditionCode) String() string {
+	switch c {
+	case Sent:
+		return "sent"
+	case NotSent:
+		return "not-sent"
+	default:
+		return fmt.Sprintf("unknown(0x%02x)", byte(c))
+	}
+}
+
+// PostConditionPrincipal is the principal whose assets a post condition
+// constrains. When Origin is set it refers to the transaction origin and
+// Principal is nil.
+type PostConditionPrincipal struct {
+	Origin bool
+	// Principal is a clarity.StandardPrincipal or clarity.ContractPrincipal.
+	Principal clarity.Value
+}
+
+// ParsePostConditionPrincipal parses "origin" or a standard or contract
+// principal.
+func ParsePostConditionPrincipal(s string) (PostConditionPrincipal, error) {
+	if s == "origin" {
+		return PostConditionPrincipal{Origin: true}, nil
+	}
+	principal, err := clarity.NewPrincipal(s)
+	if err != nil {
+		return PostConditionPrincipal{}, err
+	}
+	return PostConditionPrincipal{Principal: principal}, nil
+}
+
+func (p PostConditionPrincipal) String() string {
+	if p.Origin {
+		return "origin"
+	}
+	return clarity.Display(p.Principal)
+}
+
+func (p PostConditionPrincipal) encode(buf *bytes.Buffer) error {
+	switch principal := p.Principal.(type) {
+	case nil:
+		if !p.Origin {
+			return fmt.Errorf("post condition has no principal")
+		}
+		buf.WriteByte(byte(principalOrigin))
+	case clarity.StandardPrincipal:
+		buf.WriteByte(byte(principalStandard))
+		buf.WriteByte(principal.Version)
+		buf.Write(principal.Hash160[:])
+	case clarity.ContractPrincipal:
+		buf.WriteByte(byte(principalContract))
+		buf.WriteByte(principal.Address.Version)
+		buf.Write(principal.Address.Hash160[:])
+		if err := writeName(buf, principal.Name); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("post condition principal must be a principal, got %s", p.Principal.Type())
+	}
+	return nil
+}
+
+// AssetInfo identifies a fungible or non-fungible token defined by a
+// contract, written as ADDRESS.CONTRACT::ASSET.
+type AssetInfo struct {
+	Address      clarity.StandardPrincipal
+	ContractName string
+	AssetName    string
+}
+
+// ParseAssetInfo parses an asset identifier such as
+// SP3K8BC0PPEVCV7NZ6QSRWPQ2JE9E5B6N3PA0KBR9.alex-token::alex.
+func ParseAssetInfo(s string) (AssetInfo, error) {
+	contractID, assetName, ok := strings.Cut(s, "::")
+	if !ok || assetName == "" {
+		return AssetInfo{}, fmt.Errorf("invalid asset %q, expected ADDRESS.CONTRACT::ASSET", s)
+	}
+	contract, err := clarity.NewContractPrincipal(contractID)
+	if err != nil {
+		return AssetInfo{}, err
+	}
+	return AssetInfo{Address: contract.Address, ContractName: contract.Name, AssetName: assetName}, nil
+}
+
+func (a AssetInfo) String() string {
+	return fmt.Sprintf("%s.%s::%s", a.Address.Address(), a.ContractName, a.AssetName)
+}
+
+func (a AssetInfo) encode(buf *bytes.Buffer) error {
+	buf.WriteByte(a.Address.Version)
+	buf.Write(a.Address.Hash160[:])
+	if err := writeName(buf, a.ContractName); err != nil {
+		return err
+	}
+	return writeName(buf, a.AssetName)
+}
+
+// PostCondition is a check on asset movements that aborts the transaction
+// when it does not hold.
+type PostCondition interface {
+	String() string
+	encode(buf *bytes.Buffer) error
+}
+
+// STXPostCondition limits the micro-STX sent by a principal.
+type STXPostCondition struct {
+	Principal PostConditionPrincipal
+	Code      FungibleConditionCode
+	Amount    uint64
+}
+
+func (c *STXPostCondition) String() string {
+	return fmt.Sprintf("stx %s %s %d", c.Principal, c.Code, c.Amount)
+}
+
+func (c *STXPostCondition) encode(buf *bytes.Buffer) error {
+	buf.WriteByte(byte(assetInfoSTX))
+	if err := c.Principal.encode(buf); err != nil {
+		return err
+	}
+	buf.WriteByte(byte(c.Code))
+	return binary.Write(buf, binary.BigEndian, c.Amount)
+}
+
+// FungiblePostCondition limits the amount of a fungible token sent by a
+// principal.
+type FungiblePostCondition struct {
+	Principal PostConditionPrincipal
+	Asset     AssetInfo
+	Code      FungibleConditionCode
+	Amount    uint64
+}
+
+func (c *FungiblePostCondition) String() string {
+	return fmt.Sprintf("ft %s %s %d %s", c.Principal, c.Code, c.Amount, c.Asset)
+}
+
+func (c *FungiblePostCondition) encode(buf *bytes.Buffer) error {
+	buf.WriteByte(byte(assetInfoFungible))
+	if err := c.Principal.encode(buf); err != nil {
+		return err
+	}
+	if err := c.Asset.encode(buf); err != nil {
+		return err
+	}
+	buf.WriteByte(byte(c.Code))
+	return binary.Write(buf, binary.BigEndian, c.Amount)
+}
+
+// NonFungiblePostCondition states whether a principal sends a specific NFT.
+type NonFungiblePostCondition struct {
+	Principal PostConditionPrincipal
+	Asset     AssetInfo
+	Value     clarity.Value
+	Code      NonFungibleConditionCode
+}
+
+func (c *NonFungiblePostCondition) String() string {
+	return fmt.Sprintf("nft %s %s %s %s", c.Principal, c.Code, c.Asset, c.Value)
+}
+
+func (c *NonFungiblePostCondition) encode(buf *bytes.Buffer) error {
+	buf.WriteByte(byte(assetInfoNonFungible))
+	if err := c.Principal.encode(buf); err != nil {
+		return err
+	}
+	if err := c.Asset.encode(buf); err != nil {
+		return err
+	}
+	value, err := clarity.Encode(c.Value)
+	if err != nil {
+		return err
+	}
+	buf.Write(value)
+	buf.WriteByte(byte(c.Code))
+	return nil
+}
+
+// ParsePostCondition parses the space separated form printed by the
+// post conditions' String methods:
+//
+//	stx PRINCIPAL CODE AMOUNT
+//	ft  PRINCIPAL CODE AMOUNT ADDRESS.CONTRACT::ASSET
+//	nft PRINCIPAL sent|not-sent ADDRESS.CONTRACT::ASSET VALUE
+//
+// PRINCIPAL is "origin" or an address or contract ID, CODE is one of eq, gt,
+// gte, lt, lte or the equivalent =, >, >=, <, <=, and VALUE is a Clarity
+// literal such as u1.
+func ParsePostCondition(s string) (PostCondition, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("invalid post condition %q", s)
+	}
+	principal, err := ParsePostConditionPrincipal(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid post condition %q: %w", s, err)
+	}
+
+	switch fields[0] {
+	case "stx", "ft":
+		code, ok := fungibleConditionCodes[fields[2]]
+		if !ok {
+			return nil, fmt.Errorf("invalid post condition %q: unknown condition %q", s, fields[2])
+		}
+		amount, err := strconv.ParseUint(fields[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid post condition %q: invalid amount %q", s, fields[3])
+		}
+		if fields[0] == "stx" {
+			if len(fields) != 4 {
+				return nil, fmt.Errorf("invalid post condition %q: expected stx PRINCIPAL CODE AMOUNT", s)
+			}
+			return &STXPostCondition{Principal: principal, Code: code, Amount: amount}, nil
+		}
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("invalid post condition %q: expected ft PRINCIPAL CODE AMOUNT ASSET", s)
+		}
+		asset, err := ParseAssetInfo(fields[4])
+		if err != nil {
+			return nil, fmt.Errorf("invalid post condition %q: %w", s, err)
+		}
+		return &FungiblePostCondition{Principal: principal, Asset: asset, Code: code, Amount: amount}, nil
+	case "nft":
+		if len(fields) < 5 {
+			return nil, fmt.Errorf("invalid post condition %q: expected nft PRINCIPAL sent|not-sent ASSET VALUE", s)
+		}
+		var code NonFungibleConditionCode
+		switch fields[2] {
+		case "sent":
+			code = Sent
+		case "not-sent":
+			code = NotSent
+		default:
+			return nil, fmt.Errorf("invalid post condition %q: unknown condition %q", s, fields[2])
+		}
+		asset, err := ParseAssetInfo(fields[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid post condition %q: %w", s, err)
+		}
+		value, err := clarity.Parse(skipFields(s, 4))
+		if err != nil {
+			return nil, fmt.Errorf("invalid post condition %q: %w", s, err)
+		}
+		return &NonFungiblePostCondition{Principal: principal, Asset: asset, Value: value, Code: code}, nil
+	default:
+		return nil, fmt.Errorf("invalid post condition %q: expected stx, ft or nft", s)
+	}
+}
+
+// skipFields returns s after its first n whitespace separated fields, so
+// that spacing inside a trailing Clarity literal is kept.
+func skipFields(s string, n int) string {
+	for i := 0; i < n; i++ {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		if end := strings.IndexFunc(s, unicode.IsSpace); end >= 0 {
+			s = s[end:]
+		} else {
+			s = ""
+		}
+	}
+	return strings.TrimSpace(s)
+}
+
+func writeName(buf *bytes.Buffer, name string) error {
+	if len(name) > 128 {
+		return fmt.Errorf("name %q is longer than 128 characters", name)
+	}
+	buf.WriteByte(byte(len(name)))
+	buf.WriteString(name)
+	return nil
+}
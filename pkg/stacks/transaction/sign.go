@@ -0,0 +1,98 @@
+package transaction
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/hashhavoc/teller/pkg/stacks/keys"
+)
+
+// initialSigHash is the txid of the transaction with the signature, nonce
+// and fee of every spending condition cleared. Signers commit to the nonce
+// and fee separately in the presign hash.
+func (t *Transaction) initialSigHash() ([]byte, error) {
+	clone := *t
+	clone.Auth.Origin = clearSpendingCondition(t.Auth.Origin)
+	if t.Auth.Sponsor != nil {
+		// The sponsor is not known when the origin signs, so it is replaced
+		// by an empty P2PKH condition.
+		clone.Auth.Sponsor = &SpendingCondition{HashMode: HashModeP2PKH}
+	}
+	b, err := clone.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha512.Sum512_256(b)
+	return sum[:], nil
+}
+
+func clearSpendingCondition(sc SpendingCondition) SpendingCondition {
+	sc.Nonce = 0
+	sc.Fee = 0
+	sc.Signature = [65]byte{}
+	sc.Fields = nil
+	return sc
+}
+
+func presignSigHash(sigHash []byte, authType AuthType, fee, nonce uint64) []byte {
+	var buf bytes.Buffer
+	buf.Write(sigHash)
+	buf.WriteByte(byte(authType))
+	binary.Write(&buf, binary.BigEndian, fee)
+	binary.Write(&buf, binary.BigEndian, nonce)
+	sum := sha512.Sum512_256(buf.Bytes())
+	return sum[:]
+}
+
+// Sign signs the origin spending condition of a single signature
+// transaction with the key, which must match the origin signer.
+func (t *Transaction) Sign(key *keys.PrivateKey) error {
+	origin := &t.Auth.Origin
+	if !origin.HashMode.singleSig() {
+		return fmt.Errorf("cannot sign %s spending condition with a single key", origin.HashMode)
+	}
+	if !bytes.Equal(origin.Signer[:], keys.Hash160(key.PublicKey())) {
+		return errors.New("key does not match the transaction signer")
+	}
+
+	sigHash, err := t.initialSigHash()
+	if err != nil {
+		return err
+	}
+	// Sponsored transactions are signed by the origin as if they were
+	// standard, the sponsor signs afterwards.
+	presign := presignSigHash(sigHash, AuthStandard, origin.Fee, origin.Nonce)
+	copy(origin.Signature[:], key.SignRecoverable(presign))
+	return nil
+}
+
+// VerifyOrigin checks that the origin signatures were made by the origin
+// signer, or for multisig conditions by enough of its keys.
+func (t *Transaction) VerifyOrigin() error {
+	origin := t.Auth.Origin
+	if origin.HashMode.multiSig() {
+		return t.verifyMultiSig()
+	}
+	if !origin.HashMode.singleSig() {
+		return fmt.Errorf("cannot verify %s spending condition", origin.HashMode)
+	}
+	if origin.Signature == [65]byte{} {
+		return errors.New("transaction is not signed")
+	}
+	sigHash, err := t.initialSigHash()
+	if err != nil {
+		return err
+	}
+	presign := presignSigHash(sigHash, AuthStandard, origin.Fee, origin.Nonce)
+	publicKey, err := keys.RecoverPublicKey(presign, origin.Signature[:], origin.KeyEncoding == KeyEncodingCompressed)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(keys.Hash160(publicKey), origin.Signer[:]) {
+		return errors.New("signature does not match the transaction signer")
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+// Package transaction builds, signs and decodes Stacks transactions in the
+// SIP-005 wire format.
+package transaction
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/hashhavoc/teller/pkg/stacks/address"
+	"github.com/hashhavoc/teller/pkg/stacks/c32"
+	"github.com/hashhavoc/teller/pkg/stacks/keys"
+)
+
+// Network holds the version bytes that tie a transaction to a chain.
+type Network struct {
+	Name             string
+	Version          byte
+	ChainID          uint32
+	SingleSigVersion byte
+	MultiSigVersion  byte
+}
+
+var (
+	Mainnet = Network{Name: "mainnet", Version: 0x00, ChainID: 0x00000001, SingleSigVersion: address.MainnetSingleSig, MultiSigVersion: address.MainnetMultiSig}
+	Testnet = Network{Name: "testnet", Version: 0x80, ChainID: 0x80000000, SingleSigVersion: address.TestnetSingleSig, MultiSigVersion: address.TestnetMultiSig}
+)
+
+// NetworkByName returns the network for mainnet, testnet or devnet. Devnets
+// use the testnet version bytes.
+func NetworkByName(name string) (Network, error) {
+	switch name {
+	case "mainnet":
+		return Mainnet, nil
+	case "testnet", "devnet":
+		return Testnet, nil
+	default:
+		return Network{}, fmt.Errorf("invalid network %q, expected mainnet, testnet or devnet", name)
+	}
+}
+
+// Addresses returns the network of the addresses of n.
+func (n Network) Addresses() address.Network {
+	if n.Version == Testnet.Version {
+		return address.Testnet
+	}
+	return address.Mainnet
+}
+
+// NetworkByVersion returns the network using the transaction version byte.
+func NetworkByVersion(version byte) (Network, error) {
+	switch version {
+	case Mainnet.Version:
+		return Mainnet, nil
+	case Testnet.Version:
+		return Testnet, nil
+	default:
+		return Network{}, fmt.Errorf("unknown transaction version 0x%02x", version)
+	}
+}
+
+type AuthType byte
+
+const (
+	AuthStandard  AuthType = 0x04
+	AuthSponsored AuthType = 0x05
+)
+
+type HashMode byte
+
+// The non-sequential multisig hash modes (SIP-027) have every signer sign
+// the same hash, so signatures can be collected in any order.
+const (
+	HashModeP2PKH              HashMode = 0x00
+	HashModeP2SH               HashMode = 0x01
+	HashModeP2WPKH             HashMode = 0x02
+	HashModeP2WSH              HashMode = 0x03
+	HashModeP2SHNonSequential  HashMode = 0x05
+	HashModeP2WSHNonSequential HashMode = 0x07
+)
+
+func (h HashMode) String() string {
+	switch h {
+	case HashModeP2PKH:
+		return "p2pkh"
+	case HashModeP2SH:
+		return "p2sh"
+	case HashModeP2WPKH:
+		return "p2wpkh"
+	case HashModeP2WSH:
+		return "p2wsh"
+	case HashModeP2SHNonSequential:
+		return "p2sh_non_sequential"
+	case HashModeP2WSHNonSequential:
+		return "p2wsh_non_sequential"
+	default:
+		return fmt.Sprintf("unknown(0x%02x)", byte(h))
+	}
+}
+
+func (h HashMode) singleSig() bool {
+	return h == HashModeP2PKH || h == HashModeP2WPKH
+}
+
+func (h HashMode) multiSig() bool {
+	switch h {
+	case HashModeP2SH, HashModeP2WSH, HashModeP2SHNonSequential, HashModeP2WSHNonSequential:
+		return true
+	default:
+		return false
+	}
+}
+
+func (h HashMode) sequential() bool {
+	return h == HashModeP2SH || h == HashModeP2WSH
+}
+
+type KeyEncoding byte
+
+const (
+	KeyEncodingCompressed   KeyEncoding = 0x00
+	KeyEncodingUncompressed KeyEncoding = 0x01
+)
+
+type AnchorMode byte
+
+const (
+	AnchorModeOnChainOnly  AnchorMode = 0x01
+	AnchorModeOffChainOnly AnchorMode = 0x02
+	AnchorModeAny          AnchorMode = 0x03
+)
+
+func (a AnchorMode) String() string {
+	switch a {
+	case AnchorModeOnChainOnly:
+		return "on_chain_only"
+	case AnchorModeOffChainOnly:
+		return "off_chain_only"
+	case AnchorModeAny:
+		return "any"
+	default:
+		return fmt.Sprintf("unknown(0x%02x)", byte(a))
+	}
+}
+
+type PostConditionMode byte
+
+const (
+	PostConditionModeAllow PostConditionMode = 0x01
+	PostConditionModeDeny  PostConditionMode = 0x02
+)
+
+func (m PostConditionMode) String() string {
+	switch m {
+	case PostConditionModeAllow:
+		return "allow"
+	case PostConditionModeDeny:
+		return "deny"
+	default:
+		return fmt.Sprintf("unknown(0x%02x)", byte(m))
+	}
+}
+
+// ParsePostConditionMode parses "allow" or "deny".
+func ParsePostConditionMode(s string) (PostConditionMode, error) {
+	switch s {
+	case "allow":
+		return PostConditionModeAllow, nil
+	case "deny":
+		return PostConditionModeDeny, nil
+	default:
+		return 0, fmt.Errorf("invalid post condition mode %q, expected allow or deny", s)
+	}
+}
+
+// SpendingCondition identifies the account paying for a transaction and
+// carries its signature. Single signature conditions use KeyEncoding and
+// Signature, multisig conditions Fields and SignaturesRequired.
+type SpendingCondition struct {
+	HashMode    HashMode
+	Signer      [20]byte
+	Nonce       uint64
+	Fee         uint64
+	KeyEncoding KeyEncoding
+	Signature   [65]byte
+
+	Fields             []AuthField
+	SignaturesRequired uint16
+}
+
+// NewSingleSigSpendingCondition returns an unsigned P2PKH spending condition
+// for the public key.
+func NewSingleSigSpendingCondition(publicKey []byte, nonce, fee uint64) SpendingCondition {
+	sc := SpendingCondition{HashMode: HashModeP2PKH, Nonce: nonce, Fee: fee}
+	copy(sc.Signer[:], keys.Hash160(publicKey))
+	if len(publicKey) != 33 {
+		sc.KeyEncoding = KeyEncodingUncompressed
+	}
+	return sc
+}
+
+// Address returns the c32 address of the signer on the network.
+func (sc SpendingCondition) Address(network Network) string {
+	version := network.SingleSigVersion
+	if !sc.HashMode.singleSig() {
+		version = network.MultiSigVersion
+	}
+	address, _ := c32.Address(version, sc.Signer[:])
+	return address
+}
+
+// Authorization holds the origin and, for sponsored transactions, the
+// sponsor spending conditions.
+type Authorization struct {
+	Type    AuthType
+	Origin  SpendingCondition
+	Sponsor *SpendingCondition
+}
+
+// Transaction is a Stacks transaction.
+type Transaction struct {
+	Version           byte
+	ChainID           uint32
+	Auth              Authorization
+	AnchorMode        AnchorMode
+	PostConditionMode PostConditionMode
+	PostConditions    []PostCondition
+	Payload           Payload
+}
+
+// New returns an unsigned, standard authorization transaction on the network.
+func New(network Network, origin SpendingCondition, payload Payload) *Transaction {
+	return &Transaction{
+		Version:           network.Version,
+		ChainID:           network.ChainID,
+		Auth:              Authorization{Type: AuthStandard, Origin: origin},
+		AnchorMode:        AnchorModeAny,
+		PostConditionMode: PostConditionModeDeny,
+		Payload:           payload,
+	}
+}
+
+// Network returns the network the transaction version belongs to.
+func (t *Transaction) Network() (Network, error) {
+	return NetworkByVersion(t.Version)
+}
+
+// Serialize returns the wire encoding of the transaction.
+func (t *Transaction) Serialize() ([]byte, error) {
+	if t.Payload == nil {
+		return nil, errors.New("transaction has no payload")
+	}
+	var buf bytes.Buffer
+	buf.WriteByte(t.Version)
+	binary.Write(&buf, binary.BigEndian, t.ChainID)
+	buf.WriteByte(byte(t.Auth.Type))
+	writeSpendingCondition(&buf, t.Auth.Origin)
+	if t.Auth.Type == AuthSponsored {
+		if t.Auth.Sponsor == nil {
+			return nil, errors.New("sponsored transaction has no sponsor")
+		}
+		writeSpendingCondition(&buf, *t.Auth.Sponsor)
+	}
+	buf.WriteByte(byte(t.AnchorMode))
+	buf.WriteByte(byte(t.PostConditionMode))
+	binary.Write(&buf, binary.BigEndian, uint32(len(t.PostConditions)))
+	for _, pc := range t.PostConditions {
+		if err := pc.encode(&buf); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte(byte(t.Payload.PayloadType()))
+	if err := t.Payload.encode(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Hex returns the hex encoding of the serialized transaction.
+func (t *Transaction) Hex() (string, error) {
+	b, err := t.Serialize()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// TxID returns the transaction id, the SHA-512/256 hash of its encoding.
+func (t *Transaction) TxID() (string, error) {
+	b, err := t.Serialize()
+	if err != nil {
+		return "", err
+	}
+	sum := sha512.Sum512_256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func writeSpendingCondition(buf *bytes.Buffer, sc SpendingCondition) {
+	buf.WriteByte(byte(sc.HashMode))
+	buf.Write(sc.Signer[:])
+	binary.Write(buf, binary.BigEndian, sc.Nonce)
+	binary.Write(buf, binary.BigEndian, sc.Fee)
+	if sc.HashMode.multiSig() {
+		binary.Write(buf, binary.BigEndian, uint32(len(sc.Fields)))
+		for _, f := range sc.Fields {
+			f.encode(buf)
+		}
+		binary.Write(buf, binary.BigEndian, sc.SignaturesRequired)
+		return
+	}
+	buf.WriteByte(byte(sc.KeyEncoding))
+	buf.Write(sc.Signature[:])
+}
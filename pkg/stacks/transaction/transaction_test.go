@@ -0,0 +1,247 @@
+package transaction
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/hashhavoc/teller/pkg/clarity"
+	"github.com/hashhavoc/teller/pkg/stacks/keys"
+)
+
+// testKey is the sender key of the stacks.js transaction builder tests.
+const testKey = "edf9aee84d9b7abc145504dde6726c64f369d37ee34ded868fabd876c26570bc01"
+
+func mustKey(t *testing.T, s string) *keys.PrivateKey {
+	t.Helper()
+	key, err := keys.ParsePrivateKey(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func transfer(t *testing.T, network Network, recipient string, amount, nonce, fee uint64, memo string) *Transaction {
+	t.Helper()
+	key := mustKey(t, testKey)
+	payload, err := NewTokenTransfer(recipient, amount, memo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := New(network, NewSingleSigSpendingCondition(key.PublicKey(), nonce, fee), payload)
+	if err := tx.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func contractCall(t *testing.T) *Transaction {
+	t.Helper()
+	key := mustKey(t, testKey)
+	contract, err := clarity.NewPrincipal("SP3K8BC0PPEVCV7NZ6QSRWPQ2JE9E5B6N3PA0KBR9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipient, err := clarity.NewPrincipal("SP2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKNRV9EJ7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := NewContractCall("SP3K8BC0PPEVCV7NZ6QSRWPQ2JE9E5B6N3PA0KBR9.token-alex", "transfer",
+		[]clarity.Value{clarity.NewUInt(250000000), contract, recipient, clarity.None{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := New(Mainnet, NewSingleSigSpendingCondition(key.PublicKey(), 3, 2000), payload)
+	for _, s := range []string{
+		"stx origin lte 1000",
+		"ft SP3K8BC0PPEVCV7NZ6QSRWPQ2JE9E5B6N3PA0KBR9 eq 250000000 SP3K8BC0PPEVCV7NZ6QSRWPQ2JE9E5B6N3PA0KBR9.token-alex::alex",
+	} {
+		pc, err := ParsePostCondition(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx.PostConditions = append(tx.PostConditions, pc)
+	}
+	if err := tx.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+// The mainnet transfer is the "STX token transfer with set tx fee" vector
+// of stacks.js. The others follow SIP-005 field by field.
+func TestSingleSigVectors(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		tx      func(t *testing.T) *Transaction
+		sigHash string
+		presign string
+		hex     string
+		txid    string
+	}{
+		{
+			name: "mainnet transfer",
+			tx: func(t *testing.T) *Transaction {
+				return transfer(t, Mainnet, "SP3FGQ8Z7JY9BWYZ5WM53E0M9NK7WHJF0691NZ159", 12345, 0, 0, "test memo")
+			},
+			sigHash: "95eb01360860afa4c818768cd11b6eff45a8009a9016d255705488c60a828b97",
+			presign: "9aef893c106ea08489676c708797909f7319643bf724cec4ae3c0cd336750253",
+			hex:     "0000000001040015c31b8c1c11c515e244b75806bac48d1399c7750000000000000000000000000000000000008b316d56e35b3b8d03ab3b9dbe05eb44d64c53e7ba3c468f9a78c82a13f2174c32facb0f29faeb21075ec933db935ebc28a8793cc60e14b8ee4ef05f52c94016030200000000000516df0ba3e79792be7be5e50a370289accfc8c9e032000000000000303974657374206d656d6f00000000000000000000000000000000000000000000000000",
+			txid:    "84cccb05f4bd0e1b08905ef1f1350ad635a6474448310548bdccfa04e0121bab",
+		},
+		{
+			name: "testnet transfer",
+			tx: func(t *testing.T) *Transaction {
+				return transfer(t, Testnet, "ST2J6ZY48GV1EZ5V2V5RB9MP66SW86PYKKQYAC0RQ", 1000000, 5, 180, "")
+			},
+			sigHash: "cbdb180bf213e20ff0748a70cebc6cd69f7a178e6bf3495b02a3e818b6c91734",
+			presign: "a64c12124a3d338fc26d8668ac792cfb59580c49513a9b53f19b20216320e9ee",
+			hex:     "8080000000040015c31b8c1c11c515e244b75806bac48d1399c775000000000000000500000000000000b400003f2ff858615fe51cea656e5861c1ae876c0e15b4222a20a0dc750e3b9f6834961ea5d1ce33747e90922c811202bad41a894bea1539273412a66c6b738341aaf503020000000000051aa46ff88886c2ef9762d970b4d2c63678835bd39d00000000000f424000000000000000000000000000000000000000000000000000000000000000000000",
+			txid:    "345ce2a4a4f06337bd4be8f95a09b66d80906d1ba3599e27aa13d8c121428372",
+		},
+		{
+			name:    "contract call with post conditions",
+			tx:      contractCall,
+			sigHash: "482eeb020514b1682472578747f77c95ba367e2571a95158949629b9ecfc67df",
+			presign: "df47f06e5b67638f41c8a6c37114dd1661d233c61e265333dffc0d2bdea834b1",
+			hex:     "0000000001040015c31b8c1c11c515e244b75806bac48d1399c775000000000000000300000000000007d000002e035d74d993a2f441e4294bda23882f0057a1d41d7f4acaff02761dc8730d9905ad5d3424edbd29cbfb32ca0fdf8a98e0d85e0afa34d6c09c5eb212f2f2eef303020000000200010500000000000003e8010216e685b016b3b6cd9ebf35f38e5ae29392e2acd51d16e685b016b3b6cd9ebf35f38e5ae29392e2acd51d0a746f6b656e2d616c657804616c657801000000000ee6b2800216e685b016b3b6cd9ebf35f38e5ae29392e2acd51d0a746f6b656e2d616c6578087472616e7366657200000004010000000000000000000000000ee6b2800516e685b016b3b6cd9ebf35f38e5ae29392e2acd51d0516a46ff88886c2ef9762d970b4d2c63678835bd39d09",
+			txid:    "9579044da50dd9c8da976d949ee5f2ec98a86f0b362a9fb7248d294637278fd2",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := tt.tx(t)
+
+			sigHash, err := tx.initialSigHash()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(sigHash); got != tt.sigHash {
+				t.Errorf("initial sighash = %s, want %s", got, tt.sigHash)
+			}
+			presign := presignSigHash(sigHash, AuthStandard, tx.Auth.Origin.Fee, tx.Auth.Origin.Nonce)
+			if got := hex.EncodeToString(presign); got != tt.presign {
+				t.Errorf("presign sighash = %s, want %s", got, tt.presign)
+			}
+
+			got, err := tx.Hex()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.hex {
+				t.Errorf("Hex = %s, want %s", got, tt.hex)
+			}
+			txid, err := tx.TxID()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if txid != tt.txid {
+				t.Errorf("TxID = %s, want %s", txid, tt.txid)
+			}
+			if err := tx.VerifyOrigin(); err != nil {
+				t.Errorf("VerifyOrigin: %v", err)
+			}
+
+			decoded, err := DecodeHex(tt.hex)
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, err := decoded.Hex()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again != tt.hex {
+				t.Errorf("decoded transaction encodes to %s", again)
+			}
+			if err := decoded.VerifyOrigin(); err != nil {
+				t.Errorf("VerifyOrigin of the decoded transaction: %v", err)
+			}
+		})
+	}
+}
+
+func TestSignatureVRS(t *testing.T) {
+	key := mustKey(t, testKey)
+	digest := mustHex(t, "9aef893c106ea08489676c708797909f7319643bf724cec4ae3c0cd336750253")
+	sig := key.SignRecoverable(digest)
+	want := "008b316d56e35b3b8d03ab3b9dbe05eb44d64c53e7ba3c468f9a78c82a13f2174c32facb0f29faeb21075ec933db935ebc28a8793cc60e14b8ee4ef05f52c94016"
+	if got := hex.EncodeToString(sig); got != want {
+		t.Fatalf("SignRecoverable = %s, want %s", got, want)
+	}
+	if sig[0] > 3 {
+		t.Errorf("recovery id %d is not in the VRS layout", sig[0])
+	}
+	publicKey, err := keys.RecoverPublicKey(digest, sig, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hex.EncodeToString(publicKey), hex.EncodeToString(key.PublicKey()); got != want {
+		t.Errorf("recovered %s, want %s", got, want)
+	}
+}
+
+func TestVerifyOriginRejects(t *testing.T) {
+	signed := func() *Transaction {
+		return transfer(t, Mainnet, "SP3FGQ8Z7JY9BWYZ5WM53E0M9NK7WHJF0691NZ159", 12345, 0, 0, "test memo")
+	}
+
+	tx := signed()
+	tx.Auth.Origin.Fee++
+	if err := tx.VerifyOrigin(); err == nil {
+		t.Error("changing the fee after signing still verifies")
+	}
+
+	tx = signed()
+	tx.Payload.(*TokenTransferPayload).Amount++
+	if err := tx.VerifyOrigin(); err == nil {
+		t.Error("changing the amount after signing still verifies")
+	}
+
+	tx = signed()
+	tx.Auth.Origin.Signature = [65]byte{}
+	if err := tx.VerifyOrigin(); err == nil {
+		t.Error("unsigned transaction verifies")
+	}
+
+	tx = signed()
+	other := mustKey(t, "6d430bb91222408e7706c9001cfaeb91b08c2be6d5ac95779ab52c6b431950e001")
+	if err := tx.Sign(other); err == nil {
+		t.Error("signing with a key other than the signer succeeded")
+	}
+}
+
+func TestSponsoredSigHash(t *testing.T) {
+	tx := transfer(t, Mainnet, "SP3FGQ8Z7JY9BWYZ5WM53E0M9NK7WHJF0691NZ159", 12345, 0, 0, "test memo")
+	standard, err := tx.initialSigHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The origin signs a sponsored transaction before the sponsor is set,
+	// so the sponsor does not change the hash it signs, only the auth type.
+	tx.Auth.Type = AuthSponsored
+	sponsor := NewSingleSigSpendingCondition(mustKey(t, "6d430bb91222408e7706c9001cfaeb91b08c2be6d5ac95779ab52c6b431950e001").PublicKey(), 9, 500)
+	tx.Auth.Sponsor = &sponsor
+	first, err := tx.initialSigHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sponsor.Nonce, sponsor.Fee = 10, 800
+	second, err := tx.initialSigHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(first) != hex.EncodeToString(second) {
+		t.Error("sponsor nonce and fee change the origin sighash")
+	}
+	if hex.EncodeToString(first) == hex.EncodeToString(standard) {
+		t.Error("sponsored and standard transactions have the same sighash")
+	}
+}
@@ -0,0 +1,497 @@
+// Package txstore keeps synced Stacks transactions in a bbolt database.
+//
+// Transactions are stored once by txid and indexed by the principals they
+// were synced for, block height, contract and event type. Index keys sort
+// by block height, then position in the block, so every index lists
+// transactions in chain order. Only canonical transactions are indexed: a
+// transaction re-orged out of the chain stays readable by txid but drops out
+// of the indexes until it is mined again.
+package txstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashhavoc/teller/pkg/api/hiro"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	txsBucket        = []byte("txs")
+	principalsBucket = []byte("principals")
+	heightsBucket    = []byte("heights")
+	contractsBucket  = []byte("contracts")
+	eventsBucket     = []byte("events")
+	syncBucket       = []byte("sync")
+)
+
+// Event types indexed from the event counts of a transaction. Events listed
+// on the transaction itself are indexed by their own type, such as
+// fungible_token_asset or smart_contract_log.
+const (
+	EventSTXTransfer = "stx_transfer"
+	EventSTXMint     = "stx_mint"
+	EventSTXBurn     = "stx_burn"
+	EventFTTransfer  = "ft_transfer"
+	EventFTMint      = "ft_mint"
+	EventFTBurn      = "ft_burn"
+	EventNFTTransfer = "nft_transfer"
+	EventNFTMint     = "nft_mint"
+	EventNFTBurn     = "nft_burn"
+)
+
+// Store is a transaction database. It is safe for concurrent use, but only
+// one process can open it at a time.
+type Store struct {
+	db *bolt.DB
+}
+
+// record is a transaction as stored by txid, with what it was listed with
+// for each principal and the event types it is indexed by.
+type record struct {
+	Tx         hiro.Tx          `json:"tx"`
+	Principals map[string]entry `json:"principals,omitempty"`
+	EventTypes []string         `json:"event_types,omitempty"`
+}
+
+// entry is the amounts and event counts of a transaction relative to a
+// principal.
+type entry struct {
+	StxSent     string      `json:"stx_sent,omitempty"`
+	StxReceived string      `json:"stx_received,omitempty"`
+	Events      hiro.Events `json:"events,omitempty"`
+}
+
+// SyncState is the progress of syncing a principal.
+type SyncState struct {
+	// Complete is set once a sync has reached the first transaction of the
+	// principal. Until then syncs page through the whole history.
+	Complete bool      `json:"complete"`
+	Synced   time.Time `json:"synced"`
+}
+
+// Open opens the database at path, creating it and its directory if needed.
+// It waits up to timeout for another process to close it.
+func Open(path string, timeout time.Duration) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: timeout})
+	if errors.Is(err, bolt.ErrTimeout) {
+		return nil, fmt.Errorf("transaction store %s is in use by another process", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(btx *bolt.Tx) error {
+		for _, name := range [][]byte{txsBucket, principalsBucket, heightsBucket, contractsBucket, eventsBucket, syncBucket} {
+			if _, err := btx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Result is the outcome of Put.
+type Result struct {
+	// Added counts the transactions that were not stored for the principal
+	// yet, Updated those whose block, status or canonical flag changed.
+	Added   int
+	Updated int
+	// Known are the txids of transactions that were stored unchanged.
+	Known []string
+}
+
+// Put stores the transactions of principal, as listed by its transactions
+// endpoint, and updates the indexes of those that changed.
+func (s *Store) Put(principal string, txs []hiro.Transaction) (Result, error) {
+	var result Result
+	err := s.db.Update(func(btx *bolt.Tx) error {
+		for _, tx := range txs {
+			old, found, err := getRecord(btx, tx.Tx.TxID)
+			if err != nil {
+				return err
+			}
+			_, listed := old.Principals[principal]
+			switch {
+			case !found || !listed:
+				result.Added++
+			case changed(old.Tx, tx.Tx):
+				result.Updated++
+			default:
+				result.Known = append(result.Known, tx.Tx.TxID)
+			}
+			rec := record{Tx: tx.Tx, Principals: make(map[string]entry), EventTypes: old.EventTypes}
+			for p, e := range old.Principals {
+				rec.Principals[p] = e
+			}
+			rec.Principals[principal] = entry{StxSent: tx.StxSent, StxReceived: tx.StxReceived, Events: tx.Events}
+			for _, event := range eventTypes(tx) {
+				if !contains(rec.EventTypes, event) {
+					rec.EventTypes = append(rec.EventTypes, event)
+				}
+			}
+			if err := replace(btx, old, found, rec); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+// Update replaces a stored transaction by a newer copy of it, such as one
+// fetched by txid to check whether it is still canonical. It reports
+// whether the transaction changed.
+func (s *Store) Update(tx hiro.Tx) (bool, error) {
+	var updated bool
+	err := s.db.Update(func(btx *bolt.Tx) error {
+		old, found, err := getRecord(btx, tx.TxID)
+		if err != nil || !found {
+			return err
+		}
+		if !changed(old.Tx, tx) {
+			return nil
+		}
+		updated = true
+		rec := record{Tx: tx, Principals: old.Principals, EventTypes: old.EventTypes}
+		for _, event := range eventTypes(hiro.Transaction{Tx: tx}) {
+			if !contains(rec.EventTypes, event) {
+				rec.EventTypes = append(rec.EventTypes, event)
+			}
+		}
+		return replace(btx, old, true, rec)
+	})
+	return updated, err
+}
+
+// changed reports whether b moved to another block, changed status or
+// canonical flag since a.
+func changed(a, b hiro.Tx) bool {
+	return a.Canonical != b.Canonical || a.BlockHash != b.BlockHash ||
+		a.BlockHeight != b.BlockHeight || a.TxIndex != b.TxIndex || a.TxStatus != b.TxStatus
+}
+
+// Get returns a stored transaction by txid, canonical or not.
+func (s *Store) Get(txid string) (hiro.Tx, bool, error) {
+	var rec record
+	var found bool
+	err := s.db.View(func(btx *bolt.Tx) error {
+		var err error
+		rec, found, err = getRecord(btx, txid)
+		return err
+	})
+	return rec.Tx, found, err
+}
+
+// Principal returns the canonical transactions of principal, newest first.
+func (s *Store) Principal(principal string) ([]hiro.Transaction, error) {
+	return s.Find(Query{Principal: principal})
+}
+
+// Principals returns the synced principals and their sync progress.
+func (s *Store) Principals() (map[string]SyncState, error) {
+	principals := make(map[string]SyncState)
+	err := s.db.View(func(btx *bolt.Tx) error {
+		return btx.Bucket(syncBucket).ForEach(func(k, v []byte) error {
+			var state SyncState
+			if err := json.Unmarshal(v, &state); err != nil {
+				return err
+			}
+			principals[string(k)] = state
+			return nil
+		})
+	})
+	return principals, err
+}
+
+// Query selects canonical transactions by the indexes. Empty fields match
+// any transaction, the others all have to match.
+type Query struct {
+	Principal string
+	Contract  string
+	EventType string
+	// FromHeight and ToHeight bound the block height, zero leaves the bound
+	// open.
+	FromHeight int
+	ToHeight   int
+}
+
+// Find returns the transactions matching q, newest first. With a
+// Principal, they have the amounts and event counts relative to it.
+func (s *Store) Find(q Query) ([]hiro.Transaction, error) {
+	var txs []hiro.Transaction
+	err := s.db.View(func(btx *bolt.Tx) error {
+		var b *bolt.Bucket
+		filters := map[*bolt.Bucket]bool{}
+		for _, sel := range []struct {
+			parent []byte
+			name   string
+		}{
+			{principalsBucket, q.Principal},
+			{contractsBucket, q.Contract},
+			{eventsBucket, q.EventType},
+		} {
+			if sel.name == "" {
+				continue
+			}
+			index := btx.Bucket(sel.parent).Bucket([]byte(sel.name))
+			if index == nil {
+				return nil
+			}
+			// Walk the first index and look the keys up in the others.
+			if b == nil {
+				b = index
+			} else {
+				filters[index] = true
+			}
+		}
+		if b == nil {
+			b = btx.Bucket(heightsBucket)
+		}
+
+		c := b.Cursor()
+		var k []byte
+		if q.ToHeight > 0 {
+			k, _ = c.Seek(heightPrefix(q.ToHeight + 1))
+			if k == nil {
+				k, _ = c.Last()
+			} else {
+				k, _ = c.Prev()
+			}
+		} else {
+			k, _ = c.Last()
+		}
+		for ; k != nil; k, _ = c.Prev() {
+			if q.FromHeight > 0 && bytes.Compare(k, heightPrefix(q.FromHeight)) < 0 {
+				break
+			}
+			match := true
+			for filter := range filters {
+				if filter.Get(k) == nil {
+					match = false
+					break
+				}
+			}
+			if !match {
+				continue
+			}
+			rec, found, err := getRecord(btx, txidOf(k))
+			if err != nil {
+				return err
+			}
+			if !found {
+				continue
+			}
+			e := rec.Principals[q.Principal]
+			txs = append(txs, hiro.Transaction{Tx: rec.Tx, StxSent: e.StxSent, StxReceived: e.StxReceived, Events: e.Events})
+		}
+		return nil
+	})
+	return txs, err
+}
+
+// SyncState returns the sync progress of principal.
+func (s *Store) SyncState(principal string) (SyncState, error) {
+	var state SyncState
+	err := s.db.View(func(btx *bolt.Tx) error {
+		v := btx.Bucket(syncBucket).Get([]byte(principal))
+		if v == nil {
+			return nil
+		}
+		return json.Unmarshal(v, &state)
+	})
+	return state, err
+}
+
+// SetSyncState records the sync progress of principal.
+func (s *Store) SetSyncState(principal string, state SyncState) error {
+	v, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(btx *bolt.Tx) error {
+		return btx.Bucket(syncBucket).Put([]byte(principal), v)
+	})
+}
+
+func getRecord(btx *bolt.Tx, txid string) (record, bool, error) {
+	var rec record
+	v := btx.Bucket(txsBucket).Get([]byte(txid))
+	if v == nil {
+		return rec, false, nil
+	}
+	if err := json.Unmarshal(v, &rec); err != nil {
+		return rec, false, fmt.Errorf("corrupt transaction %s: %w", txid, err)
+	}
+	return rec, true, nil
+}
+
+// replace replaces old, if found, by rec in the store and the indexes.
+func replace(btx *bolt.Tx, old record, found bool, rec record) error {
+	if found {
+		if err := unindex(btx, old); err != nil {
+			return err
+		}
+	}
+	v, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if err := btx.Bucket(txsBucket).Put([]byte(rec.Tx.TxID), v); err != nil {
+		return err
+	}
+	return index(btx, rec)
+}
+
+// index adds rec to the indexes, unless it is not canonical.
+func index(btx *bolt.Tx, rec record) error {
+	if !rec.Tx.Canonical {
+		return nil
+	}
+	key := sortKey(rec.Tx)
+	if err := btx.Bucket(heightsBucket).Put(key, nil); err != nil {
+		return err
+	}
+	for _, spec := range indexes(rec) {
+		for _, name := range spec.names {
+			b, err := btx.Bucket(spec.parent).CreateBucketIfNotExists([]byte(name))
+			if err != nil {
+				return err
+			}
+			if err := b.Put(key, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// unindex removes rec from every index, before it is indexed again under
+// its new block.
+func unindex(btx *bolt.Tx, rec record) error {
+	key := sortKey(rec.Tx)
+	if err := btx.Bucket(heightsBucket).Delete(key); err != nil {
+		return err
+	}
+	for _, spec := range indexes(rec) {
+		for _, name := range spec.names {
+			if b := btx.Bucket(spec.parent).Bucket([]byte(name)); b != nil {
+				if err := b.Delete(key); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+type indexSpec struct {
+	parent []byte
+	names  []string
+}
+
+// indexes returns the principal, contract and event type indexes of rec.
+func indexes(rec record) []indexSpec {
+	principals := make([]string, 0, len(rec.Principals))
+	for principal := range rec.Principals {
+		principals = append(principals, principal)
+	}
+	return []indexSpec{
+		{principalsBucket, principals},
+		{contractsBucket, Contracts(rec.Tx)},
+		{eventsBucket, rec.EventTypes},
+	}
+}
+
+// Contracts returns the contracts a transaction calls or moves tokens of.
+func Contracts(tx hiro.Tx) []string {
+	var contracts []string
+	add := func(contract string) {
+		if contract != "" && !contains(contracts, contract) {
+			contracts = append(contracts, contract)
+		}
+	}
+	add(tx.ContractCall.ContractId)
+	for _, event := range tx.Events {
+		contract, _, _ := strings.Cut(event.Asset.AssetId, "::")
+		add(contract)
+		add(event.ContractLog.ContractId)
+	}
+	return contracts
+}
+
+// eventTypes returns the types of the events listed on a transaction, and
+// those of its event counts.
+func eventTypes(tx hiro.Transaction) []string {
+	var types []string
+	for _, event := range tx.Tx.Events {
+		if event.EventType != "" && !contains(types, event.EventType) {
+			types = append(types, event.EventType)
+		}
+	}
+	for _, count := range []struct {
+		name  string
+		count int
+	}{
+		{EventSTXTransfer, tx.Events.Stx.Transfer},
+		{EventSTXMint, tx.Events.Stx.Mint},
+		{EventSTXBurn, tx.Events.Stx.Burn},
+		{EventFTTransfer, tx.Events.Ft.Transfer},
+		{EventFTMint, tx.Events.Ft.Mint},
+		{EventFTBurn, tx.Events.Ft.Burn},
+		{EventNFTTransfer, tx.Events.Nft.Transfer},
+		{EventNFTMint, tx.Events.Nft.Mint},
+		{EventNFTBurn, tx.Events.Nft.Burn},
+	} {
+		if count.count > 0 && !contains(types, count.name) {
+			types = append(types, count.name)
+		}
+	}
+	return types
+}
+
+// sortKey orders transactions by block height, then index in the block,
+// then txid.
+func sortKey(tx hiro.Tx) []byte {
+	key := make([]byte, 12, 12+len(tx.TxID))
+	binary.BigEndian.PutUint64(key, uint64(tx.BlockHeight))
+	binary.BigEndian.PutUint32(key[8:], uint32(tx.TxIndex))
+	return append(key, tx.TxID...)
+}
+
+func heightPrefix(height int) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(height))
+	return key
+}
+
+func txidOf(key []byte) string {
+	return string(key[12:])
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,389 @@
+package txstore
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashhavoc/teller/pkg/api/hiro"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	alice  = "SP1ALICE"
+	bob    = "SP1BOB"
+	token  = "SP2TOKEN.token"
+	pool   = "SP2POOL.pool"
+	hashA  = "0xaaaa"
+	hashB  = "0xbbbb"
+	logged = "smart_contract_log"
+)
+
+func open(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "db", "txs.db"), time.Second)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// mined returns a canonical transaction mined at height and index.
+func mined(txid string, height, index int) hiro.Transaction {
+	return hiro.Transaction{Tx: hiro.Tx{
+		TxID:        txid,
+		BlockHash:   hashA,
+		BlockHeight: height,
+		TxIndex:     index,
+		TxStatus:    "success",
+		Canonical:   true,
+	}}
+}
+
+// calling returns tx calling contract.
+func calling(tx hiro.Transaction, contract string) hiro.Transaction {
+	tx.Tx.ContractCall.ContractId = contract
+	return tx
+}
+
+// transferring returns tx with a fungible token transfer.
+func transferring(tx hiro.Transaction) hiro.Transaction {
+	tx.Events.Ft.Transfer = 1
+	return tx
+}
+
+func put(t *testing.T, s *Store, principal string, txs ...hiro.Transaction) Result {
+	t.Helper()
+	result, err := s.Put(principal, txs)
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	return result
+}
+
+func find(t *testing.T, s *Store, q Query) []string {
+	t.Helper()
+	txs, err := s.Find(q)
+	if err != nil {
+		t.Fatalf("Find(%+v): %v", q, err)
+	}
+	var txids []string
+	for _, tx := range txs {
+		txids = append(txids, tx.Tx.TxID)
+	}
+	return txids
+}
+
+// keys returns the index keys of txid, by index bucket.
+func keys(t *testing.T, s *Store, txid string) map[string][][]byte {
+	t.Helper()
+	found := map[string][][]byte{}
+	collect := func(name string, b *bolt.Bucket) error {
+		return b.ForEach(func(k, v []byte) error {
+			if len(k) < 12 || txidOf(k) != txid {
+				return nil
+			}
+			found[name] = append(found[name], k)
+			return nil
+		})
+	}
+	err := s.db.View(func(btx *bolt.Tx) error {
+		if err := collect("heights", btx.Bucket(heightsBucket)); err != nil {
+			return err
+		}
+		for _, parent := range [][]byte{principalsBucket, contractsBucket, eventsBucket} {
+			err := btx.Bucket(parent).ForEach(func(name, _ []byte) error {
+				return collect(string(parent)+"/"+string(name), btx.Bucket(parent).Bucket(name))
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("View: %v", err)
+	}
+	return found
+}
+
+func TestPut(t *testing.T) {
+	s := open(t)
+	tx := transferring(calling(mined("0x01", 10, 0), token))
+	tx.StxSent = "100"
+
+	tests := []struct {
+		name      string
+		principal string
+		tx        hiro.Transaction
+		want      Result
+	}{
+		{"new", alice, tx, Result{Added: 1}},
+		{"unchanged", alice, tx, Result{Known: []string{"0x01"}}},
+		{"other principal", bob, tx, Result{Added: 1}},
+		{"new status", alice, func() hiro.Transaction {
+			tx := tx
+			tx.Tx.TxStatus = "abort_by_response"
+			return tx
+		}(), Result{Updated: 1}},
+	}
+	for _, tt := range tests {
+		if got := put(t, s, tt.principal, tt.tx); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Put = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	got, found, err := s.Get("0x01")
+	if err != nil || !found || got.TxStatus != "abort_by_response" {
+		t.Errorf("Get = %+v, %v, %v, want the updated transaction", got, found, err)
+	}
+	idx := keys(t, s, "0x01")
+	for _, name := range []string{"heights", "principals/" + alice, "principals/" + bob, "contracts/" + token, "events/" + EventFTTransfer} {
+		if len(idx[name]) != 1 {
+			t.Errorf("index %s has %d keys for 0x01, want 1", name, len(idx[name]))
+		}
+	}
+	if len(idx) != 5 {
+		t.Errorf("indexed in %v, want 5 indexes", idx)
+	}
+
+	txs, err := s.Principal(alice)
+	if err != nil || len(txs) != 1 || txs[0].StxSent != "100" || txs[0].Events.Ft.Transfer != 1 {
+		t.Errorf("Principal = %+v, %v, want the amounts relative to %s", txs, err, alice)
+	}
+}
+
+func TestPutNotCanonical(t *testing.T) {
+	s := open(t)
+	tx := transferring(calling(mined("0x01", 10, 0), token))
+	put(t, s, alice, tx)
+
+	orphaned := tx
+	orphaned.Tx.Canonical = false
+	if got := put(t, s, alice, orphaned); got.Updated != 1 {
+		t.Errorf("Put = %+v, want 1 updated", got)
+	}
+	if idx := keys(t, s, "0x01"); len(idx) != 0 {
+		t.Errorf("orphaned transaction still indexed in %v", idx)
+	}
+	for _, q := range []Query{
+		{},
+		{Principal: alice},
+		{Contract: token},
+		{EventType: EventFTTransfer},
+		{FromHeight: 10, ToHeight: 10},
+	} {
+		if got := find(t, s, q); got != nil {
+			t.Errorf("Find(%+v) = %v, want none", q, got)
+		}
+	}
+	got, found, err := s.Get("0x01")
+	if err != nil || !found || got.Canonical {
+		t.Errorf("Get = %+v, %v, %v, want the orphaned transaction", got, found, err)
+	}
+
+	// Mined again, it is indexed again.
+	put(t, s, alice, tx)
+	if got := find(t, s, Query{Principal: alice}); !reflect.DeepEqual(got, []string{"0x01"}) {
+		t.Errorf("Find after re-mining = %v, want [0x01]", got)
+	}
+}
+
+func TestPutRemined(t *testing.T) {
+	s := open(t)
+	tx := calling(mined("0x01", 10, 3), token)
+	put(t, s, alice, tx)
+	before := keys(t, s, "0x01")
+
+	remined := tx
+	remined.Tx.BlockHash = hashB
+	remined.Tx.BlockHeight = 12
+	remined.Tx.TxIndex = 0
+	if got := put(t, s, alice, remined); got.Updated != 1 {
+		t.Errorf("Put = %+v, want 1 updated", got)
+	}
+
+	after := keys(t, s, "0x01")
+	if len(after) != len(before) {
+		t.Errorf("indexed in %v after re-mining, want %d indexes", after, len(before))
+	}
+	for name, ks := range after {
+		if len(ks) != 1 || !bytes.Equal(ks[0], sortKey(remined.Tx)) {
+			t.Errorf("index %s has keys %x, want only the key at height 12", name, ks)
+		}
+	}
+	if got := find(t, s, Query{ToHeight: 11}); got != nil {
+		t.Errorf("Find below the new height = %v, want none", got)
+	}
+	if got := find(t, s, Query{FromHeight: 12, Contract: token}); !reflect.DeepEqual(got, []string{"0x01"}) {
+		t.Errorf("Find at the new height = %v, want [0x01]", got)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	s := open(t)
+	tx := calling(mined("0x01", 10, 0), token)
+	put(t, s, alice, tx)
+
+	if updated, err := s.Update(mined("0x02", 10, 1).Tx); err != nil || updated {
+		t.Errorf("Update of an unknown transaction = %v, %v, want false", updated, err)
+	}
+	if got, found, _ := s.Get("0x02"); found {
+		t.Errorf("Update stored unknown transaction %+v", got)
+	}
+	if updated, err := s.Update(tx.Tx); err != nil || updated {
+		t.Errorf("Update unchanged = %v, %v, want false", updated, err)
+	}
+
+	// A newer copy with events listed moves block and adds event types.
+	newer := tx.Tx
+	newer.BlockHeight = 11
+	newer.BlockHash = hashB
+	newer.Events = []hiro.Event{{EventType: logged, ContractLog: hiro.ContractLog{ContractId: pool}}}
+	if updated, err := s.Update(newer); err != nil || !updated {
+		t.Errorf("Update moved = %v, %v, want true", updated, err)
+	}
+	for _, q := range []Query{
+		{Principal: alice, FromHeight: 11},
+		{Contract: token, ToHeight: 11},
+		{Contract: pool},
+		{EventType: logged},
+	} {
+		if got := find(t, s, q); !reflect.DeepEqual(got, []string{"0x01"}) {
+			t.Errorf("Find(%+v) = %v, want [0x01]", q, got)
+		}
+	}
+	if got := find(t, s, Query{ToHeight: 10}); got != nil {
+		t.Errorf("Find at the old height = %v, want none", got)
+	}
+
+	orphaned := newer
+	orphaned.Canonical = false
+	if updated, err := s.Update(orphaned); err != nil || !updated {
+		t.Errorf("Update orphaned = %v, %v, want true", updated, err)
+	}
+	if idx := keys(t, s, "0x01"); len(idx) != 0 {
+		t.Errorf("orphaned transaction still indexed in %v", idx)
+	}
+	// The principals it was synced for are kept.
+	orphaned.Canonical = true
+	s.Update(orphaned)
+	if got := find(t, s, Query{Principal: alice}); !reflect.DeepEqual(got, []string{"0x01"}) {
+		t.Errorf("Find by principal after Update = %v, want [0x01]", got)
+	}
+}
+
+func TestFind(t *testing.T) {
+	s := open(t)
+	put(t, s, alice,
+		calling(mined("0x01", 5, 0), token),
+		transferring(calling(mined("0x02", 10, 1), token)),
+		calling(mined("0x03", 10, 0), pool),
+		transferring(calling(mined("0x04", 20, 0), pool)),
+	)
+	put(t, s, bob,
+		transferring(calling(mined("0x02", 10, 1), token)),
+		calling(mined("0x05", 15, 0), token),
+	)
+
+	tests := []struct {
+		name string
+		q    Query
+		want []string
+	}{
+		{"all", Query{}, []string{"0x04", "0x05", "0x02", "0x03", "0x01"}},
+		{"principal", Query{Principal: alice}, []string{"0x04", "0x02", "0x03", "0x01"}},
+		{"contract", Query{Contract: token}, []string{"0x05", "0x02", "0x01"}},
+		{"event type", Query{EventType: EventFTTransfer}, []string{"0x04", "0x02"}},
+		{"principal and contract", Query{Principal: bob, Contract: token}, []string{"0x05", "0x02"}},
+		{"principal, contract and event type", Query{Principal: alice, Contract: pool, EventType: EventFTTransfer}, []string{"0x04"}},
+		{"disjoint", Query{Principal: bob, Contract: pool}, nil},
+		{"unknown principal", Query{Principal: "SP1CAROL"}, nil},
+		{"unknown event type", Query{EventType: EventNFTMint}, nil},
+		{"from height", Query{FromHeight: 10}, []string{"0x04", "0x05", "0x02", "0x03"}},
+		{"to height", Query{ToHeight: 10}, []string{"0x02", "0x03", "0x01"}},
+		{"between heights", Query{FromHeight: 6, ToHeight: 15}, []string{"0x05", "0x02", "0x03"}},
+		{"single height", Query{FromHeight: 10, ToHeight: 10}, []string{"0x02", "0x03"}},
+		{"to height beyond the last key", Query{ToHeight: 100}, []string{"0x04", "0x05", "0x02", "0x03", "0x01"}},
+		{"to height before the first key", Query{ToHeight: 4}, nil},
+		{"from height beyond the last key", Query{FromHeight: 21}, nil},
+		{"empty range", Query{FromHeight: 15, ToHeight: 10}, nil},
+		{"heights and indexes", Query{Principal: alice, Contract: token, ToHeight: 9}, []string{"0x01"}},
+		{"heights beyond the last key of an index", Query{Contract: token, FromHeight: 6, ToHeight: 100}, []string{"0x05", "0x02"}},
+	}
+	for _, tt := range tests {
+		if got := find(t, s, tt.q); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Find = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSortKey(t *testing.T) {
+	// Each transaction sorts before the next.
+	txs := []hiro.Tx{
+		mined("0x02", 1, 0).Tx,
+		mined("0x01", 1, 1).Tx,
+		mined("0x01", 1, 256).Tx,
+		mined("0x01", 2, 0).Tx,
+		mined("0x02", 2, 0).Tx,
+		mined("0x01", 256, 0).Tx,
+		mined("0x01", 1<<32, 0).Tx,
+	}
+	for i := 1; i < len(txs); i++ {
+		a, b := sortKey(txs[i-1]), sortKey(txs[i])
+		if bytes.Compare(a, b) >= 0 {
+			t.Errorf("sortKey(%d/%d/%s) = %x, not before sortKey(%d/%d/%s) = %x",
+				txs[i-1].BlockHeight, txs[i-1].TxIndex, txs[i-1].TxID, a,
+				txs[i].BlockHeight, txs[i].TxIndex, txs[i].TxID, b)
+		}
+	}
+	for _, tx := range txs {
+		key := sortKey(tx)
+		if got := txidOf(key); got != tx.TxID {
+			t.Errorf("txidOf(%x) = %s, want %s", key, got, tx.TxID)
+		}
+		if !bytes.HasPrefix(key, heightPrefix(tx.BlockHeight)) {
+			t.Errorf("sortKey(%+v) = %x, does not start with its height", tx, key)
+		}
+		if bytes.Compare(key, heightPrefix(tx.BlockHeight+1)) >= 0 {
+			t.Errorf("sortKey(%+v) = %x, not before the next height", tx, key)
+		}
+	}
+}
+
+func TestIndexes(t *testing.T) {
+	tx := transferring(calling(mined("0x01", 1, 0), pool))
+	tx.Events.Stx.Transfer = 2
+	tx.Tx.Events = []hiro.Event{
+		{EventType: "fungible_token_asset", Asset: hiro.Asset{AssetId: token + "::token"}},
+		{EventType: logged, ContractLog: hiro.ContractLog{ContractId: pool}},
+		{EventType: "fungible_token_asset", Asset: hiro.Asset{AssetId: token + "::token"}},
+	}
+	if got, want := Contracts(tx.Tx), []string{pool, token}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Contracts = %v, want %v", got, want)
+	}
+	if got, want := eventTypes(tx), []string{"fungible_token_asset", logged, EventSTXTransfer, EventFTTransfer}; !reflect.DeepEqual(got, want) {
+		t.Errorf("eventTypes = %v, want %v", got, want)
+	}
+}
+
+func TestSyncState(t *testing.T) {
+	s := open(t)
+	if got, err := s.SyncState(alice); err != nil || got != (SyncState{}) {
+		t.Errorf("SyncState of an unsynced principal = %+v, %v, want zero", got, err)
+	}
+	state := SyncState{Complete: true, Synced: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	if err := s.SetSyncState(alice, state); err != nil {
+		t.Fatalf("SetSyncState: %v", err)
+	}
+	if got, err := s.SyncState(alice); err != nil || !got.Synced.Equal(state.Synced) || !got.Complete {
+		t.Errorf("SyncState = %+v, %v, want %+v", got, err, state)
+	}
+	principals, err := s.Principals()
+	if err != nil || len(principals) != 1 || !principals[alice].Complete {
+		t.Errorf("Principals = %v, %v, want %s", principals, err, alice)
+	}
+}
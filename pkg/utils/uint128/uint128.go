@@ -15,7 +15,9 @@ package uint128
 import (
 	"encoding/binary"
 	"encoding/hex"
+	"math/big"
 	"math/bits"
+	"strconv"
 
 	"github.com/pkg/errors"
 )
@@ -104,7 +106,7 @@ func (u Uint128) Add(v Uint128) Uint128 {
 	if carry != 0 {
 		panic("overflow")
 	}
-	return Uint128{lo, hi}
+	return Uint128{Hi: hi, Lo: lo}
 }
 
 // From64 converts v to a Uint128 value.
@@ -114,7 +116,7 @@ func From64(v uint64) Uint128 {
 
 // New returns the Uint128 value (lo,hi).
 func New(lo, hi uint64) Uint128 {
-	return Uint128{lo, hi}
+	return Uint128{Hi: hi, Lo: lo}
 }
 
 // Equals64 returns true if u == v.
@@ -158,7 +160,7 @@ func (u Uint128) Sub(v Uint128) Uint128 {
 	if borrow != 0 {
 		panic("underflow")
 	}
-	return Uint128{lo, hi}
+	return Uint128{Hi: hi, Lo: lo}
 }
 
 // And returns a new Uint128 that is the bitwise AND of two Uint128 values.
@@ -204,6 +206,22 @@ func FromString(s string) (Uint128, error) {
 	return FromBytes(bytes), nil
 }
 
+// FromDecimal parses a base 10 string, such as a raw token balance, as a
+// 128-bit unsigned integer.
+func FromDecimal(s string) (Uint128, error) {
+	if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return FromInts(0, n), nil
+	}
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return Uint128{}, errors.Errorf("could not parse %s as a decimal integer", s)
+	}
+	if n.Sign() < 0 || n.BitLen() > 128 {
+		return Uint128{}, errors.Errorf("input string %s out of range for uint128", s)
+	}
+	return FromBytes(n.FillBytes(make([]byte, 16))), nil
+}
+
 // FromInts takes in two unsigned 64-bit integers and constructs a Uint128.
 func FromInts(hi uint64, lo uint64) Uint128 {
 	return Uint128{hi, lo}
@@ -0,0 +1,94 @@
+package uint128
+
+import "testing"
+
+const max64 = ^uint64(0)
+
+func TestAdd(t *testing.T) {
+	for _, tt := range []struct {
+		u, v, want Uint128
+	}{
+		{FromInts(0, 1), FromInts(0, 2), FromInts(0, 3)},
+		// The low word carries into the high word.
+		{FromInts(0, max64), FromInts(0, 1), FromInts(1, 0)},
+		{FromInts(5, max64), FromInts(2, max64), FromInts(8, max64-1)},
+		{FromInts(max64-1, max64), FromInts(0, 1), FromInts(max64, 0)},
+		{FromInts(max64, 0), FromInts(0, max64), FromInts(max64, max64)},
+	} {
+		if got := tt.u.Add(tt.v); got != tt.want {
+			t.Errorf("%s + %s = %s, want %s", tt.u, tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestSub(t *testing.T) {
+	for _, tt := range []struct {
+		u, v, want Uint128
+	}{
+		{FromInts(0, 3), FromInts(0, 2), FromInts(0, 1)},
+		// The low word borrows from the high word.
+		{FromInts(1, 0), FromInts(0, 1), FromInts(0, max64)},
+		{FromInts(8, max64-1), FromInts(2, max64), FromInts(5, max64)},
+		{FromInts(max64, 0), FromInts(0, max64), FromInts(max64-1, 1)},
+		{FromInts(max64, max64), FromInts(max64, max64), Zero},
+	} {
+		if got := tt.u.Sub(tt.v); got != tt.want {
+			t.Errorf("%s - %s = %s, want %s", tt.u, tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	for name, f := range map[string]func(){
+		"add":           func() { FromInts(max64, max64).Add(FromInts(0, 1)) },
+		"sub":           func() { FromInts(0, 0).Sub(FromInts(0, 1)) },
+		"sub high word": func() { FromInts(1, max64).Sub(FromInts(2, 0)) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("no panic")
+				}
+			}()
+			f()
+		})
+	}
+}
+
+// New and From64 take the low word first, unlike FromInts.
+func TestNew(t *testing.T) {
+	if got := New(1, 2); got.Lo != 1 || got.Hi != 2 {
+		t.Errorf("New(1, 2) = {Hi: %d, Lo: %d}, want {Hi: 2, Lo: 1}", got.Hi, got.Lo)
+	}
+	if got := From64(7); got != FromInts(0, 7) {
+		t.Errorf("From64(7) = %s", got)
+	}
+	if got := New(0, 1).String(); got != "18446744073709551616" {
+		t.Errorf("2^64 = %s", got)
+	}
+}
+
+func TestFromDecimal(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want Uint128
+	}{
+		{"0", Zero},
+		{"18446744073709551615", FromInts(0, max64)},
+		{"18446744073709551616", FromInts(1, 0)},
+		{"340282366920938463463374607431768211455", FromInts(max64, max64)},
+	} {
+		got, err := FromDecimal(tt.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want || got.String() != tt.s {
+			t.Errorf("FromDecimal(%s) = %s", tt.s, got)
+		}
+	}
+	for _, s := range []string{"", "-1", "1.5", "340282366920938463463374607431768211456"} {
+		if _, err := FromDecimal(s); err == nil {
+			t.Errorf("FromDecimal(%q) succeeded", s)
+		}
+	}
+}